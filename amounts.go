@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Kaspa amounts are in sompi, 10^8 sompi make one KAS
+const (
+	kaspaDecimals     = 8
+	kaspaDisplayDenom = "KAS"
+)
+
+// Separators of displayed amounts, set from the thousands_separator and
+// decimal_separator config settings at startup. An empty thousands separator
+// shows the digits ungrouped.
+var (
+	thousandsSeparator = ","
+	decimalSeparator   = "."
+)
+
+// validateSeparators checks the separators of displayed amounts, which must
+// tell apart from each other and from the digits
+func validateSeparators(thousands, decimal string) error {
+	if thousands == decimal {
+		return fmt.Errorf("thousands_separator and decimal_separator must differ, both are %q", decimal)
+	}
+	for _, separator := range []string{thousands, decimal} {
+		if strings.ContainsAny(separator, "0123456789-") {
+			return fmt.Errorf("invalid separator %q: must not contain digits or a minus sign", separator)
+		}
+	}
+	return nil
+}
+
+// Balance threshold directions, a balance alerts when it is below (the
+// default) or above its threshold
+const (
+	directionBelow = "below"
+	directionAbove = "above"
+)
+
+// validateDirection checks a balance threshold direction, empty means below
+func validateDirection(direction string) error {
+	switch direction {
+	case "", directionBelow, directionAbove:
+		return nil
+	}
+	return fmt.Errorf("invalid direction %q: must be %s or %s", direction, directionBelow, directionAbove)
+}
+
+// breachesThreshold reports whether a balance is on the alerting side of the
+// threshold for the given direction
+func breachesThreshold(amount, threshold *big.Int, direction string) bool {
+	if direction == directionAbove {
+		return amount.Cmp(threshold) > 0
+	}
+	return amount.Cmp(threshold) < 0
+}
+
+// BalanceThreshold is the minimum balance of an address in base units, with
+// optional settings to show amounts in a human-readable denom
+type BalanceThreshold struct {
+	Denom        string `mapstructure:"denom"`
+	Amount       string `mapstructure:"amount"`
+	Decimals     int    `mapstructure:"decimals"`      // Optional decimals of the display denom, e.g. 6 for uatom
+	DisplayDenom string `mapstructure:"display_denom"` // Optional human-readable denom, e.g. ATOM
+
+	DisplayAmount string `mapstructure:"display_amount"` // Optional amount in the display denom instead of amount, e.g. "1.5" ATOM
+}
+
+// parseDisplayAmount converts an amount in the display denom, e.g. "1.5" with
+// 6 decimals, to base units, "1500000". The decimal point is shifted on the
+// digits so no precision is lost to floating point, and an amount with more
+// fractional digits than decimals is rejected rather than truncated.
+func parseDisplayAmount(amount string, decimals int) (string, error) {
+	if decimals <= 0 {
+		return "", fmt.Errorf("display_amount %q requires decimals to be set", amount)
+	}
+
+	integer, fraction, _ := strings.Cut(amount, ".")
+	if integer == "" || !isDigits(integer) || !isDigits(fraction) || strings.HasSuffix(amount, ".") {
+		return "", fmt.Errorf("invalid display_amount %q: must be a non-negative decimal number", amount)
+	}
+	if len(fraction) > decimals {
+		return "", fmt.Errorf("display_amount %q has more fractional digits than the %d decimals", amount, decimals)
+	}
+
+	base, _ := new(big.Int).SetString(integer+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	return base.String(), nil
+}
+
+// isDigits reports whether s only contains ASCII digits
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// format renders a base-unit amount of the threshold's denom for messages
+func (t BalanceThreshold) format(raw string) string {
+	return formatBalance(raw, t.Denom, t.Decimals, t.DisplayDenom)
+}
+
+// formatBalance renders a base-unit amount as "1.25 ATOM (1,250,000 uatom)"
+// when display settings are given, and as "1,250,000 uatom" otherwise
+func formatBalance(raw, denom string, decimals int, displayDenom string) string {
+	if decimals == 0 && displayDenom == "" {
+		return fmt.Sprintf("%s %s", formatAmount(raw, 0), denom)
+	}
+
+	if displayDenom == "" {
+		displayDenom = denom
+	}
+	return fmt.Sprintf("%s %s (%s %s)", formatAmount(raw, decimals), displayDenom, formatAmount(raw, 0), denom)
+}
+
+// formatAmount divides a base-unit integer amount by 10^decimals and formats
+// it with the configured separators, e.g. "1234567890" with 6 decimals
+// becomes "1,234.56789". The digits are shifted as a string, so amounts past
+// float64 precision stay exact. Amounts that aren't integers are returned
+// unchanged.
+func formatAmount(raw string, decimals int) string {
+	amount, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return raw
+	}
+
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+		amount.Neg(amount)
+	}
+
+	digits := amount.String()
+	if decimals > 0 {
+		// Pad so there's at least one integer digit
+		if len(digits) <= decimals {
+			digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+		}
+	}
+
+	integer := digits[:len(digits)-decimals]
+	fraction := strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	result := sign + groupThousands(integer)
+	if fraction != "" {
+		result += decimalSeparator + fraction
+	}
+	return result
+}
+
+// groupThousands inserts the thousands separator between every group of three
+// digits
+func groupThousands(digits string) string {
+	if len(digits) <= 3 || thousandsSeparator == "" {
+		return digits
+	}
+
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(thousandsSeparator)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseDisplayAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		expected string
+		wantErr  bool
+	}{
+		{amount: "1.5", decimals: 6, expected: "1500000"},
+		{amount: "1", decimals: 6, expected: "1000000"},
+		{amount: "0.000001", decimals: 6, expected: "1"},
+		{amount: "0", decimals: 6, expected: "0"},
+		{amount: "007.25", decimals: 2, expected: "725"},
+		{amount: "123456789.123456789123456789", decimals: 18, expected: "123456789123456789123456789"},
+		{amount: "1.1234567891234567891", decimals: 18, wantErr: true},
+		{amount: "1000000000000.000000000000000001", decimals: 18, expected: "1000000000000000000000000000001"},
+		{amount: "0.0000001", decimals: 6, wantErr: true},
+		{amount: "1.50", decimals: 1, wantErr: true},
+		{amount: "1.5", decimals: 0, wantErr: true},
+		{amount: "", decimals: 6, wantErr: true},
+		{amount: ".5", decimals: 6, wantErr: true},
+		{amount: "1.", decimals: 6, wantErr: true},
+		{amount: "-1", decimals: 6, wantErr: true},
+		{amount: "1e6", decimals: 6, wantErr: true},
+		{amount: "1,000", decimals: 6, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDisplayAmount(tt.amount, tt.decimals)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDisplayAmount(%q, %d): expected an error, got %s", tt.amount, tt.decimals, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDisplayAmount(%q, %d): %v", tt.amount, tt.decimals, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseDisplayAmount(%q, %d): expected %s, got %s", tt.amount, tt.decimals, tt.expected, got)
+		}
+	}
+}
+
+func TestFormatBalance(t *testing.T) {
+	tests := []struct {
+		name         string
+		thousands    string
+		decimal      string
+		raw          string
+		decimals     int
+		displayDenom string
+		expected     string
+	}{
+		{name: "base units", thousands: ",", decimal: ".", raw: "1250000", expected: "1,250,000 uatom"},
+		{name: "display denom", thousands: ",", decimal: ".", raw: "1250000", decimals: 6, displayDenom: "ATOM", expected: "1.25 ATOM (1,250,000 uatom)"},
+		{name: "beyond float64", thousands: ",", decimal: ".", raw: "1234567890123456789012345", decimals: 18, displayDenom: "ATOM", expected: "1,234,567.890123456789012345 ATOM (1,234,567,890,123,456,789,012,345 uatom)"},
+		{name: "below one", thousands: ",", decimal: ".", raw: "5", decimals: 6, displayDenom: "ATOM", expected: "0.000005 ATOM (5 uatom)"},
+		{name: "negative", thousands: ",", decimal: ".", raw: "-1234567", expected: "-1,234,567 uatom"},
+		{name: "european", thousands: ".", decimal: ",", raw: "1234567890", decimals: 6, displayDenom: "ATOM", expected: "1.234,56789 ATOM (1.234.567.890 uatom)"},
+		{name: "ungrouped", thousands: "", decimal: ".", raw: "1234567890", decimals: 6, displayDenom: "ATOM", expected: "1234.56789 ATOM (1234567890 uatom)"},
+		{name: "not an integer", thousands: ",", decimal: ".", raw: "12.5", expected: "12.5 uatom"},
+	}
+
+	t.Cleanup(func() { thousandsSeparator, decimalSeparator = ",", "." })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thousandsSeparator, decimalSeparator = tt.thousands, tt.decimal
+			if got := formatBalance(tt.raw, "uatom", tt.decimals, tt.displayDenom); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateSeparators(t *testing.T) {
+	tests := []struct {
+		thousands string
+		decimal   string
+		wantErr   bool
+	}{
+		{thousands: ",", decimal: "."},
+		{thousands: ".", decimal: ","},
+		{thousands: " ", decimal: ","},
+		{thousands: "", decimal: "."},
+		{thousands: ".", decimal: ".", wantErr: true},
+		{thousands: "0", decimal: ".", wantErr: true},
+		{thousands: ",", decimal: "-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateSeparators(tt.thousands, tt.decimal); (err != nil) != tt.wantErr {
+			t.Errorf("validateSeparators(%q, %q): expected error %v, got %v", tt.thousands, tt.decimal, tt.wantErr, err)
+		}
+	}
+}
+
+// TestBreachesThreshold checks both directions at and around the threshold
+func TestBreachesThreshold(t *testing.T) {
+	threshold := big.NewInt(100)
+	tests := []struct {
+		direction string
+		amount    int64
+		expected  bool
+	}{
+		{direction: directionBelow, amount: 99, expected: true},
+		{direction: directionBelow, amount: 100},
+		{direction: directionAbove, amount: 100},
+		{direction: directionAbove, amount: 101, expected: true},
+		{direction: directionAbove, amount: 5},
+	}
+
+	for _, tt := range tests {
+		if got := breachesThreshold(big.NewInt(tt.amount), threshold, tt.direction); got != tt.expected {
+			t.Errorf("%d %s 100: expected %v, got %v", tt.amount, tt.direction, tt.expected, got)
+		}
+	}
+}
+
+// TestValidateDirection checks the accepted directions
+func TestValidateDirection(t *testing.T) {
+	for _, direction := range []string{"", directionBelow, directionAbove} {
+		if err := validateDirection(direction); err != nil {
+			t.Errorf("expected direction %q to be accepted, got %v", direction, err)
+		}
+	}
+	if err := validateDirection("over"); err == nil {
+		t.Error("expected an unknown direction to be rejected")
+	}
+}
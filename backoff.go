@@ -0,0 +1,70 @@
+package main
+
+import "log/slog"
+
+// maxCheckBackoff is the largest multiple of its group's interval that a
+// failing item's checks back off to, set from the check_backoff config
+// setting at startup. 0 or 1 disables backing off.
+var maxCheckBackoff = 8
+
+// itemBackoff spaces out the checks of an item whose checks keep failing, so
+// a dead endpoint isn't hammered every interval. Each backoff is only used by
+// one goroutine, the item's group goroutine, whose check cycles never
+// overlap, or its recovery monitor, which keeps its own.
+type itemBackoff struct {
+	failures int // Consecutive failed checks
+	skip     int // Checks left to skip before the next attempt
+}
+
+// run runs the check through timedCheck unless the item is backing off, in
+// which case it skips the check and reports that it didn't run
+func (b *itemBackoff) run(itemType, group, name string, check func() error) (bool, error) {
+	if b.skipping() {
+		return false, nil
+	}
+	err := timedCheck(itemType, check)
+	b.record(err, itemType, group, name)
+	return true, err
+}
+
+// skipping reports whether the current check should be skipped, counting it
+// as skipped
+func (b *itemBackoff) skipping() bool {
+	if b.skip == 0 {
+		return false
+	}
+	b.skip--
+	return true
+}
+
+// record updates the backoff with the result of a check
+func (b *itemBackoff) record(err error, itemType, group, name string) {
+	if err == nil {
+		if b.failures > 1 {
+			slog.Info("check succeeded, ending backoff", "type", itemType, "group", group, "item", name, "failures", b.failures)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.skip = backoffSkips(b.failures)
+	if b.skip > 0 {
+		slog.Info("check keeps failing, backing off", "type", itemType, "group", group, "item", name,
+			"failures", b.failures, "skipped_checks", b.skip)
+	}
+}
+
+// backoffSkips returns how many checks to skip after consecutive failures:
+// the first failure is retried on the next interval, then the gap doubles
+// with every failure up to maxCheckBackoff intervals
+func backoffSkips(failures int) int {
+	if maxCheckBackoff <= 1 {
+		return 0
+	}
+	multiple := maxCheckBackoff
+	if failures <= 30 && 1<<(failures-1) < multiple {
+		multiple = 1 << (failures - 1)
+	}
+	return multiple - 1
+}
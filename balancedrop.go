@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// dropPercent returns by how many percent current is below previous, or 0
+// when the balance didn't drop
+func dropPercent(previous, current *big.Int) float64 {
+	if previous.Sign() <= 0 || current.Cmp(previous) >= 0 {
+		return 0
+	}
+	diff := new(big.Int).Sub(previous, current)
+	percent, _ := new(big.Rat).SetFrac(new(big.Int).Mul(diff, big.NewInt(100)), previous).Float64()
+	return percent
+}
+
+// dropCondition alerts when the balance of an address with max_drop_percent
+// fell by more than that percentage since the previous regular check. A drop
+// is a one-off event, so there is no recovery, only the usual cooldown
+// between alerts.
+func (a *AddressItem) dropCondition() condition {
+	return condition{
+		Checker: checkFunc(func(context.Context) (bool, string, error) {
+			a.recoveryMonitorMu.Lock()
+			previousAmount, currentAmount := a.previousBalance, a.balance
+			a.previousBalance = currentAmount
+			a.recoveryMonitorMu.Unlock()
+			if previousAmount == nil || currentAmount == nil {
+				return true, "", nil
+			}
+
+			drop := dropPercent(previousAmount, currentAmount)
+			detail := fmt.Sprintf("%s, dropped by %.2f%% from %s", a.Threshold.format(currentAmount.String()), drop, a.Threshold.format(previousAmount.String()))
+			return drop <= a.MaxDropPercent, detail, nil
+		}),
+		state: &a.drop, keyPart: "drop", threshold: fmt.Sprintf("max drop %g%%", a.MaxDropPercent), event: true,
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDropPercent checks drops, rises and a zero previous balance
+func TestDropPercent(t *testing.T) {
+	tests := []struct {
+		previous int64
+		current  int64
+		expected float64
+	}{
+		{previous: 1000, current: 750, expected: 25},
+		{previous: 1000, current: 0, expected: 100},
+		{previous: 1000, current: 1500},
+		{previous: 1000, current: 1000},
+		{previous: 0, current: 0},
+	}
+
+	for _, tt := range tests {
+		if got := dropPercent(big.NewInt(tt.previous), big.NewInt(tt.current)); got != tt.expected {
+			t.Errorf("%d to %d: expected %g%%, got %g%%", tt.previous, tt.current, tt.expected, got)
+		}
+	}
+}
+
+// TestDropCondition checks that a drop over max_drop_percent between two
+// checks breaches, and that the next check compares with the new balance
+func TestDropCondition(t *testing.T) {
+	item := &AddressItem{Name: "wallet", MaxDropPercent: 20, itemState: newItemState(), drop: newItemState()}
+	item.Threshold.Denom = "adym"
+	drop := item.dropCondition()
+
+	steps := []struct {
+		balance int64
+		healthy bool
+	}{
+		{balance: 1000, healthy: true}, // Nothing to compare with yet
+		{balance: 900, healthy: true},  // 10% drop
+		{balance: 600, healthy: false}, // 33% drop
+		{balance: 550, healthy: true},  // 8% drop from the new balance
+	}
+
+	for i, step := range steps {
+		item.balance = big.NewInt(step.balance)
+		healthy, detail, err := drop.Check(context.Background())
+		if err != nil {
+			t.Fatalf("check %d: %v", i+1, err)
+		}
+		if healthy != step.healthy {
+			t.Errorf("check %d at %d: expected healthy %v, got %v (%s)", i+1, step.balance, step.healthy, healthy, detail)
+		}
+	}
+}
+
+// TestDropBaselineRegularChecks checks that the recovery monitor of an
+// address below its threshold doesn't move the max_drop_percent baseline, so
+// a drop is judged between regular checks however often it's polled
+func TestDropBaselineRegularChecks(t *testing.T) {
+	noRetries(t)
+	interval := recoveryInterval
+	recoveryInterval = 10 * time.Millisecond
+	t.Cleanup(func() { recoveryInterval = interval })
+
+	var balance, hits atomic.Int64
+	balance.Store(1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprintf(w, `{"balances":[{"denom":"adym","amount":"%d"}],"pagination":{}}`, balance.Load())
+	}))
+	defer server.Close()
+
+	group := &AddressConfig{Name: "group", RESTEndpoint: server.URL, Addresses: []AddressItem{{
+		Name: "wallet", Address: "dym1abc", MaxDropPercent: 20, Direction: directionBelow,
+		Threshold: BalanceThreshold{Denom: "adym", Amount: "5000"}, itemState: newItemState(), drop: newItemState(),
+	}}}
+	item := &group.Addresses[0]
+	t.Cleanup(func() {
+		item.recoveryMonitorMu.Lock()
+		stopRecoveryMonitor(&item.isUnhealthy, &item.recoveryMonitorStop)
+		item.recoveryMonitorMu.Unlock()
+		waitForRecoveryMonitors(t, 0)
+	})
+
+	if _, err := checkGroupItem(group, 0, &Notifier{}, 3600); err != nil {
+		t.Fatalf("checkGroupItem: %v", err)
+	}
+
+	// The recovery monitor polls the lower balances in between
+	for _, amount := range []int64{900, 800} {
+		balance.Store(amount)
+		for seen := hits.Load(); hits.Load() < seen+2; {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	item.recoveryMonitorMu.Lock()
+	baseline := item.balance.Int64()
+	item.recoveryMonitorMu.Unlock()
+	if baseline != 1000 {
+		t.Fatalf("expected recovery checks to leave the baseline at 1000, got %d", baseline)
+	}
+
+	balance.Store(700)
+	if _, err := checkGroupItem(group, 0, &Notifier{}, 3600); err != nil {
+		t.Fatalf("checkGroupItem: %v", err)
+	}
+	item.drop.recoveryMonitorMu.Lock()
+	dropped := item.drop.isUnhealthy
+	item.drop.recoveryMonitorMu.Unlock()
+	if !dropped {
+		t.Error("expected the 30% drop since the last regular check to breach max_drop_percent")
+	}
+}
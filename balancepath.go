@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// defaultBalancePath is the bank module query of an address's balances,
+// relative to the group's rest_endpoint
+const defaultBalancePath = "/cosmos/bank/v1beta1/balances/" + explorerAddressPlaceholder
+
+// validateBalancePath checks that a balance_path is a path on the REST endpoint
+func validateBalancePath(balancePath string) error {
+	if balancePath != "" && !strings.HasPrefix(balancePath, "/") {
+		return fmt.Errorf("invalid balance_path %q: must start with /", balancePath)
+	}
+	return nil
+}
+
+// balanceURL returns the URL of an address's balance query, the item's
+// balance_path if it has one or the bank balances otherwise
+func balanceURL(restEndpoint, balancePath, address string) (string, error) {
+	if balancePath == "" {
+		balancePath = defaultBalancePath
+	}
+	return joinEndpoint(restEndpoint, strings.ReplaceAll(balancePath, explorerAddressPlaceholder, address))
+}
+
+// UnmarshalJSON reads the coins of the bank balances response and of the
+// other queries a balance_path may point at: a single balance, e.g. of
+// /cosmos/bank/v1beta1/balances/{address}/by_denom, the delegations of
+// /cosmos/staking/v1beta1/delegations/{address}, summed per denomination, and
+// the total rewards of /cosmos/distribution/v1beta1/delegators/{address}/rewards,
+// whose decimal amounts are truncated to whole base units.
+func (r *BalanceResponse) UnmarshalJSON(data []byte) error {
+	var resp struct {
+		Balances    []Balance `json:"balances"`
+		Balance     *Balance  `json:"balance"`
+		Delegations []struct {
+			Balance Balance `json:"balance"`
+		} `json:"delegation_responses"`
+		Total []Balance `json:"total"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+
+	coins := resp.Balances
+	if resp.Balance != nil {
+		coins = append(coins, *resp.Balance)
+	}
+	for _, delegation := range resp.Delegations {
+		coins = append(coins, delegation.Balance)
+	}
+	for _, reward := range resp.Total {
+		reward.Amount, _, _ = strings.Cut(reward.Amount, ".")
+		coins = append(coins, reward)
+	}
+
+	var err error
+	r.Balances, err = sumCoins(coins)
+	return err
+}
+
+// sumCoins sums coins per denomination, keeping the order they were listed in
+func sumCoins(coins []Balance) ([]Balance, error) {
+	var sums []Balance
+	totals := make(map[string]*big.Int)
+	for _, coin := range coins {
+		amount, ok := new(big.Int).SetString(coin.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q of %s", coin.Amount, coin.Denom)
+		}
+		if total, seen := totals[coin.Denom]; seen {
+			total.Add(total, amount)
+			continue
+		}
+		totals[coin.Denom] = amount
+		sums = append(sums, Balance{Denom: coin.Denom})
+	}
+	for i := range sums {
+		sums[i].Amount = totals[sums[i].Denom].String()
+	}
+	return sums, nil
+}
+
+// maxBalancePages bounds the pages of a paginated balance query, in case an
+// endpoint keeps returning a next_key
+const maxBalancePages = 100
+
+// balancePage is the pagination of a cosmos list query, whose next_key is
+// empty on the last page
+type balancePage struct {
+	Pagination *struct {
+		NextKey string `json:"next_key"`
+	} `json:"pagination"`
+}
+
+// pageURL returns the query URL for the page after the one that returned
+// nextKey
+func pageURL(queryURL, nextKey string) (string, error) {
+	u, err := url.Parse(queryURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid balance URL %q: %w", queryURL, err)
+	}
+	query := u.Query()
+	query.Set("pagination.key", nextKey)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestGetBalancePath checks that balance_path replaces the bank balances query
+// and that the coins of the responses it may point at are read
+func TestGetBalancePath(t *testing.T) {
+	noRetries(t)
+
+	tests := []struct {
+		name        string
+		balancePath string
+		body        string
+		wantURL     string
+		want        []Balance
+	}{
+		{
+			name:    "bank balances",
+			body:    `{"balances":[{"denom":"adym","amount":"1500"}]}`,
+			wantURL: "http://rest.test/cosmos/bank/v1beta1/balances/dym1test",
+			want:    []Balance{{Denom: "adym", Amount: "1500"}},
+		},
+		{
+			name:        "single balance",
+			balancePath: "/cosmos/bank/v1beta1/balances/{address}/by_denom?denom=adym",
+			body:        `{"balance":{"denom":"adym","amount":"42"}}`,
+			wantURL:     "http://rest.test/cosmos/bank/v1beta1/balances/dym1test/by_denom?denom=adym",
+			want:        []Balance{{Denom: "adym", Amount: "42"}},
+		},
+		{
+			name:        "delegations",
+			balancePath: "/cosmos/staking/v1beta1/delegations/{address}",
+			body:        `{"delegation_responses":[{"balance":{"denom":"adym","amount":"100"}},{"balance":{"denom":"adym","amount":"250"}}]}`,
+			wantURL:     "http://rest.test/cosmos/staking/v1beta1/delegations/dym1test",
+			want:        []Balance{{Denom: "adym", Amount: "350"}},
+		},
+		{
+			name:        "rewards",
+			balancePath: "/cosmos/distribution/v1beta1/delegators/{address}/rewards",
+			body:        `{"rewards":[],"total":[{"denom":"adym","amount":"1234.567800000000000000"}]}`,
+			wantURL:     "http://rest.test/cosmos/distribution/v1beta1/delegators/dym1test/rewards",
+			want:        []Balance{{Denom: "adym", Amount: "1234"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotURL string
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				gotURL = req.URL.String()
+				return respond(http.StatusOK, tt.body)(req)
+			})
+
+			resp, err := getBalance(context.Background(), "http://rest.test", "dym1test", tt.balancePath, RequestOptions{httpClient: client})
+			if err != nil {
+				t.Fatalf("getBalance: %v", err)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("expected a request to %s, got %s", tt.wantURL, gotURL)
+			}
+			if !reflect.DeepEqual(resp.Balances, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, resp.Balances)
+			}
+		})
+	}
+}
+
+// TestGetBalancePaginated checks that the pages of a paginated balances
+// response are followed, so a denomination on a later page is found
+func TestGetBalancePaginated(t *testing.T) {
+	noRetries(t)
+
+	var requests []string
+	client := fakeClient(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.String())
+		switch req.URL.Query().Get("pagination.key") {
+		case "":
+			return respond(http.StatusOK, `{"balances":[{"denom":"ibc/27394FB0","amount":"7"}],"pagination":{"next_key":"FGliYy9B+w==","total":"0"}}`)(req)
+		case "FGliYy9B+w==":
+			return respond(http.StatusOK, `{"balances":[{"denom":"adym","amount":"1500"}],"pagination":{"next_key":null,"total":"0"}}`)(req)
+		default:
+			return respond(http.StatusBadRequest, `{"code":3,"message":"invalid key"}`)(req)
+		}
+	})
+
+	resp, err := getBalance(context.Background(), "http://rest.test", "dym1test", "", RequestOptions{httpClient: client})
+	if err != nil {
+		t.Fatalf("getBalance: %v", err)
+	}
+	want := []Balance{{Denom: "ibc/27394FB0", Amount: "7"}, {Denom: "adym", Amount: "1500"}}
+	if !reflect.DeepEqual(resp.Balances, want) {
+		t.Errorf("expected %+v, got %+v", want, resp.Balances)
+	}
+	if len(requests) != 2 || requests[1] != "http://rest.test/cosmos/bank/v1beta1/balances/dym1test?pagination.key=FGliYy9B%2Bw%3D%3D" {
+		t.Errorf("expected the second page to be requested with the next key, got %v", requests)
+	}
+
+	// An endpoint that keeps returning the same key must not be followed forever
+	loop := fakeClient(respond(http.StatusOK, `{"balances":[],"pagination":{"next_key":"AA=="}}`))
+	if _, err := getBalance(context.Background(), "http://rest.test", "dym1test", "", RequestOptions{httpClient: loop}); err == nil {
+		t.Error("expected an error for a repeating next_key")
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultHeightPath matches the cosmos /cosmos/base/tendermint/v1beta1/blocks/latest response
+const defaultHeightPath = "block.header.height"
+
+// defaultMaxStallChecks is how many checks without progress raise an alert by default
+const defaultMaxStallChecks = 3
+
+type BlockHeightItem struct {
+	Name             string  `mapstructure:"name"`
+	Endpoint         string  `mapstructure:"endpoint"`          // URL returning the latest block as JSON
+	HeightPath       string  `mapstructure:"height_path"`       // Dotted JSON path of the height (default: block.header.height)
+	MaxStallChecks   int     `mapstructure:"max_stall_checks"`  // Optional per-endpoint stall limit
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-endpoint cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-endpoint recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	lastHeight  int64     // Last seen height
+	lastAdvance time.Time // When the height last increased
+	stallChecks int       // Consecutive checks without progress
+	itemState             // Internal tracking, not from config
+}
+
+type BlockHeightConfig struct {
+	Name           string            `mapstructure:"name"`
+	CheckInterval  Seconds           `mapstructure:"check_interval"`   // Optional per-group check interval
+	MaxStallChecks int               `mapstructure:"max_stall_checks"` // Checks without progress before alerting (default: 3)
+	Endpoints      []BlockHeightItem `mapstructure:"endpoints"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+// validateBlockHeights validates the block height groups and prepares their items
+func validateBlockHeights(config *Config) error {
+	for i, heightGroup := range config.BlockHeight {
+		if heightGroup.Name == "" {
+			config.BlockHeight[i].Name = fmt.Sprintf("Block Height Group %d", i+1) // Set default name if not provided
+			heightGroup.Name = config.BlockHeight[i].Name
+		}
+		if err := heightGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("block height group '%s': %w", config.BlockHeight[i].Name, err)
+		}
+		if heightGroup.MaxStallChecks < 0 {
+			return fmt.Errorf("max_stall_checks must not be negative for block height group '%s'", heightGroup.Name)
+		}
+		if heightGroup.MaxStallChecks == 0 {
+			config.BlockHeight[i].MaxStallChecks = defaultMaxStallChecks
+		}
+
+		for j, endpoint := range heightGroup.Endpoints {
+			item := &config.BlockHeight[i].Endpoints[j]
+			if endpoint.Endpoint == "" {
+				return fmt.Errorf("endpoint is required for block height item #%d in group '%s'", j+1, heightGroup.Name)
+			}
+			if err := validateEndpoint(endpoint.Endpoint); err != nil {
+				return fmt.Errorf("block height item #%d in group '%s': %w", j+1, heightGroup.Name, err)
+			}
+			if endpoint.MaxStallChecks < 0 {
+				return fmt.Errorf("max_stall_checks must not be negative for block height endpoint '%s' in group '%s'", endpoint.Endpoint, heightGroup.Name)
+			}
+			if endpoint.HeightPath == "" {
+				item.HeightPath = defaultHeightPath
+			}
+			if endpoint.Name == "" {
+				item.Name = fmt.Sprintf("Node %d", j+1) // Set default name if not provided
+			}
+			if err := endpoint.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("block height endpoint '%s' in group '%s': %w", endpoint.Endpoint, heightGroup.Name, err)
+			}
+			if endpoint.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for block height endpoint '%s' in group '%s'", endpoint.Endpoint, heightGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// maxStallChecks returns the item's stall limit, falling back to the group's
+func (b *BlockHeightItem) maxStallChecks(heightConfig *BlockHeightConfig) int {
+	if b.MaxStallChecks > 0 {
+		return b.MaxStallChecks
+	}
+	return heightConfig.MaxStallChecks
+}
+
+// getBlockHeight fetches the latest block height from the endpoint
+func getBlockHeight(ctx context.Context, endpoint, heightPath string, opts RequestOptions) (int64, error) {
+	resp, err := httpGet(ctx, endpoint, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("API returned %w", unexpectedStatus(resp))
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(resp.Body, &data); err != nil {
+		return 0, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	value, err := lookupJSONPath(data, heightPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// Cosmos returns heights as strings, other APIs as numbers
+	switch height := value.(type) {
+	case string:
+		parsed, err := strconv.ParseInt(height, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid height %q: %w", height, err)
+		}
+		return parsed, nil
+	case float64:
+		return int64(height), nil
+	default:
+		return 0, fmt.Errorf("invalid height %v at %s", value, heightPath)
+	}
+}
+
+// blockHeightCheck checks a node's block height against its group's stall limit
+type blockHeightCheck struct {
+	group *BlockHeightConfig
+	item  *BlockHeightItem
+}
+
+// Check fetches the block height and reports the node unhealthy once the
+// height hasn't advanced for max_stall_checks checks
+func (c blockHeightCheck) Check(ctx context.Context) (bool, string, error) {
+	height, err := getBlockHeight(ctx, c.item.Endpoint, c.item.HeightPath, c.group.RequestOptions)
+	if err != nil {
+		return false, "", fmt.Errorf("error getting block height of %s: %w", c.item.Name, err)
+	}
+
+	item := c.item
+	item.recoveryMonitorMu.Lock()
+	defer item.recoveryMonitorMu.Unlock()
+
+	// The first check and any progress reset the stall tracking
+	if item.lastAdvance.IsZero() || height > item.lastHeight {
+		item.lastHeight = height
+		item.lastAdvance = time.Now()
+		item.stallChecks = 0
+		return true, strconv.FormatInt(height, 10), nil
+	}
+
+	item.stallChecks++
+	if item.stallChecks < item.maxStallChecks(c.group) {
+		return true, strconv.FormatInt(height, 10), nil
+	}
+	return false, fmt.Sprintf("stuck at %d for %s", item.lastHeight, time.Since(item.lastAdvance).Round(time.Second)), nil
+}
+
+// checkGroup implementation for block height groups, run by runMonitor
+
+func (c *BlockHeightConfig) checkType() string      { return "block_height" }
+func (c *BlockHeightConfig) groupName() string      { return c.Name }
+func (c *BlockHeightConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *BlockHeightConfig) itemCount() int         { return len(c.Endpoints) }
+
+func (c *BlockHeightConfig) item(i int) monitoredItem {
+	item := &c.Endpoints[i]
+	return monitoredItem{
+		Checker: blockHeightCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Endpoint,
+		threshold: fmt.Sprintf("%d checks", item.maxStallChecks(c)), severity: severityCritical,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestBlockHeightStall checks that a node is only unhealthy once its height
+// hasn't advanced for max_stall_checks checks, and healthy again on progress
+func TestBlockHeightStall(t *testing.T) {
+	noRetries(t)
+
+	height := 100
+	group := &BlockHeightConfig{Name: "group", MaxStallChecks: 2, RequestOptions: RequestOptions{httpClient: fakeClient(func(req *http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, fmt.Sprintf(`{"block":{"header":{"height":"%d"}}}`, height))(req)
+	})}}
+	item := &BlockHeightItem{Name: "node", Endpoint: "http://node.test/blocks/latest", HeightPath: defaultHeightPath, itemState: newItemState()}
+	check := blockHeightCheck{group: group, item: item}
+
+	steps := []struct {
+		height  int
+		healthy bool
+	}{
+		{height: 100, healthy: true}, // First check
+		{height: 101, healthy: true}, // Progress
+		{height: 101, healthy: true}, // First check without progress
+		{height: 101, healthy: false},
+		{height: 101, healthy: false},
+		{height: 102, healthy: true}, // Progress again
+	}
+
+	for i, step := range steps {
+		height = step.height
+		healthy, detail, err := check.Check(context.Background())
+		if err != nil {
+			t.Fatalf("check %d: %v", i+1, err)
+		}
+		if healthy != step.healthy {
+			t.Fatalf("check %d at height %d: expected healthy %v, got %v (%s)", i+1, step.height, step.healthy, healthy, detail)
+		}
+		if !healthy && !strings.HasPrefix(detail, "stuck at 101 for ") {
+			t.Errorf("check %d: unexpected detail %q", i+1, detail)
+		}
+	}
+}
+
+// TestGetBlockHeight checks heights given as strings and as numbers
+func TestGetBlockHeight(t *testing.T) {
+	noRetries(t)
+
+	tests := []struct {
+		name string
+		body string
+		path string
+		want int64
+	}{
+		{name: "cosmos string", body: `{"block":{"header":{"height":"12345"}}}`, path: defaultHeightPath, want: 12345},
+		{name: "number", body: `{"result":{"height":678}}`, path: "result.height", want: 678},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			height, err := getBlockHeight(context.Background(), "http://node.test", tt.path, RequestOptions{httpClient: respond(http.StatusOK, tt.body)})
+			if err != nil {
+				t.Fatalf("getBlockHeight: %v", err)
+			}
+			if height != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, height)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+)
+
+// btcAddressPattern loosely matches base58 and bech32 Bitcoin addresses
+var btcAddressPattern = regexp.MustCompile(`^[a-zA-Z0-9]{25,90}$`)
+
+// btcDefaultDecimals is used when display_denom is set without decimals
+const btcDefaultDecimals = 8
+
+type BTCAddressItem struct {
+	Name             string  `mapstructure:"name"`
+	Address          string  `mapstructure:"address"`           // Bitcoin address
+	Threshold        string  `mapstructure:"threshold"`         // Threshold amount in satoshis
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-address cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-address recovery check interval in seconds
+	Decimals         int     `mapstructure:"decimals"`          // Optional display decimals (default: 8 with display_denom)
+	DisplayDenom     string  `mapstructure:"display_denom"`     // Optional display denom, e.g. BTC
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type BTCAddressConfig struct {
+	Name          string           `mapstructure:"name"`
+	RESTEndpoint  string           `mapstructure:"rest_endpoint"`  // Esplora API base URL, e.g. https://blockstream.info/api
+	CheckInterval Seconds          `mapstructure:"check_interval"` // Optional per-group check interval
+	ExplorerURL   string           `mapstructure:"explorer_url"`   // Optional explorer address page, e.g. https://mempool.space/address/{address}
+	Addresses     []BTCAddressItem `mapstructure:"addresses"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+// esploraAddressResponse is the part of the Esplora /address/{addr} response the balance is computed from
+type esploraAddressResponse struct {
+	ChainStats struct {
+		FundedTxoSum json.Number `json:"funded_txo_sum"`
+		SpentTxoSum  json.Number `json:"spent_txo_sum"`
+	} `json:"chain_stats"`
+}
+
+// validateBTCAddresses validates the Bitcoin address groups and prepares their items
+func validateBTCAddresses(config *Config) error {
+	for i, btcGroup := range config.BTCAddresses {
+		if btcGroup.RESTEndpoint == "" {
+			return fmt.Errorf("REST endpoint is required for BTC address group #%d", i+1)
+		}
+		if err := validateEndpoint(btcGroup.RESTEndpoint); err != nil {
+			return fmt.Errorf("BTC address group #%d: %w", i+1, err)
+		}
+		if btcGroup.Name == "" {
+			config.BTCAddresses[i].Name = fmt.Sprintf("BTC Address Group %d", i+1) // Set default name if not provided
+			btcGroup.Name = config.BTCAddresses[i].Name
+		}
+		if err := btcGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("BTC address group '%s': %w", config.BTCAddresses[i].Name, err)
+		}
+		if err := validateExplorerURL(btcGroup.ExplorerURL); err != nil {
+			return fmt.Errorf("BTC address group '%s': %w", config.BTCAddresses[i].Name, err)
+		}
+
+		for j, addr := range btcGroup.Addresses {
+			item := &config.BTCAddresses[i].Addresses[j]
+			if !btcAddressPattern.MatchString(addr.Address) {
+				return fmt.Errorf("invalid address '%s' for BTC address item #%d in group '%s'", addr.Address, j+1, btcGroup.Name)
+			}
+			if _, ok := new(big.Int).SetString(addr.Threshold, 10); !ok {
+				return fmt.Errorf("threshold must be an amount in satoshis for BTC address '%s' in group '%s'", addr.Address, btcGroup.Name)
+			}
+			if addr.Decimals < 0 {
+				return fmt.Errorf("decimals must not be negative for BTC address '%s' in group '%s'", addr.Address, btcGroup.Name)
+			}
+			if addr.Decimals == 0 && addr.DisplayDenom != "" {
+				item.Decimals = btcDefaultDecimals
+			}
+			if addr.Name == "" {
+				item.Name = fmt.Sprintf("BTC Wallet %d", j+1) // Set default name if not provided
+			}
+			if err := addr.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("BTC address '%s' in group '%s': %w", addr.Address, btcGroup.Name, err)
+			}
+			if addr.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for BTC address '%s' in group '%s'", addr.Address, btcGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// format renders a satoshi amount for messages
+func (b *BTCAddressItem) format(raw string) string {
+	return formatBalance(raw, "sat", b.Decimals, b.DisplayDenom)
+}
+
+// getBTCBalance fetches the confirmed balance of an address in satoshis from an Esplora API
+func getBTCBalance(ctx context.Context, restEndpoint, address string, opts RequestOptions) (*big.Int, error) {
+	addressURL, err := joinEndpoint(restEndpoint, "/address/"+address)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpGet(ctx, addressURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %w", unexpectedStatus(resp))
+	}
+
+	var addrResp esploraAddressResponse
+	if err := json.Unmarshal(resp.Body, &addrResp); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	// Addresses without transactions report zeroed sums
+	funded, ok := new(big.Int).SetString(addrResp.ChainStats.FundedTxoSum.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid funded_txo_sum %q", addrResp.ChainStats.FundedTxoSum)
+	}
+	spent, ok := new(big.Int).SetString(addrResp.ChainStats.SpentTxoSum.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid spent_txo_sum %q", addrResp.ChainStats.SpentTxoSum)
+	}
+
+	return funded.Sub(funded, spent), nil
+}
+
+// btcAddressCheck checks a BTC balance with its group's endpoint
+type btcAddressCheck struct {
+	group *BTCAddressConfig
+	item  *BTCAddressItem
+}
+
+// Check fetches the balance and reports it unhealthy below the threshold
+func (c btcAddressCheck) Check(ctx context.Context) (bool, string, error) {
+	currentAmount, err := getBTCBalance(ctx, c.group.RESTEndpoint, c.item.Address, c.group.RequestOptions)
+	if err != nil {
+		return false, "", fmt.Errorf("error checking %s: %w", c.item.Name, err)
+	}
+
+	thresholdAmount, ok := new(big.Int).SetString(c.item.Threshold, 10)
+	if !ok {
+		return false, "", fmt.Errorf("invalid threshold amount for %s: %s", c.item.Name, c.item.Threshold)
+	}
+
+	recordBalance("btc", c.group.Name, c.item.Name, c.item.Address, "sat", currentAmount, thresholdAmount)
+	return currentAmount.Cmp(thresholdAmount) >= 0, c.item.format(currentAmount.String()), nil
+}
+
+// checkGroup implementation for BTC address groups, run by runMonitor
+
+func (c *BTCAddressConfig) checkType() string      { return "btc_balance" }
+func (c *BTCAddressConfig) groupName() string      { return c.Name }
+func (c *BTCAddressConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *BTCAddressConfig) itemCount() int         { return len(c.Addresses) }
+
+func (c *BTCAddressConfig) item(i int) monitoredItem {
+	item := &c.Addresses[i]
+	return monitoredItem{
+		Checker: btcAddressCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Address,
+		threshold: "below " + item.format(item.Threshold), link: explorerLine(c.ExplorerURL, item.Address),
+		keyParts: []string{item.Address}, severity: severityWarning,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
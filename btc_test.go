@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestGetBTCBalance checks that the confirmed balance is the funded minus the
+// spent outputs, and that unconfirmed mempool activity is ignored
+func TestGetBTCBalance(t *testing.T) {
+	noRetries(t)
+
+	var path string
+	client := fakeClient(func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return respond(http.StatusOK, `{"address":"bc1qtest","chain_stats":{"funded_txo_sum":250000000,"spent_txo_sum":50000000},"mempool_stats":{"funded_txo_sum":0,"spent_txo_sum":100000000}}`)(req)
+	})
+
+	balance, err := getBTCBalance(context.Background(), "http://esplora.test/api", "bc1qtest", RequestOptions{httpClient: client})
+	if err != nil {
+		t.Fatalf("getBTCBalance: %v", err)
+	}
+	if path != "/api/address/bc1qtest" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if balance.String() != "200000000" {
+		t.Errorf("expected 200000000 sat, got %s", balance)
+	}
+
+	if _, err := getBTCBalance(context.Background(), "http://esplora.test/api", "bc1qtest", RequestOptions{httpClient: respond(http.StatusBadRequest, "Invalid Bitcoin address")}); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+type CertCheckItem struct {
+	Name             string  `mapstructure:"name"`
+	Address          string  `mapstructure:"address"`           // host:port serving TLS
+	ServerName       string  `mapstructure:"server_name"`       // Optional SNI name, defaults to the host of address
+	WarnDays         int     `mapstructure:"warn_days"`         // Optional per-check override of the group warn_days
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-check cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-check recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type CertCheckConfig struct {
+	Name          string          `mapstructure:"name"`
+	CheckInterval Seconds         `mapstructure:"check_interval"` // Optional per-group check interval
+	WarnDays      int             `mapstructure:"warn_days"`      // Alert when a certificate expires within this many days
+	Checks        []CertCheckItem `mapstructure:"checks"`
+}
+
+// validateCertChecks validates the certificate check groups and prepares their items
+func validateCertChecks(config *Config) error {
+	for i, certGroup := range config.CertChecks {
+		if certGroup.Name == "" {
+			config.CertChecks[i].Name = fmt.Sprintf("Certificate Check Group %d", i+1) // Set default name if not provided
+			certGroup.Name = config.CertChecks[i].Name
+		}
+		if certGroup.WarnDays < 0 {
+			return fmt.Errorf("warn_days must not be negative for certificate check group '%s'", certGroup.Name)
+		}
+		if certGroup.WarnDays == 0 {
+			config.CertChecks[i].WarnDays = 14 // Default to 14 days if not specified
+		}
+
+		for j, check := range certGroup.Checks {
+			item := &config.CertChecks[i].Checks[j]
+			host, _, err := net.SplitHostPort(check.Address)
+			if err != nil {
+				return fmt.Errorf("invalid address '%s' for certificate check #%d in group '%s': %s", check.Address, j+1, certGroup.Name, hostPortFormat)
+			}
+			if check.WarnDays < 0 {
+				return fmt.Errorf("warn_days must not be negative for certificate check '%s' in group '%s'", check.Address, certGroup.Name)
+			}
+			if check.ServerName == "" {
+				item.ServerName = host
+			}
+			if check.Name == "" {
+				item.Name = check.Address // Default to the address if no name is provided
+			}
+			if err := check.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("certificate check '%s' in group '%s': %w", check.Address, certGroup.Name, err)
+			}
+			if check.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for certificate check '%s' in group '%s'", check.Address, certGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// warnDays returns the item's warn_days, falling back to the group setting
+func (c *CertCheckItem) warnDays(certConfig *CertCheckConfig) int {
+	if c.WarnDays > 0 {
+		return c.WarnDays
+	}
+	return certConfig.WarnDays
+}
+
+// getCertExpiration connects to addr and returns when its leaf certificate expires
+func getCertExpiration(ctx context.Context, addr, serverName string) (time.Time, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: tcpDialTimeout},
+		Config: &tls.Config{
+			ServerName: serverName,
+			// Only the expiry is inspected, and an expired certificate must still be readable
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("no certificate presented by %s", addr)
+	}
+	return certs[0].NotAfter, nil
+}
+
+// certRemainingDays returns the whole days left until expiration
+func certRemainingDays(expiration time.Time) int {
+	return int(time.Until(expiration).Hours() / 24)
+}
+
+// certExpiring reports whether a certificate expires within warnDays
+func certExpiring(expiration time.Time, warnDays int) bool {
+	return time.Until(expiration) < time.Duration(warnDays)*24*time.Hour
+}
+
+// certCheck checks a certificate against its group's warn_days
+type certCheck struct {
+	group *CertCheckConfig
+	item  *CertCheckItem
+}
+
+// Check reads the certificate and reports it unhealthy once it expires within
+// its warn_days
+func (c certCheck) Check(ctx context.Context) (bool, string, error) {
+	expiration, err := getCertExpiration(ctx, c.item.Address, c.item.ServerName)
+	if err != nil {
+		return false, "", fmt.Errorf("error getting certificate of %s: %w", c.item.Name, err)
+	}
+
+	detail := fmt.Sprintf("expires in %d days (%s)", certRemainingDays(expiration), expiration.UTC().Format(time.RFC3339))
+	if time.Until(expiration) <= 0 {
+		detail = "expired on " + expiration.UTC().Format(time.RFC3339)
+	}
+	return !certExpiring(expiration, c.item.warnDays(c.group)), detail, nil
+}
+
+// checkGroup implementation for certificate check groups, run by runMonitor
+
+func (c *CertCheckConfig) checkType() string      { return "cert" }
+func (c *CertCheckConfig) groupName() string      { return c.Name }
+func (c *CertCheckConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *CertCheckConfig) itemCount() int         { return len(c.Checks) }
+
+func (c *CertCheckConfig) item(i int) monitoredItem {
+	item := &c.Checks[i]
+	return monitoredItem{
+		Checker: certCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Address,
+		threshold: fmt.Sprintf("%d days", item.warnDays(c)), severity: severityWarning,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
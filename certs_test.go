@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGetCertExpiration checks that the expiry of the presented certificate is read
+func TestGetCertExpiration(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	expiration, err := getCertExpiration(context.Background(), strings.TrimPrefix(server.URL, "https://"), "example.com")
+	if err != nil {
+		t.Fatalf("getCertExpiration: %v", err)
+	}
+	if !expiration.Equal(server.Certificate().NotAfter) {
+		t.Errorf("expected %s, got %s", server.Certificate().NotAfter, expiration)
+	}
+}
+
+// TestCertExpiring checks the warn_days window
+func TestCertExpiring(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration time.Time
+		expiring   bool
+	}{
+		{name: "expired", expiration: time.Now().Add(-time.Hour), expiring: true},
+		{name: "within warn_days", expiration: time.Now().Add(6 * 24 * time.Hour), expiring: true},
+		{name: "after warn_days", expiration: time.Now().Add(8 * 24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		if got := certExpiring(tt.expiration, 7); got != tt.expiring {
+			t.Errorf("%s: expected expiring %v, got %v", tt.name, tt.expiring, got)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// defaultStartupMessage returns the message sent to the channels that are
+// verified at startup unless startup_message sets another text or disables it
+func defaultStartupMessage(withEmoji bool) string {
+	return emojiOrTag(emojiStartup, withEmoji) + " Monitor started"
+}
+
+// notificationChannel is a global destination of notifications, such as a
+// chat or a paging service. The Notifier fans every notification out to the
+// configured channels, so adding a channel only takes a new implementation.
+type notificationChannel interface {
+	// name identifies the channel in logs, metrics and dead letters
+	name() string
+	// incidentsOnly reports whether the channel only takes alerts and
+	// recoveries, like a paging service, and never plain messages or digests
+	incidentsOnly() bool
+	// accepts reports whether the channel takes notifications of a severity,
+	// "" for plain messages
+	accepts(severity string) bool
+	// send delivers a Telegram-formatted message, inc is set for alerts and recoveries
+	send(message string, inc *incident) error
+}
+
+type telegramChannel struct {
+	sender      *telegramSender
+	minSeverity string
+}
+
+func (c telegramChannel) name() string                 { return "telegram" }
+func (c telegramChannel) incidentsOnly() bool          { return false }
+func (c telegramChannel) accepts(severity string) bool { return meetsSeverity(severity, c.minSeverity) }
+func (c telegramChannel) send(message string, _ *incident) error {
+	return c.sender.send(message)
+}
+
+type slackChannel struct{ config SlackConfig }
+
+func (c slackChannel) name() string        { return "slack" }
+func (c slackChannel) incidentsOnly() bool { return false }
+func (c slackChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c slackChannel) send(message string, _ *incident) error {
+	return sendSlackMessage(c.config, message)
+}
+
+type discordChannel struct{ config DiscordConfig }
+
+func (c discordChannel) name() string        { return "discord" }
+func (c discordChannel) incidentsOnly() bool { return false }
+func (c discordChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c discordChannel) send(message string, _ *incident) error {
+	return sendDiscordMessage(c.config.WebhookURL, message)
+}
+
+type mattermostChannel struct{ config MattermostConfig }
+
+func (c mattermostChannel) name() string        { return "mattermost" }
+func (c mattermostChannel) incidentsOnly() bool { return false }
+func (c mattermostChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c mattermostChannel) send(message string, _ *incident) error {
+	return sendMattermostMessage(c.config, plainText(message))
+}
+
+type teamsChannel struct{ config TeamsConfig }
+
+func (c teamsChannel) name() string        { return "teams" }
+func (c teamsChannel) incidentsOnly() bool { return false }
+func (c teamsChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c teamsChannel) send(message string, inc *incident) error {
+	return sendTeamsMessage(c.config.WebhookURL, plainText(message), inc)
+}
+
+type pushoverChannel struct{ config PushoverConfig }
+
+func (c pushoverChannel) name() string        { return "pushover" }
+func (c pushoverChannel) incidentsOnly() bool { return false }
+func (c pushoverChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c pushoverChannel) send(message string, inc *incident) error {
+	return c.config.sendPushover(plainText(message), inc)
+}
+
+type matrixChannel struct{ config MatrixConfig }
+
+func (c matrixChannel) name() string        { return "matrix" }
+func (c matrixChannel) incidentsOnly() bool { return false }
+func (c matrixChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c matrixChannel) send(message string, _ *incident) error {
+	return c.config.sendMatrixMessage(message)
+}
+
+type emailChannel struct{ config EmailConfig }
+
+func (c emailChannel) name() string        { return "email" }
+func (c emailChannel) incidentsOnly() bool { return false }
+func (c emailChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c emailChannel) send(message string, _ *incident) error {
+	text := plainText(message)
+	subject := strings.SplitN(text, "\n", 2)[0]
+	return c.config.sendEmail(subject, text)
+}
+
+type pagerDutyChannel struct {
+	config PagerDutyConfig
+	source string // Host name reported as the event source
+}
+
+func (c pagerDutyChannel) name() string        { return "pagerduty" }
+func (c pagerDutyChannel) incidentsOnly() bool { return true }
+func (c pagerDutyChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c pagerDutyChannel) send(message string, inc *incident) error {
+	summary := strings.SplitN(plainText(message), "\n", 2)[0]
+	return sendPagerDutyEvent(c.config.RoutingKey, *inc, summary, c.source)
+}
+
+type opsgenieChannel struct {
+	config OpsgenieConfig
+	source string // Host name reported as the alert source
+}
+
+func (c opsgenieChannel) name() string        { return "opsgenie" }
+func (c opsgenieChannel) incidentsOnly() bool { return true }
+func (c opsgenieChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.config.MinSeverity)
+}
+func (c opsgenieChannel) send(message string, inc *incident) error {
+	return c.config.sendOpsgenieEvent(*inc, plainText(message), c.source)
+}
+
+type smsChannel struct{ config SMSConfig }
+
+func (c smsChannel) name() string        { return "sms" }
+func (c smsChannel) incidentsOnly() bool { return true }
+
+// accepts only critical alerts and their recoveries, SMS costs money
+func (c smsChannel) accepts(severity string) bool { return severity == severityCritical }
+func (c smsChannel) send(message string, inc *incident) error {
+	return c.config.sendSMS(smsBody(plainText(message), inc))
+}
+
+// agentSource returns the host name reported as the source of PagerDuty and Opsgenie events
+func agentSource() string {
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
+	}
+	return "observability-agent"
+}
+
+// configuredChannels returns the channels set up in the config, in delivery
+// order. Telegram is used if telegram is not nil.
+func configuredChannels(telegram *telegramSender, config *Config) []notificationChannel {
+	var channels []notificationChannel
+	if telegram != nil {
+		channels = append(channels, telegramChannel{sender: telegram, minSeverity: config.Telegram.MinSeverity})
+	}
+	if config.Slack.WebhookURL != "" {
+		channels = append(channels, slackChannel{config.Slack})
+	}
+	if config.Discord.WebhookURL != "" {
+		channels = append(channels, discordChannel{config.Discord})
+	}
+	if config.Mattermost.WebhookURL != "" {
+		channels = append(channels, mattermostChannel{config.Mattermost})
+	}
+	if config.Teams.WebhookURL != "" {
+		channels = append(channels, teamsChannel{config.Teams})
+	}
+	if config.Pushover.Token != "" {
+		channels = append(channels, pushoverChannel{config.Pushover})
+	}
+	if config.Matrix.Homeserver != "" {
+		channels = append(channels, matrixChannel{config.Matrix})
+	}
+	if config.Email.SMTPHost != "" {
+		channels = append(channels, emailChannel{config.Email})
+	}
+	if config.PagerDuty.RoutingKey != "" {
+		channels = append(channels, pagerDutyChannel{config: config.PagerDuty, source: agentSource()})
+	}
+	if config.Opsgenie.APIKey != "" {
+		channels = append(channels, opsgenieChannel{config: config.Opsgenie, source: agentSource()})
+	}
+	if config.SMS.AccountSID != "" {
+		channels = append(channels, smsChannel{config.SMS})
+	}
+	return channels
+}
+
+// startChannels sets up the configured channels for monitoring. Telegram and
+// Discord are tested with the startup message, or silently when it's
+// disabled, and left out if the test fails, so a misconfigured channel
+// doesn't stop the agent.
+func startChannels(config *Config) []notificationChannel {
+	// Initialize Telegram bot only if token is provided
+	var telegram *telegramSender
+	if config.Telegram.BotToken != "" {
+		// Share the notification client so Telegram requests get the same timeout and connection pool
+		bot, err := tgbotapi.NewBotAPIWithClient(config.Telegram.BotToken, tgbotapi.APIEndpoint, notificationClient)
+		if err != nil {
+			slog.Warn("failed to initialize Telegram bot, continuing without Telegram notifications", "error", err)
+		} else {
+			telegram = newTelegramSender(bot, config.Telegram.ChatID, config.Telegram.ParseMode, time.Duration(config.Telegram.SendTimeout)*time.Second)
+		}
+	}
+
+	var channels []notificationChannel
+	for _, channel := range configuredChannels(telegram, config) {
+		attrs := []any{"channel", channel.name()}
+		switch c := channel.(type) {
+		case telegramChannel, discordChannel:
+			if err := testChannel(channel, config.StartupMessage); err != nil {
+				slog.Warn("failed to send test message, continuing without the channel", "channel", channel.name(), "error", err)
+				if _, ok := c.(telegramChannel); ok {
+					slog.Warn("please make sure you have started a chat with the Telegram bot and the chat ID is correct")
+				}
+				continue
+			}
+			attrs = append(attrs, "tested", true)
+		case matrixChannel:
+			attrs = append(attrs, "room_id", c.config.RoomID)
+		case opsgenieChannel:
+			attrs = append(attrs, "region", c.config.Region)
+		case smsChannel:
+			attrs = append(attrs, "recipients", len(c.config.To), "critical_only", true)
+		case emailChannel:
+			attrs = append(attrs, "smtp_host", c.config.SMTPHost, "smtp_port", c.config.SMTPPort, "recipients", len(c.config.To))
+		}
+		slog.Info("notifications enabled", attrs...)
+		channels = append(channels, channel)
+	}
+
+	if !hasChatChannel(channels) {
+		slog.Info("running in stdout-only mode (no Telegram, Slack, Discord, Mattermost, Teams, Pushover, Matrix, or email notifications)")
+	}
+	return channels
+}
+
+// testChannel tests the connection of a channel by sending it the startup
+// message. Without one the channel is only checked to be reachable, so
+// frequent restarts don't post to the chat.
+func testChannel(channel notificationChannel, message string) error {
+	if message != "" {
+		return channel.send(message, nil)
+	}
+	switch c := channel.(type) {
+	case telegramChannel:
+		return c.sender.checkChat()
+	case discordChannel:
+		return checkDiscordWebhook(c.config.WebhookURL)
+	}
+	return nil
+}
+
+// hasChatChannel reports whether any of the channels takes plain messages
+func hasChatChannel(channels []notificationChannel) bool {
+	for _, channel := range channels {
+		if !channel.incidentsOnly() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasIncidentsOnlyChannel reports whether any of the channels only takes alerts and recoveries
+func hasIncidentsOnlyChannel(channels []notificationChannel) bool {
+	for _, channel := range channels {
+		if channel.incidentsOnly() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,522 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Checker checks a single monitored item. Check reports whether the item is
+// healthy, along with what the check saw, e.g. a balance or a block height,
+// for messages and /status. An error means the item couldn't be checked, e.g.
+// its endpoint didn't answer. Everything else, the cooldown, silences,
+// recovery and notifications, is handled by runMonitor for every item type
+// alike.
+type Checker interface {
+	Check(ctx context.Context) (healthy bool, detail string, err error)
+}
+
+// checkFunc adapts a function to a Checker
+type checkFunc func(ctx context.Context) (bool, string, error)
+
+func (f checkFunc) Check(ctx context.Context) (bool, string, error) { return f(ctx) }
+
+// checkGroup is a group of monitored items of one type, run by runMonitor
+type checkGroup interface {
+	// checkType is the item type used in logs, metrics and dedup keys
+	checkType() string
+	groupName() string
+	// groupInterval is the group's check_interval override, 0 for the global interval
+	groupInterval() Seconds
+	itemCount() int
+	// item returns the item at index i
+	item(i int) monitoredItem
+}
+
+// itemState is the alerting state of a monitored item, embedded in every
+// item type. It is guarded by recoveryMonitorMu, except for backoff, which
+// only the item's group goroutine uses.
+type itemState struct {
+	lastAlertTime       time.Time     // Last alert, for the cooldown
+	unhealthySince      time.Time     // First unhealthy check of the current breach, zero while healthy
+	alerted             bool          // Whether the current breach was alerted, so its end is a recovery
+	paged               bool          // Whether the current breach reached the incident channels
+	status              itemStatus    // Result of the last check, for /status
+	backoff             itemBackoff   // Backoff of the checks after consecutive failures
+	latency             time.Duration // Response time of the last regular check
+	isUnhealthy         bool          // Track if currently in unhealthy state
+	recoveryMonitorStop chan bool     // Channel to stop recovery monitoring
+	recoveryMonitorMu   *sync.Mutex   // Pointer to avoid copy issues
+}
+
+// newItemState returns the state of an item that hasn't been checked yet
+func newItemState() itemState {
+	return itemState{recoveryMonitorMu: &sync.Mutex{}}
+}
+
+// monitoredItem is an item of a group as runMonitor sees it: its Checker and
+// state, and what its alerts need
+type monitoredItem struct {
+	Checker
+	state *itemState
+
+	// recoveryChecker optionally checks the item for its recovery monitor,
+	// for items whose regular check keeps state the monitor mustn't move
+	recoveryChecker Checker
+
+	name       string
+	target     string          // Address, endpoint or host the item checks, for messages
+	threshold  string          // Threshold or expectation, for messages and /status
+	link       string          // Optional last line of alerts, e.g. an explorer link
+	keyParts   []string        // Dedup key parts after the type, group and name
+	severity   string          // Severity unless the item sets one
+	cooldown   Seconds         // Item's alert_cooldown, 0 for the global cooldown
+	recovery   Seconds         // Item's recovery_interval, 0 for the global interval
+	override   WebhookOverride // Item's webhook and severity settings
+	hysteresis *Hysteresis     // Optional trigger_after and recover_after
+	alertDelay time.Duration   // How long the item must be unhealthy before alerting
+	conditions []condition     // Further alerts judged from the item's regular check
+
+	passive bool // Recovers on the next regular check, without a recovery monitor
+	event   bool // One-off events such as a balance drop, which never send a recovery
+}
+
+// condition is a further alert of an item judged from its regular check
+// rather than a request of its own, e.g. the response time of the check. It
+// has its own state and dedup key, and recovers on the next regular check
+// that passes.
+type condition struct {
+	Checker
+	state     *itemState
+	keyPart   string // Appended to the item's dedup key
+	threshold string
+	severity  string // Severity unless the item sets one, the item's default if empty
+	event     bool   // One-off events such as a balance drop, which never send a recovery
+}
+
+// conditionItem returns the item a condition alerts as, under the name of the
+// item it belongs to
+func (m monitoredItem) conditionItem(c condition) monitoredItem {
+	item := m
+	item.Checker = c.Checker
+	item.state = c.state
+	item.threshold = c.threshold
+	if c.severity != "" {
+		item.severity = c.severity
+	}
+	item.keyParts = append(slices.Clone(m.keyParts), c.keyPart)
+	item.hysteresis = nil
+	item.alertDelay = 0
+	item.conditions = nil
+	item.recoveryChecker = nil
+	item.passive = true
+	item.event = c.event
+	return item
+}
+
+// runMonitor checks the items of a group on startup and then every interval
+func runMonitor(g checkGroup, notifier *Notifier, interval time.Duration, globalCooldown int, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	itemType, group := g.checkType(), g.groupName()
+	slog.Info("started monitoring group", "type", itemType, "group", group, "items", g.itemCount())
+
+	// Groups fetching every item from one endpoint also alert when it's down
+	endpoint, shared := g.(endpointGroup)
+	var reachability groupReachability
+
+	cycle := func() {
+		var results cycleResults
+		checkCycle(notifier, itemType, group, g.itemCount(), func(i int) {
+			results.add(checkGroupItem(g, i, notifier, globalCooldown))
+		})
+		if shared {
+			reachability.update(endpoint, &results, notifier, globalCooldown)
+		}
+	}
+
+	// Spread the first checks of groups that start together
+	sleepJitter(interval)
+	cycle()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sleepJitter(interval)
+		cycle()
+	}
+}
+
+// checkGroupItem checks the item at index i unless it is backing off, and
+// logs a failed check and keeps its error for /status. It reports whether the
+// check ran and its error.
+func checkGroupItem(g checkGroup, i int, notifier *Notifier, globalCooldown int) (bool, error) {
+	item := g.item(i)
+	a := itemAlert{itemType: g.checkType(), group: g.groupName(), item: item, notifier: notifier,
+		cooldown: alertCooldown(item.cooldown, globalCooldown)}
+	_, shared := g.(endpointGroup)
+
+	checked, err := item.state.backoff.run(a.itemType, a.group, item.name, func() error {
+		return a.check(shared)
+	})
+	if err != nil {
+		slog.Error("check failed", "type", a.itemType, "group", a.group, "item", item.name, "error", err)
+		item.state.status.recordError(item.state.recoveryMonitorMu, err)
+	}
+	return checked, err
+}
+
+// itemAlert is an item being checked along with where its alerts go
+type itemAlert struct {
+	itemType string
+	group    string
+	item     monitoredItem
+	notifier *Notifier
+	cooldown int // Cooldown between alerts in seconds
+}
+
+// check runs a regular check of the item, alerts or recovers it, and judges
+// its conditions. An item that can't be checked is unhealthy, except in
+// groups sharing one endpoint, whose items only log the failed fetch since
+// the group alerts once the endpoint is down.
+func (a itemAlert) check(shared bool) error {
+	start := time.Now()
+	healthy, detail, err := a.item.Check(context.Background())
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil && shared {
+		return err
+	}
+
+	state := a.item.state
+	state.recoveryMonitorMu.Lock()
+	state.latency = elapsed
+	state.status.set(detail)
+	state.recoveryMonitorMu.Unlock()
+	recordAlerting(a.itemType, a.group, a.item.name, !healthy || err != nil)
+	slog.Debug("item checked", "type", a.itemType, "group", a.group, "item", a.item.name,
+		"value", detail, "healthy", healthy && err == nil, "elapsed", elapsed)
+
+	if healthy && err == nil {
+		a.recover(detail)
+	} else {
+		a.breach(detail, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Conditions are judged from the check that just answered
+	for _, c := range a.item.conditions {
+		cond := a
+		cond.item = a.item.conditionItem(c)
+		if healthy, detail, _ := c.Check(context.Background()); healthy {
+			cond.recover(detail)
+		} else {
+			cond.breach(detail, nil)
+		}
+	}
+	return nil
+}
+
+// breach handles a regular check that found the item unhealthy. The item
+// alerts once it has breached for trigger_after consecutive checks and for
+// its alert delay, unless its cooldown or a silence holds the alert back,
+// and a recovery monitor watches it until it recovers. Until the item has
+// been unhealthy for its page_after the alert only goes to the chat
+// channels, and the first check after that pages the incident channels.
+func (a itemAlert) breach(detail string, err error) {
+	item, state := a.item, a.item.state
+	now := time.Now()
+
+	state.recoveryMonitorMu.Lock()
+	if state.unhealthySince.IsZero() {
+		state.unhealthySince = now
+	}
+	if item.hysteresis != nil && !item.hysteresis.observe(true) {
+		breaches := item.hysteresis.breaches
+		state.recoveryMonitorMu.Unlock()
+		slog.Info("alert pending", "type", a.itemType, "group", a.group, "item", item.name,
+			"breaches", breaches, "trigger_after", item.hysteresis.TriggerAfter)
+		return
+	}
+
+	// Start recovery monitoring if not already started
+	if item.passive {
+		state.isUnhealthy = true
+	} else {
+		startRecoveryMonitor(state.recoveryMonitorMu, &state.isUnhealthy, &state.recoveryMonitorStop, a.monitorRecovery)
+	}
+	unhealthyFor := now.Sub(state.unhealthySince)
+	lastAlertTime, alerted, paged := state.lastAlertTime, state.alerted, state.paged
+	// Notifying can block on a full queue, so it's done without the lock
+	state.recoveryMonitorMu.Unlock()
+
+	if unhealthyFor < item.alertDelay {
+		slog.Info("alert pending", "type", a.itemType, "group", a.group, "item", item.name,
+			"unhealthy_for", unhealthyFor.Round(time.Second), "alert_delay", item.alertDelay)
+		return
+	}
+	pageAfter := time.Duration(item.override.PageAfter) * time.Second
+	paging := unhealthyFor >= pageAfter
+
+	// Check if we're still in cooldown period
+	if !shouldAlert(lastAlertTime, a.cooldown) {
+		// The page of an alert sent to chat isn't held back by the cooldown
+		if alerted && !paged && paging && !silenced(a.itemType, a.group, item.name) {
+			a.page(detail, err, unhealthyFor)
+			return
+		}
+		slog.Info("alert suppressed by cooldown", "type", a.itemType, "group", a.group, "item", item.name,
+			"remaining", cooldownRemaining(lastAlertTime, a.cooldown))
+		return
+	}
+
+	// Don't notify while the item is silenced, the alert fires once the silence ends
+	if silenced(a.itemType, a.group, item.name) {
+		return
+	}
+
+	message, details := a.message(eventAlert, detail, err, unhealthyFor)
+	var sent delivery
+	if paging {
+		sent = a.notifier.Alert(message, item.override, a.dedupKey(), a.severity(), details)
+	} else {
+		sent = a.notifier.AlertChat(message, item.override, a.dedupKey(), a.severity(), details)
+		slog.Info("page held back", "type", a.itemType, "group", a.group, "item", item.name,
+			"unhealthy_for", unhealthyFor.Round(time.Second), "page_after", pageAfter)
+	}
+	slog.Warn("item unhealthy", "type", a.itemType, "group", a.group, "item", item.name, "target", item.target,
+		"value", detail, "threshold", item.threshold, "error", err, "error_class", errorClass(err))
+
+	// A deduped or throttled alert still starts the cooldown, but only the
+	// channels that got it are sent the recovery. Without incident channels
+	// the page of an alert sent while paging is done.
+	state.recoveryMonitorMu.Lock()
+	state.lastAlertTime = time.Now()
+	state.alerted = state.alerted || sent.chat
+	state.paged = state.paged || sent.incidents || (paging && sent.chat)
+	state.recoveryMonitorMu.Unlock()
+}
+
+// page sends the incident of an item whose alert went to chat once it has
+// been unhealthy for its page_after
+func (a itemAlert) page(detail string, err error, unhealthyFor time.Duration) {
+	item, state := a.item, a.item.state
+	message, details := a.message(eventAlert, detail, err, unhealthyFor)
+	a.notifier.Page(message, item.override, a.dedupKey(), a.severity(), details)
+	slog.Warn("item paged", "type", a.itemType, "group", a.group, "item", item.name,
+		"unhealthy_for", unhealthyFor.Round(time.Second), "page_after", time.Duration(item.override.PageAfter)*time.Second)
+
+	state.recoveryMonitorMu.Lock()
+	state.paged = true
+	state.recoveryMonitorMu.Unlock()
+}
+
+// recover handles a check that found the item healthy, from its group or its
+// recovery monitor. An unhealthy item recovers once it has been healthy for
+// recover_after consecutive checks, with a recovery message if its breach
+// was alerted. It reports whether the item recovered.
+func (a itemAlert) recover(detail string) bool {
+	item, state := a.item, a.item.state
+
+	state.recoveryMonitorMu.Lock()
+	if !state.isUnhealthy {
+		// A breach that never triggered ends without a recovery
+		if item.hysteresis != nil {
+			item.hysteresis.observe(false)
+		}
+		state.unhealthySince = time.Time{}
+		state.recoveryMonitorMu.Unlock()
+		return false
+	}
+	if item.hysteresis != nil && !item.hysteresis.recovered(true) {
+		state.recoveryMonitorMu.Unlock()
+		return false
+	}
+	stopRecoveryMonitor(&state.isUnhealthy, &state.recoveryMonitorStop)
+	alerted, paged := state.alerted, state.paged
+	state.unhealthySince = time.Time{}
+	state.alerted = false
+	state.paged = false
+	state.recoveryMonitorMu.Unlock()
+
+	recordAlerting(a.itemType, a.group, item.name, false)
+	if item.event {
+		return true
+	}
+	if !alerted && !paged {
+		slog.Info("item recovered before alerting", "type", a.itemType, "group", a.group, "item", item.name)
+		return true
+	}
+
+	message, details := a.message(eventRecovery, detail, nil, 0)
+	switch {
+	case silenced(a.itemType, a.group, item.name):
+	case alerted && paged:
+		a.notifier.Resolve(message, item.override, a.dedupKey(), a.severity(), details)
+	case paged:
+		// The alert cap held the alert back from chat
+		a.notifier.ResolveIncidents(message, item.override, a.dedupKey(), a.severity(), details)
+	default:
+		// The page was cancelled, only chat saw the alert
+		slog.Info("item recovered before paging", "type", a.itemType, "group", a.group, "item", item.name)
+		a.notifier.ResolveChat(message, item.override, a.dedupKey(), a.severity(), details)
+	}
+	slog.Info("item recovered", "type", a.itemType, "group", a.group, "item", item.name, "target", item.target, "value", detail)
+	return true
+}
+
+// monitorRecovery re-checks an unhealthy item every recovery interval until
+// it recovers, each check bounded by recovery_timeout so a hung endpoint
+// can't hold up the monitor. Checks that keep failing back off like the
+// regular ones, so an endpoint that is down isn't polled every interval.
+func (a itemAlert) monitorRecovery(stop <-chan bool) {
+	item := a.item
+	checker := item.Checker
+	if item.recoveryChecker != nil {
+		checker = item.recoveryChecker
+	}
+	ticks, done := recoveryTicks(recoveryPollInterval(item.recovery), stop)
+	defer done()
+
+	var backoff itemBackoff
+	for range ticks {
+		if backoff.skipping() {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), recoveryTimeout)
+		healthy, detail, err := checker.Check(ctx)
+		cancel()
+		backoff.record(err, a.itemType, a.group, item.name)
+		if err != nil {
+			slog.Error("recovery check failed", "type", a.itemType, "group", a.group, "item", item.name, "error", err)
+		} else {
+			item.state.status.record(item.state.recoveryMonitorMu, detail)
+		}
+
+		if healthy && err == nil {
+			if a.recover(detail) {
+				return
+			}
+			continue
+		}
+		// Recovery takes recover_after healthy checks in a row
+		if item.hysteresis != nil {
+			item.state.recoveryMonitorMu.Lock()
+			item.hysteresis.recovered(false)
+			item.state.recoveryMonitorMu.Unlock()
+		}
+	}
+}
+
+// severity returns the severity of the item's alerts
+func (a itemAlert) severity() string {
+	return a.item.override.alertSeverity(a.item.severity)
+}
+
+// dedupKey returns the key the item's alert and recovery share
+func (a itemAlert) dedupKey() string {
+	return dedupKey(a.itemType, a.group, append([]string{a.item.name}, a.item.keyParts...)...)
+}
+
+// message builds the item's alert or recovery message, rendered with the
+// item type's template if one is set, and the data templates can reference
+func (a itemAlert) message(event, detail string, err error, unhealthyFor time.Duration) (string, messageData) {
+	item := a.item
+	details := messageData{Group: a.group, Name: item.name, Address: item.target, Current: detail, Threshold: item.threshold}
+
+	var b strings.Builder
+	switch {
+	case event == eventRecovery:
+		fmt.Fprintf(&b, "%s Recovery: [%s] `%s` has recovered!", messageEmoji(emojiRecovery), a.group, item.name)
+	case err != nil:
+		fmt.Fprintf(&b, "%s Alert: [%s] `%s` check failed!", severityEmoji(a.severity()), a.group, item.name)
+	default:
+		fmt.Fprintf(&b, "%s Alert: [%s] `%s` is unhealthy!", severityEmoji(a.severity()), a.group, item.name)
+	}
+	if item.target != "" {
+		fmt.Fprintf(&b, "\nTarget: `%s`", item.target)
+	}
+	if detail != "" {
+		fmt.Fprintf(&b, "\nCurrent: %s", detail)
+	}
+	if item.threshold != "" {
+		fmt.Fprintf(&b, "\nThreshold: %s", item.threshold)
+	}
+	if err != nil {
+		details.Error = err.Error()
+		details.ErrorClass = errorClass(err)
+		fmt.Fprintf(&b, "\nError: %v", err)
+	}
+	if unhealthyFor >= time.Second {
+		fmt.Fprintf(&b, "\nUnhealthy for: %s", unhealthyFor.Round(time.Second))
+	}
+	if event == eventAlert {
+		b.WriteString(item.link)
+	}
+
+	details.Message = b.String()
+	return renderMessage(a.itemType, event, details), details
+}
+
+// groupCheckers returns every configured group
+func groupCheckers(config *Config) []checkGroup {
+	var checkers []checkGroup
+	for i := range config.Metrics {
+		checkers = append(checkers, &config.Metrics[i])
+	}
+	for i := range config.Addresses {
+		checkers = append(checkers, &config.Addresses[i])
+	}
+	for i := range config.KaspaAddresses {
+		checkers = append(checkers, &config.KaspaAddresses[i])
+	}
+	for i := range config.Health {
+		checkers = append(checkers, &config.Health[i])
+	}
+	for i := range config.KaspaValidators {
+		checkers = append(checkers, &config.KaspaValidators[i])
+	}
+	for i := range config.HeaderChecks {
+		checkers = append(checkers, &config.HeaderChecks[i])
+	}
+	for i := range config.GrantChecks {
+		checkers = append(checkers, &config.GrantChecks[i])
+	}
+	for i := range config.EVMAddresses {
+		checkers = append(checkers, &config.EVMAddresses[i])
+	}
+	for i := range config.BTCAddresses {
+		checkers = append(checkers, &config.BTCAddresses[i])
+	}
+	for i := range config.BlockHeight {
+		checkers = append(checkers, &config.BlockHeight[i])
+	}
+	for i := range config.TCPChecks {
+		checkers = append(checkers, &config.TCPChecks[i])
+	}
+	for i := range config.GRPCHealth {
+		checkers = append(checkers, &config.GRPCHealth[i])
+	}
+	for i := range config.CertChecks {
+		checkers = append(checkers, &config.CertChecks[i])
+	}
+	for i := range config.DNSChecks {
+		checkers = append(checkers, &config.DNSChecks[i])
+	}
+	for i := range config.CosmosValidators {
+		checkers = append(checkers, &config.CosmosValidators[i])
+	}
+	for i := range config.PeerChecks {
+		checkers = append(checkers, &config.PeerChecks[i])
+	}
+	for i := range config.SyncChecks {
+		checkers = append(checkers, &config.SyncChecks[i])
+	}
+	return checkers
+}
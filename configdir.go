@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// configSource is where the config was loaded from, kept so a reload reads
+// the same files
+var configSource struct {
+	path string // Config file, empty for ./config.yaml
+	dir  string // Optional directory of config files merged into it
+}
+
+// readConfig reads the config into viper: the config file, or ./config.yaml
+// by default, and every .yaml and .yml file of configDir in name order. With
+// only a directory no default file is read, so its files alone make up the
+// config.
+func readConfig(configPath, configDir string) error {
+	settings := make(map[string]interface{})
+	origins := make(map[string]string)
+
+	if configDir == "" || configPath != "" {
+		v := viper.New()
+		if configPath != "" {
+			// If a config path is provided, use it directly
+			v.SetConfigFile(configPath)
+		} else {
+			// Default behavior: look for config.yaml in the current directory
+			v.SetConfigName("config")
+			v.SetConfigType("yaml")
+			v.AddConfigPath(".")
+		}
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("error reading config file: %w", err)
+		}
+		if err := mergeConfigSettings(settings, v.AllSettings(), "", v.ConfigFileUsed(), origins); err != nil {
+			return err
+		}
+	}
+
+	if configDir != "" {
+		files, err := configDirFiles(configDir)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			v := viper.New()
+			v.SetConfigFile(file)
+			if err := v.ReadInConfig(); err != nil {
+				return fmt.Errorf("error reading config file %s: %w", file, err)
+			}
+			if err := mergeConfigSettings(settings, v.AllSettings(), "", file, origins); err != nil {
+				return err
+			}
+		}
+	}
+
+	viper.Reset()
+	return viper.MergeConfigMap(settings)
+}
+
+// configDirFiles returns the YAML files of a config directory in name order
+func configDirFiles(configDir string) ([]string, error) {
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.Type().IsRegular() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(configDir, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .yaml or .yml files in config directory %s", configDir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// mergeConfigSettings merges the settings of one config file into those of
+// the files before it. Lists such as addresses or metrics are concatenated
+// and sections such as telegram are merged key by key, but a setting two
+// files give different values is a conflict: which one applies would
+// otherwise depend on the file names. origins records the file that set each
+// setting, for the error.
+func mergeConfigSettings(dst, src map[string]interface{}, prefix, file string, origins map[string]string) error {
+	for key, value := range src {
+		path := prefix + key
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			recordOrigins(value, path, file, origins)
+			continue
+		}
+
+		switch v := value.(type) {
+		case []interface{}:
+			if list, ok := existing.([]interface{}); ok {
+				dst[key] = append(list, v...)
+				continue
+			}
+		case map[string]interface{}:
+			if section, ok := existing.(map[string]interface{}); ok {
+				if err := mergeConfigSettings(section, v, path+".", file, origins); err != nil {
+					return err
+				}
+				continue
+			}
+		default:
+			if reflect.DeepEqual(existing, value) {
+				continue
+			}
+		}
+		return fmt.Errorf("conflicting config setting %s: %s sets %v, %s sets %v", path, origins[path], existing, file, value)
+	}
+	return nil
+}
+
+// recordOrigins records the file that set a setting and, for a section, each
+// of its settings
+func recordOrigins(value interface{}, path, file string, origins map[string]string) {
+	origins[path] = file
+	if section, ok := value.(map[string]interface{}); ok {
+		for key, item := range section {
+			recordOrigins(item, path+"."+key, file, origins)
+		}
+	}
+}
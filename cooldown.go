@@ -0,0 +1,27 @@
+package main
+
+import "time"
+
+// alertCooldown returns the item's cooldown in seconds if it sets one, and
+// the global cooldown otherwise
+func alertCooldown(itemCooldown Seconds, globalCooldown int) int {
+	if itemCooldown > 0 {
+		return int(itemCooldown)
+	}
+	return globalCooldown
+}
+
+// cooldownRemaining returns how much of the cooldown in seconds after the last
+// alert is left, zero if the item never alerted or the cooldown is over
+func cooldownRemaining(lastAlert time.Time, cooldown int) time.Duration {
+	if lastAlert.IsZero() {
+		return 0
+	}
+	return max(time.Duration(cooldown)*time.Second-time.Since(lastAlert), 0)
+}
+
+// shouldAlert reports whether an item that last alerted at lastAlert may alert
+// again, i.e. whether it never alerted or its cooldown in seconds is over
+func shouldAlert(lastAlert time.Time, cooldown int) bool {
+	return cooldownRemaining(lastAlert, cooldown) == 0
+}
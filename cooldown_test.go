@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShouldAlert(t *testing.T) {
+	tests := []struct {
+		name      string
+		lastAlert time.Time
+		cooldown  int
+		want      bool
+	}{
+		{
+			name:     "never alerted",
+			cooldown: 3600,
+			want:     true,
+		},
+		{
+			name:      "within cooldown",
+			lastAlert: time.Now().Add(-10 * time.Minute),
+			cooldown:  3600,
+			want:      false,
+		},
+		{
+			name:      "just past cooldown",
+			lastAlert: time.Now().Add(-time.Hour - time.Second),
+			cooldown:  3600,
+			want:      true,
+		},
+		{
+			name:      "no cooldown",
+			lastAlert: time.Now(),
+			cooldown:  0,
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldAlert(tt.lastAlert, tt.cooldown); got != tt.want {
+				t.Errorf("shouldAlert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertCooldown(t *testing.T) {
+	tests := []struct {
+		name           string
+		itemCooldown   Seconds
+		globalCooldown int
+		want           int
+	}{
+		{name: "global cooldown", itemCooldown: 0, globalCooldown: 3600, want: 3600},
+		{name: "item override wins", itemCooldown: 600, globalCooldown: 3600, want: 600},
+		{name: "longer item override wins", itemCooldown: 7200, globalCooldown: 3600, want: 7200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alertCooldown(tt.itemCooldown, tt.globalCooldown); got != tt.want {
+				t.Errorf("alertCooldown() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// An item override that has passed allows an alert even within the global cooldown
+	lastAlert := time.Now().Add(-15 * time.Minute)
+	if !shouldAlert(lastAlert, alertCooldown(600, 3600)) {
+		t.Error("expected the 10 minute item cooldown to have passed")
+	}
+	if shouldAlert(lastAlert, alertCooldown(0, 3600)) {
+		t.Error("expected the 1 hour global cooldown to still apply")
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// bondStatusPrefix is trimmed from staking module bond statuses, e.g. BOND_STATUS_BONDED
+const bondStatusPrefix = "BOND_STATUS_"
+
+// ed25519PubKeyType is the consensus key type whose address can be derived
+const ed25519PubKeyType = "/cosmos.crypto.ed25519.PubKey"
+
+type CosmosValidatorItem struct {
+	Name             string  `mapstructure:"name"`
+	Valoper          string  `mapstructure:"valoper"`           // Validator operator address, e.g. cosmosvaloper1...
+	Valcons          string  `mapstructure:"valcons"`           // Optional consensus address, derived from the consensus key if not set
+	MaxMissedBlocks  int64   `mapstructure:"max_missed_blocks"` // Optional per-validator override of the group max_missed_blocks
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-validator cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-validator recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type CosmosValidatorConfig struct {
+	Name            string                `mapstructure:"name"`
+	RESTEndpoint    string                `mapstructure:"rest_endpoint"`
+	CheckInterval   Seconds               `mapstructure:"check_interval"`    // Optional per-group check interval
+	MaxMissedBlocks int64                 `mapstructure:"max_missed_blocks"` // Alert when a validator missed more blocks in the signing window
+	Validators      []CosmosValidatorItem `mapstructure:"validators"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+type StakingValidatorResponse struct {
+	Validator struct {
+		OperatorAddress string `json:"operator_address"`
+		ConsensusPubkey struct {
+			Type string `json:"@type"`
+			Key  string `json:"key"`
+		} `json:"consensus_pubkey"`
+		Jailed bool   `json:"jailed"`
+		Status string `json:"status"`
+	} `json:"validator"`
+}
+
+type SigningInfoResponse struct {
+	ValSigningInfo struct {
+		Address             string `json:"address"`
+		Tombstoned          bool   `json:"tombstoned"`
+		MissedBlocksCounter string `json:"missed_blocks_counter"`
+	} `json:"val_signing_info"`
+}
+
+// cosmosValidatorState is what a check learned about a validator
+type cosmosValidatorState struct {
+	status       string // Lowercase bond status without its prefix: bonded, unbonding or unbonded
+	jailed       bool
+	tombstoned   bool
+	missedBlocks int64
+}
+
+// validateCosmosValidators validates the cosmos validator groups and prepares their items
+func validateCosmosValidators(config *Config) error {
+	for i, validatorGroup := range config.CosmosValidators {
+		if validatorGroup.RESTEndpoint == "" {
+			return fmt.Errorf("REST endpoint is required for cosmos validator group #%d", i+1)
+		}
+		if err := validateEndpoint(validatorGroup.RESTEndpoint); err != nil {
+			return fmt.Errorf("cosmos validator group #%d: %w", i+1, err)
+		}
+		if validatorGroup.Name == "" {
+			config.CosmosValidators[i].Name = fmt.Sprintf("Cosmos Validator Group %d", i+1) // Set default name if not provided
+			validatorGroup.Name = config.CosmosValidators[i].Name
+		}
+		if err := validatorGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("cosmos validator group '%s': %w", config.CosmosValidators[i].Name, err)
+		}
+		if validatorGroup.MaxMissedBlocks < 0 {
+			return fmt.Errorf("max_missed_blocks must not be negative for cosmos validator group '%s'", validatorGroup.Name)
+		}
+		if validatorGroup.MaxMissedBlocks == 0 {
+			config.CosmosValidators[i].MaxMissedBlocks = 50 // Default to 50 blocks if not specified
+		}
+
+		for j, validator := range validatorGroup.Validators {
+			item := &config.CosmosValidators[i].Validators[j]
+			if validator.Valoper == "" {
+				return fmt.Errorf("valoper is required for validator #%d in group '%s'", j+1, validatorGroup.Name)
+			}
+			if !strings.Contains(validator.Valoper, "valoper1") {
+				return fmt.Errorf("invalid valoper '%s' in group '%s': expected a validator operator address", validator.Valoper, validatorGroup.Name)
+			}
+			if validator.MaxMissedBlocks < 0 {
+				return fmt.Errorf("max_missed_blocks must not be negative for validator '%s' in group '%s'", validator.Valoper, validatorGroup.Name)
+			}
+			if validator.Name == "" {
+				item.Name = validator.Valoper // Default to the operator address if no name is provided
+			}
+			if err := validator.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("validator '%s' in group '%s': %w", validator.Valoper, validatorGroup.Name, err)
+			}
+			if validator.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for validator '%s' in group '%s'", validator.Valoper, validatorGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// maxMissedBlocks returns the item's max_missed_blocks, falling back to the group setting
+func (v *CosmosValidatorItem) maxMissedBlocks(validatorConfig *CosmosValidatorConfig) int64 {
+	if v.MaxMissedBlocks > 0 {
+		return v.MaxMissedBlocks
+	}
+	return validatorConfig.MaxMissedBlocks
+}
+
+// consensusAddress derives the bech32 consensus address of an ed25519
+// consensus key, using the operator address prefix with valcons instead of valoper
+func consensusAddress(valoper, pubKeyType, pubKey string) (string, error) {
+	if pubKeyType != ed25519PubKeyType {
+		return "", fmt.Errorf("can't derive the consensus address of a %s key, set valcons instead", pubKeyType)
+	}
+	key, err := base64.StdEncoding.DecodeString(pubKey)
+	if err != nil {
+		return "", fmt.Errorf("error decoding consensus key: %w", err)
+	}
+
+	sep := strings.LastIndex(valoper, "1")
+	prefix := strings.TrimSuffix(valoper[:sep], "valoper") + "valcons"
+	hash := sha256.Sum256(key)
+	return bech32Encode(prefix, hash[:20])
+}
+
+// getCosmosValidatorState looks up a validator's bond status in the staking
+// module and its missed blocks in the slashing module
+func getCosmosValidatorState(ctx context.Context, restEndpoint string, item *CosmosValidatorItem, opts RequestOptions) (*cosmosValidatorState, error) {
+	validatorURL, err := joinEndpoint(restEndpoint, "/cosmos/staking/v1beta1/validators/"+item.Valoper)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpGet(ctx, validatorURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %w", unexpectedStatus(resp))
+	}
+
+	var validator StakingValidatorResponse
+	if err := json.Unmarshal(resp.Body, &validator); err != nil {
+		return nil, fmt.Errorf("error parsing validator response: %w", err)
+	}
+
+	valcons := item.Valcons
+	if valcons == "" {
+		pubKey := validator.Validator.ConsensusPubkey
+		if valcons, err = consensusAddress(item.Valoper, pubKey.Type, pubKey.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	signingInfoURL, err := joinEndpoint(restEndpoint, "/cosmos/slashing/v1beta1/signing_infos/"+valcons)
+	if err != nil {
+		return nil, err
+	}
+	resp, err = httpGet(ctx, signingInfoURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %w for signing info of %s", unexpectedStatus(resp), valcons)
+	}
+
+	var signingInfo SigningInfoResponse
+	if err := json.Unmarshal(resp.Body, &signingInfo); err != nil {
+		return nil, fmt.Errorf("error parsing signing info response: %w", err)
+	}
+
+	var missedBlocks int64
+	if counter := signingInfo.ValSigningInfo.MissedBlocksCounter; counter != "" {
+		if missedBlocks, err = strconv.ParseInt(counter, 10, 64); err != nil {
+			return nil, fmt.Errorf("error parsing missed blocks counter %q: %w", counter, err)
+		}
+	}
+
+	return &cosmosValidatorState{
+		status:       strings.ToLower(strings.TrimPrefix(validator.Validator.Status, bondStatusPrefix)),
+		jailed:       validator.Validator.Jailed,
+		tombstoned:   signingInfo.ValSigningInfo.Tombstoned,
+		missedBlocks: missedBlocks,
+	}, nil
+}
+
+// validatorProblems lists why a validator needs attention, or nothing if it's
+// bonded with a healthy missed blocks counter
+func validatorProblems(state *cosmosValidatorState, maxMissedBlocks int64) []string {
+	var problems []string
+	if state.tombstoned {
+		problems = append(problems, "is tombstoned")
+	}
+	if state.jailed {
+		problems = append(problems, "is jailed")
+	}
+	if state.status != "bonded" {
+		problems = append(problems, "is "+state.status)
+	}
+	if state.missedBlocks > maxMissedBlocks {
+		problems = append(problems, fmt.Sprintf("missed %d blocks", state.missedBlocks))
+	}
+	return problems
+}
+
+// describe summarizes a validator's state for messages and /status
+func (s *cosmosValidatorState) describe() string {
+	description := fmt.Sprintf("%s, %d missed blocks", s.status, s.missedBlocks)
+	if s.jailed {
+		description += ", jailed"
+	}
+	if s.tombstoned {
+		description += ", tombstoned"
+	}
+	return description
+}
+
+// cosmosValidatorCheck checks a validator against its group's endpoint and max_missed_blocks
+type cosmosValidatorCheck struct {
+	group *CosmosValidatorConfig
+	item  *CosmosValidatorItem
+}
+
+// Check looks up the validator and reports it unhealthy unless it's bonded
+// with a healthy missed blocks counter
+func (c cosmosValidatorCheck) Check(ctx context.Context) (bool, string, error) {
+	state, err := getCosmosValidatorState(ctx, c.group.RESTEndpoint, c.item, c.group.RequestOptions)
+	if err != nil {
+		return false, "", fmt.Errorf("error checking %s: %w", c.item.Name, err)
+	}
+	return len(validatorProblems(state, c.item.maxMissedBlocks(c.group))) == 0, state.describe(), nil
+}
+
+// checkGroup implementation for Cosmos validator groups, run by runMonitor
+
+func (c *CosmosValidatorConfig) checkType() string      { return "cosmos_validator" }
+func (c *CosmosValidatorConfig) groupName() string      { return c.Name }
+func (c *CosmosValidatorConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *CosmosValidatorConfig) itemCount() int         { return len(c.Validators) }
+
+func (c *CosmosValidatorConfig) item(i int) monitoredItem {
+	item := &c.Validators[i]
+	return monitoredItem{
+		Checker: cosmosValidatorCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Valoper,
+		threshold: fmt.Sprintf("%d missed blocks", item.maxMissedBlocks(c)), severity: severityCritical,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestConsensusAddress checks that the valcons address is derived from an
+// ed25519 consensus key with the operator address prefix
+func TestConsensusAddress(t *testing.T) {
+	if got, err := bech32Encode("a", nil); got != "a12uel5l" || err != nil {
+		t.Errorf("expected the BIP-173 test vector a12uel5l, got %q (%v)", got, err)
+	}
+
+	key := "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	got, err := consensusAddress("dymvaloper1qqqqqq", ed25519PubKeyType, key)
+	if expected := "dymvalcons1vvxu62txcsekdygj23ythvjmfl6p9fyus5sy0d"; got != expected || err != nil {
+		t.Errorf("expected %s, got %q (%v)", expected, got, err)
+	}
+
+	if _, err := consensusAddress("dymvaloper1qqqqqq", "/cosmos.crypto.secp256k1.PubKey", key); err == nil {
+		t.Error("expected an error for a key type that can't be derived")
+	}
+}
+
+// TestValidatorProblems checks every reason a validator needs attention
+func TestValidatorProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    cosmosValidatorState
+		expected []string
+	}{
+		{name: "healthy", state: cosmosValidatorState{status: "bonded", missedBlocks: 50}},
+		{name: "missed blocks", state: cosmosValidatorState{status: "bonded", missedBlocks: 51}, expected: []string{"missed 51 blocks"}},
+		{name: "unbonding", state: cosmosValidatorState{status: "unbonding"}, expected: []string{"is unbonding"}},
+		{name: "jailed", state: cosmosValidatorState{status: "unbonding", jailed: true}, expected: []string{"is jailed", "is unbonding"}},
+		{name: "tombstoned", state: cosmosValidatorState{status: "unbonded", jailed: true, tombstoned: true}, expected: []string{"is tombstoned", "is jailed", "is unbonded"}},
+	}
+
+	for _, tt := range tests {
+		if got := validatorProblems(&tt.state, 50); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestCosmosValidatorCheck checks that a validator is looked up in the staking
+// module, then in the slashing module under its derived consensus address
+func TestCosmosValidatorCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		validator   string
+		signingInfo string
+		healthy     bool
+		description string
+	}{
+		{
+			name:        "bonded",
+			validator:   `"status":"BOND_STATUS_BONDED","jailed":false`,
+			signingInfo: `{"val_signing_info":{"missed_blocks_counter":"3"}}`,
+			healthy:     true,
+			description: "bonded, 3 missed blocks",
+		},
+		{
+			name:        "too many missed blocks",
+			validator:   `"status":"BOND_STATUS_BONDED","jailed":false`,
+			signingInfo: `{"val_signing_info":{"missed_blocks_counter":"11"}}`,
+			description: "bonded, 11 missed blocks",
+		},
+		{
+			name:        "jailed",
+			validator:   `"status":"BOND_STATUS_UNBONDING","jailed":true`,
+			signingInfo: `{"val_signing_info":{"missed_blocks_counter":"0"}}`,
+			description: "unbonding, 0 missed blocks, jailed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				paths = append(paths, req.URL.Path)
+				if strings.Contains(req.URL.Path, "/slashing/") {
+					return respond(http.StatusOK, tt.signingInfo)(req)
+				}
+				consensusKey := `"consensus_pubkey":{"@type":"/cosmos.crypto.ed25519.PubKey","key":"AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="}`
+				return respond(http.StatusOK, `{"validator":{`+consensusKey+`,`+tt.validator+`}}`)(req)
+			})
+			group := &CosmosValidatorConfig{RESTEndpoint: "https://rest.test", MaxMissedBlocks: 10, RequestOptions: RequestOptions{httpClient: client}}
+			item := &CosmosValidatorItem{Name: "validator", Valoper: "dymvaloper1qqqqqq"}
+
+			healthy, description, err := cosmosValidatorCheck{group: group, item: item}.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if healthy != tt.healthy || description != tt.description {
+				t.Errorf("expected healthy %v with %q, got %v with %q", tt.healthy, tt.description, healthy, description)
+			}
+			expected := []string{
+				"/cosmos/staking/v1beta1/validators/dymvaloper1qqqqqq",
+				"/cosmos/slashing/v1beta1/signing_infos/dymvalcons1vvxu62txcsekdygj23ythvjmfl6p9fyus5sy0d",
+			}
+			if !reflect.DeepEqual(paths, expected) {
+				t.Errorf("expected requests to %v, got %v", expected, paths)
+			}
+		})
+	}
+}
+
+// TestValidateCosmosValidators checks that validators need an operator
+// address and max_missed_blocks defaults to 50
+func TestValidateCosmosValidators(t *testing.T) {
+	for valoper, valid := range map[string]bool{"dymvaloper1qqqqqq": true, "dym1qqqqqq": false, "": false} {
+		config := &Config{CosmosValidators: []CosmosValidatorConfig{{RESTEndpoint: "https://rest.test", Validators: []CosmosValidatorItem{{Valoper: valoper}}}}}
+		if err := validateCosmosValidators(config); (err == nil) != valid {
+			t.Errorf("valoper %q: expected valid %v, got %v", valoper, valid, err)
+		}
+	}
+
+	config := &Config{CosmosValidators: []CosmosValidatorConfig{{RESTEndpoint: "https://rest.test"}}}
+	if err := validateCosmosValidators(config); err != nil || config.CosmosValidators[0].MaxMissedBlocks != 50 {
+		t.Errorf("expected max_missed_blocks to default to 50, got %d (%v)", config.CosmosValidators[0].MaxMissedBlocks, err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// alertDeduper suppresses identical alerts and recoveries seen within a
+// window, e.g. from the same item configured twice or a check that flaps
+// faster than its cooldown
+type alertDeduper struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	sent map[uint64]time.Time // When each alert hash was last let through
+}
+
+func newAlertDeduper(window time.Duration) *alertDeduper {
+	return &alertDeduper{window: window, sent: make(map[uint64]time.Time)}
+}
+
+// duplicate reports whether the incident was already let through within the
+// window, and records it otherwise
+func (d *alertDeduper) duplicate(inc *incident) bool {
+	if d.window <= 0 || inc == nil {
+		return false
+	}
+
+	// The dedup key holds the item type, group and item, the action is the status
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(inc.dedupKey + "\x00" + inc.action))
+	key := h.Sum64()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for k, t := range d.sent {
+		if now.Sub(t) >= d.window {
+			delete(d.sent, k)
+		}
+	}
+
+	if _, ok := d.sent[key]; ok {
+		slog.Info("duplicate notification suppressed", "dedup_key", inc.dedupKey, "event_action", inc.action, "dedup_window", d.window)
+		return true
+	}
+	d.sent[key] = now
+	return false
+}
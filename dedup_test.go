@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAlertDeduper checks that the same alert or recovery is only let through
+// once per window, per item and action
+func TestAlertDeduper(t *testing.T) {
+	d := newAlertDeduper(50 * time.Millisecond)
+	alert := &incident{action: pagerDutyTrigger, dedupKey: "health/group/node"}
+
+	if d.duplicate(alert) {
+		t.Fatal("expected the first alert to be let through")
+	}
+	if !d.duplicate(&incident{action: pagerDutyTrigger, dedupKey: "health/group/node"}) {
+		t.Error("expected the same alert to be suppressed within the window")
+	}
+	if d.duplicate(&incident{action: pagerDutyResolve, dedupKey: "health/group/node"}) {
+		t.Error("expected the recovery of the item to be let through")
+	}
+	if d.duplicate(&incident{action: pagerDutyTrigger, dedupKey: "health/group/other"}) {
+		t.Error("expected the alert of another item to be let through")
+	}
+	if d.duplicate(nil) {
+		t.Error("expected plain messages never to be suppressed")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if d.duplicate(alert) {
+		t.Error("expected the alert to be let through again after the window")
+	}
+
+	if off := newAlertDeduper(0); off.duplicate(alert) || off.duplicate(alert) {
+		t.Error("expected no deduplication without a window")
+	}
+}
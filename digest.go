@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// channelScope limits which channels a notification is delivered to
+type channelScope int
+
+const (
+	scopeAll       channelScope = iota // Every applicable channel
+	scopeChat                          // Only the channels that post messages, for digests
+	scopeIncidents                     // Only PagerDuty, Opsgenie and SMS, for alerts whose message is in a digest or throttled
+)
+
+// digest collects the alerts of one check cycle of a group, so they are
+// posted as one message instead of one per item
+type digest struct {
+	key      string
+	itemType string
+	group    string
+
+	mu     sync.Mutex
+	alerts []notification
+}
+
+// digestKey identifies the group of an item type a digest collects alerts for
+func digestKey(itemType, group string) string {
+	return itemType + "/" + group
+}
+
+// startDigest begins collecting the alerts of a group's check cycle. It
+// returns nil when digests are disabled.
+func (n *Notifier) startDigest(itemType, group string) *digest {
+	if !n.digest {
+		return nil
+	}
+
+	d := &digest{key: digestKey(itemType, group), itemType: itemType, group: group}
+	n.digestMu.Lock()
+	n.digests[d.key] = d
+	n.digestMu.Unlock()
+	return d
+}
+
+// activeDigest returns the digest collecting alerts of the group, if any
+func (n *Notifier) activeDigest(itemType, group string) *digest {
+	if !n.digest {
+		return nil
+	}
+
+	n.digestMu.Lock()
+	defer n.digestMu.Unlock()
+	return n.digests[digestKey(itemType, group)]
+}
+
+// flushDigest ends a check cycle and posts its alerts. A single alert is
+// posted as is, several are combined into one message with a line per alert.
+// The message counts as one against max_alerts_per_minute, its incidents
+// were already sent per item.
+func (n *Notifier) flushDigest(d *digest) {
+	if d == nil {
+		return
+	}
+
+	n.digestMu.Lock()
+	if n.digests[d.key] == d {
+		delete(n.digests, d.key)
+	}
+	n.digestMu.Unlock()
+
+	d.mu.Lock()
+	alerts := d.alerts
+	d.mu.Unlock()
+
+	var job notification
+	switch len(alerts) {
+	case 0:
+		return
+	case 1:
+		job = alerts[0]
+	default:
+		job = combineDigest(d, alerts)
+	}
+
+	if n.overLimit(job, len(alerts)) {
+		return
+	}
+	job.scope = scopeChat
+	n.enqueue(job)
+}
+
+// combineDigest builds the message of a digest of several alerts. The digest
+// takes the highest severity of its alerts, so it reaches every channel one
+// of them would have.
+func combineDigest(d *digest, alerts []notification) notification {
+	severity := severityInfo
+	lines := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		if severityRanks[alert.incident.severity] > severityRanks[severity] {
+			severity = alert.incident.severity
+		}
+		lines = append(lines, "• "+strings.SplitN(alert.message, "\n", 2)[0])
+	}
+
+	message := fmt.Sprintf("%s Alert digest: [%s] %d alerts\n%s", severityEmoji(severity), d.group, len(alerts), strings.Join(lines, "\n"))
+	return notification{
+		message:  message,
+		incident: &incident{action: pagerDutyTrigger, dedupKey: dedupKey(d.itemType, d.group, "digest"), severity: severity},
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDigest checks that a cycle's alerts are posted as one message with the
+// highest severity, while their incidents and item webhooks stay per item
+func TestDigest(t *testing.T) {
+	var sent []string
+	n := &Notifier{
+		channels: []notificationChannel{
+			recordingChannel{channelName: "chat", sent: &sent},
+			recordingChannel{channelName: "pager", incidents: true, sent: &sent},
+		},
+		queue:   make(chan notification, 10),
+		deduper: newAlertDeduper(0),
+		digest:  true,
+		digests: make(map[string]*digest),
+	}
+
+	d := n.startDigest("health", "Hub")
+	n.Alert("⚠️ Alert: [Hub] `rpc` is slow\ndetails", WebhookOverride{}, "health/Hub/rpc", severityWarning, messageData{Group: "Hub"})
+	n.Alert("🚨 Alert: [Hub] `api` is unhealthy!\ndetails", WebhookOverride{}, "health/Hub/api", severityCritical, messageData{Group: "Hub"})
+	n.Alert("🚨 Alert: [Hub] `grpc` is unhealthy!", WebhookOverride{WebhookURL: "https://hooks.test/grpc"}, "health/Hub/grpc", severityCritical, messageData{Group: "Hub"})
+
+	var scopes []channelScope
+	for len(n.queue) > 0 {
+		scopes = append(scopes, (<-n.queue).scope)
+	}
+	if expected := []channelScope{scopeIncidents, scopeIncidents, scopeAll}; !reflect.DeepEqual(scopes, expected) {
+		t.Errorf("expected the incidents and the webhook alert right away, got scopes %v", scopes)
+	}
+
+	n.flushDigest(d)
+	if len(n.queue) != 1 {
+		t.Fatalf("expected one digest, got %d notifications", len(n.queue))
+	}
+	job := <-n.queue
+	expected := "🚨 Alert digest: [Hub] 2 alerts\n• ⚠️ Alert: [Hub] `rpc` is slow\n• 🚨 Alert: [Hub] `api` is unhealthy!"
+	if job.message != expected || job.scope != scopeChat || job.incident.severity != severityCritical {
+		t.Errorf("expected a critical chat digest %q, got %q in scope %v with severity %s", expected, job.message, job.scope, job.incident.severity)
+	}
+	if n.activeDigest("health", "Hub") != nil {
+		t.Error("expected the digest to end with its cycle")
+	}
+
+	d = n.startDigest("health", "Hub")
+	n.Alert("⚠️ Alert: [Hub] `rpc` is slow\ndetails", WebhookOverride{}, "health/Hub/rpc-2", severityWarning, messageData{Group: "Hub"})
+	<-n.queue
+	n.flushDigest(d)
+	if job := <-n.queue; job.message != "⚠️ Alert: [Hub] `rpc` is slow\ndetails" || job.scope != scopeChat {
+		t.Errorf("expected a single alert to be posted as is, got %q in scope %v", job.message, job.scope)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// discordMaxLength is the maximum number of characters Discord accepts in a message
+const discordMaxLength = 2000
+
+type DiscordConfig struct {
+	WebhookURL  string `mapstructure:"webhook_url"`  // Discord channel webhook URL
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest alert severity to send
+}
+
+// sendDiscordMessage posts a message to a Discord webhook, splitting it into
+// several messages if it exceeds Discord's length limit. The Telegram code
+// spans are kept as-is since Discord renders single backticks the same way.
+func sendDiscordMessage(webhookURL, content string) error {
+	for _, part := range splitMessage(content, discordMaxLength) {
+		payload, err := json.Marshal(map[string]string{"content": part})
+		if err != nil {
+			return fmt.Errorf("error encoding payload: %w", err)
+		}
+
+		resp, err := notificationClient.Post(webhookURL, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("error making request: %w", err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		// Discord answers 204 No Content unless ?wait=true is set
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("discord returned status code %d: %s", resp.StatusCode, string(body))
+		}
+	}
+
+	return nil
+}
+
+// checkDiscordWebhook checks that a Discord webhook exists without posting to
+// its channel, Discord answers a GET of the webhook URL with its details
+func checkDiscordWebhook(webhookURL string) error {
+	resp, err := notificationClient.Get(webhookURL)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("discord returned status code %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// splitMessage splits text into chunks of at most limit characters, breaking
+// on line boundaries where possible
+func splitMessage(text string, limit int) []string {
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+	currentLen := 0
+
+	flush := func() {
+		if currentLen > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		lineLen := utf8.RuneCountInString(line)
+		if currentLen+lineLen > limit {
+			flush()
+		}
+
+		// A single line longer than the limit has to be cut mid-line
+		for lineLen > limit {
+			runes := []rune(line)
+			parts = append(parts, string(runes[:limit]))
+			line = string(runes[limit:])
+			lineLen -= limit
+		}
+
+		current.WriteString(line)
+		currentLen += lineLen
+	}
+	flush()
+
+	return parts
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestSplitMessage checks that long messages are split on line boundaries,
+// and that a single overlong line is cut
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		limit    int
+		expected []string
+	}{
+		{name: "short", text: "one\ntwo", limit: 10, expected: []string{"one\ntwo"}},
+		{name: "lines", text: "one\ntwo\nthree", limit: 8, expected: []string{"one\ntwo\n", "three"}},
+		{name: "long line", text: "abcdefghij\nk", limit: 4, expected: []string{"abcd", "efgh", "ij\nk"}},
+		{name: "multibyte", text: "ééééé", limit: 2, expected: []string{"éé", "éé", "é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := splitMessage(tt.text, tt.limit)
+			if !reflect.DeepEqual(parts, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, parts)
+			}
+		})
+	}
+}
+
+// TestDiscordMessage checks that a message over Discord's limit is posted in parts
+func TestDiscordMessage(t *testing.T) {
+	requests := captureNotifications(t, http.StatusNoContent)
+
+	message := strings.Repeat(strings.Repeat("x", 99)+"\n", 30)
+	if err := sendDiscordMessage("https://discord.test/api/webhooks/1/x", message); err != nil {
+		t.Fatalf("sendDiscordMessage: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected the message in 2 parts, got %d", len(*requests))
+	}
+
+	var content strings.Builder
+	for _, req := range *requests {
+		var payload map[string]string
+		if err := json.Unmarshal([]byte(req.body), &payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		if utf8.RuneCountInString(payload["content"]) > discordMaxLength {
+			t.Errorf("expected parts within %d characters, got %d", discordMaxLength, utf8.RuneCountInString(payload["content"]))
+		}
+		content.WriteString(payload["content"])
+	}
+	if content.String() != message {
+		t.Error("expected the parts to add up to the message")
+	}
+}
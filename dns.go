@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// dnsLookupTimeout bounds every DNS resolution check
+const dnsLookupTimeout = 10 * time.Second
+
+// DNS record types a check can resolve
+const (
+	dnsRecordA     = "A"
+	dnsRecordAAAA  = "AAAA"
+	dnsRecordCNAME = "CNAME"
+)
+
+type DNSCheckItem struct {
+	Name             string  `mapstructure:"name"`
+	Hostname         string  `mapstructure:"hostname"`          // Hostname to resolve
+	RecordType       string  `mapstructure:"record_type"`       // A (default), AAAA or CNAME
+	Expected         string  `mapstructure:"expected"`          // Optional address or canonical name the hostname must resolve to
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-check cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-check recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type DNSCheckConfig struct {
+	Name          string         `mapstructure:"name"`
+	CheckInterval Seconds        `mapstructure:"check_interval"` // Optional per-group check interval
+	Checks        []DNSCheckItem `mapstructure:"checks"`
+}
+
+// validateDNSChecks validates the DNS check groups and prepares their items
+func validateDNSChecks(config *Config) error {
+	for i, dnsGroup := range config.DNSChecks {
+		if dnsGroup.Name == "" {
+			config.DNSChecks[i].Name = fmt.Sprintf("DNS Check Group %d", i+1) // Set default name if not provided
+			dnsGroup.Name = config.DNSChecks[i].Name
+		}
+
+		for j, check := range dnsGroup.Checks {
+			item := &config.DNSChecks[i].Checks[j]
+			if check.Hostname == "" {
+				return fmt.Errorf("hostname is required for DNS check #%d in group '%s'", j+1, dnsGroup.Name)
+			}
+			if check.Name == "" {
+				item.Name = check.Hostname // Default to the hostname if no name is provided
+			}
+
+			item.RecordType = strings.ToUpper(check.RecordType)
+			switch item.RecordType {
+			case "":
+				item.RecordType = dnsRecordA
+			case dnsRecordA, dnsRecordAAAA, dnsRecordCNAME:
+			default:
+				return fmt.Errorf("invalid record_type '%s' for DNS check '%s' in group '%s': must be A, AAAA or CNAME", check.RecordType, check.Hostname, dnsGroup.Name)
+			}
+
+			if check.Expected != "" && item.RecordType != dnsRecordCNAME {
+				ip := net.ParseIP(check.Expected)
+				if ip == nil || (ip.To4() != nil) != (item.RecordType == dnsRecordA) {
+					return fmt.Errorf("invalid expected '%s' for DNS check '%s' in group '%s': must be an address of the %s record type", check.Expected, check.Hostname, dnsGroup.Name, item.RecordType)
+				}
+			}
+
+			if err := check.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("DNS check '%s' in group '%s': %w", check.Hostname, dnsGroup.Name, err)
+			}
+			if check.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for DNS check '%s' in group '%s'", check.Hostname, dnsGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// resolveDNS looks up the records of a type for a hostname. A and AAAA
+// records are read from the hostname's addresses, a CNAME is returned
+// without its trailing dot.
+func resolveDNS(ctx context.Context, hostname, recordType string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+	defer cancel()
+
+	if recordType == dnsRecordCNAME {
+		cname, err := net.DefaultResolver.LookupCNAME(ctx, hostname)
+		if err != nil {
+			return nil, err
+		}
+		return []string{strings.TrimSuffix(cname, ".")}, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []string
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip != nil && (ip.To4() != nil) == (recordType == dnsRecordA) {
+			records = append(records, addr)
+		}
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no %s records found for %s", recordType, hostname)
+	}
+	return records, nil
+}
+
+// Check resolves the hostname and, if an expected value is set, checks that
+// it's among the results. A hostname resolving to the wrong records is
+// unhealthy, a lookup that fails is an error.
+func (d *DNSCheckItem) Check(ctx context.Context) (bool, string, error) {
+	records, err := resolveDNS(ctx, d.Hostname, d.RecordType)
+	if err != nil {
+		return false, "", err
+	}
+	resolved := strings.Join(records, ", ")
+	if d.Expected == "" {
+		return true, resolved, nil
+	}
+
+	for _, record := range records {
+		if d.RecordType == dnsRecordCNAME {
+			if strings.EqualFold(record, strings.TrimSuffix(d.Expected, ".")) {
+				return true, resolved, nil
+			}
+		} else if net.ParseIP(record).Equal(net.ParseIP(d.Expected)) {
+			return true, resolved, nil
+		}
+	}
+	return false, resolved, nil
+}
+
+// checkGroup implementation for DNS check groups, run by runMonitor
+
+func (c *DNSCheckConfig) checkType() string      { return "dns" }
+func (c *DNSCheckConfig) groupName() string      { return c.Name }
+func (c *DNSCheckConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *DNSCheckConfig) itemCount() int         { return len(c.Checks) }
+
+func (c *DNSCheckConfig) item(i int) monitoredItem {
+	item := &c.Checks[i]
+	return monitoredItem{
+		Checker: item, state: &item.itemState, name: item.Name, target: item.Hostname + " (" + item.RecordType + ")",
+		threshold: item.Expected, severity: severityCritical, cooldown: item.AlertCooldown, recovery: item.RecoveryInterval,
+		override: item.WebhookOverride,
+	}
+}
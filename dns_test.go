@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// TestDNSCheck checks that a hostname is healthy when it resolves to the
+// expected address and unhealthy when it resolves elsewhere
+func TestDNSCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     DNSCheckItem
+		healthy  bool
+		hasError bool
+	}{
+		{name: "any address", item: DNSCheckItem{Hostname: "127.0.0.1", RecordType: dnsRecordA}, healthy: true},
+		{name: "expected address", item: DNSCheckItem{Hostname: "127.0.0.1", RecordType: dnsRecordA, Expected: "127.0.0.1"}, healthy: true},
+		{name: "other address", item: DNSCheckItem{Hostname: "127.0.0.1", RecordType: dnsRecordA, Expected: "10.0.0.1"}},
+		{name: "no record of the type", item: DNSCheckItem{Hostname: "127.0.0.1", RecordType: dnsRecordAAAA}, hasError: true},
+	}
+
+	for _, tt := range tests {
+		healthy, _, err := tt.item.Check(context.Background())
+		if healthy != tt.healthy || (err != nil) != tt.hasError {
+			t.Errorf("%s: expected healthy %v with error %v, got %v (%v)", tt.name, tt.healthy, tt.hasError, healthy, err)
+		}
+	}
+}
+
+// TestValidateDNSChecks checks the record type default and that expected
+// addresses match the record type
+func TestValidateDNSChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  DNSCheckItem
+		valid bool
+	}{
+		{name: "default record type", item: DNSCheckItem{Hostname: "rpc.test"}, valid: true},
+		{name: "IPv4 for A", item: DNSCheckItem{Hostname: "rpc.test", RecordType: "a", Expected: "10.0.0.1"}, valid: true},
+		{name: "IPv6 for AAAA", item: DNSCheckItem{Hostname: "rpc.test", RecordType: "AAAA", Expected: "2001:db8::1"}, valid: true},
+		{name: "name for CNAME", item: DNSCheckItem{Hostname: "rpc.test", RecordType: "CNAME", Expected: "lb.test."}, valid: true},
+		{name: "IPv6 for A", item: DNSCheckItem{Hostname: "rpc.test", Expected: "2001:db8::1"}},
+		{name: "name for A", item: DNSCheckItem{Hostname: "rpc.test", Expected: "lb.test"}},
+		{name: "unknown record type", item: DNSCheckItem{Hostname: "rpc.test", RecordType: "MX"}},
+		{name: "no hostname", item: DNSCheckItem{}},
+	}
+
+	for _, tt := range tests {
+		config := &Config{DNSChecks: []DNSCheckConfig{{Checks: []DNSCheckItem{tt.item}}}}
+		err := validateDNSChecks(config)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %v, got %v", tt.name, tt.valid, err)
+		}
+	}
+
+	config := &Config{DNSChecks: []DNSCheckConfig{{Checks: []DNSCheckItem{{Hostname: "rpc.test"}}}}}
+	if err := validateDNSChecks(config); err != nil || config.DNSChecks[0].Checks[0].RecordType != dnsRecordA {
+		t.Errorf("expected the record type to default to A, got %q (%v)", config.DNSChecks[0].Checks[0].RecordType, err)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Seconds is a config duration in whole seconds, given either as a plain
+// number of seconds like 300 or as a Go duration string like 5m or 1h30m
+type Seconds int
+
+// secondsHook decodes the duration strings of Seconds settings, leaving plain
+// numbers to the default decoding
+func secondsHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(Seconds(0)) || from.Kind() != reflect.String {
+		return data, nil
+	}
+	value := strings.TrimSpace(data.(string))
+	if _, err := strconv.Atoi(value); err == nil {
+		return value, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %q: expected seconds or a duration like 5m or 1h", value)
+	}
+	if d%time.Second != 0 {
+		return nil, fmt.Errorf("invalid duration %q: must be a whole number of seconds", value)
+	}
+	return int(d / time.Second), nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailTimeout bounds the whole SMTP exchange, including the dial
+const emailTimeout = 30 * time.Second
+
+type EmailConfig struct {
+	SMTPHost string   `mapstructure:"smtp_host"`
+	SMTPPort int      `mapstructure:"smtp_port"` // Defaults to 465 with use_tls, 587 otherwise
+	Username string   `mapstructure:"username"`  // Optional, enables SMTP authentication
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	UseTLS   bool     `mapstructure:"use_tls"` // Connect with implicit TLS instead of STARTTLS
+
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest alert severity to send
+}
+
+// validate checks the email config and applies defaults
+func (e *EmailConfig) validate() error {
+	if e.From == "" {
+		return fmt.Errorf("from is required")
+	}
+	if _, err := mail.ParseAddress(e.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %w", e.From, err)
+	}
+	if len(e.To) == 0 {
+		return fmt.Errorf("at least one to address is required")
+	}
+
+	if e.SMTPPort == 0 {
+		if e.UseTLS {
+			e.SMTPPort = 465
+		} else {
+			e.SMTPPort = 587
+		}
+	}
+
+	return nil
+}
+
+// sendEmail sends a plain-text email to every configured recipient. Recipients
+// the server rejects are logged and skipped; it only fails when no recipient
+// accepted the message.
+func (e EmailConfig) sendEmail(subject, body string) error {
+	addr := net.JoinHostPort(e.SMTPHost, strconv.Itoa(e.SMTPPort))
+	dialer := &net.Dialer{Timeout: emailTimeout}
+
+	var conn net.Conn
+	var err error
+	if e.UseTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: e.SMTPHost})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(emailTimeout)); err != nil {
+		conn.Close()
+		return fmt.Errorf("error setting deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, e.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if !e.UseTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: e.SMTPHost}); err != nil {
+				return fmt.Errorf("error starting TLS: %w", err)
+			}
+		}
+	}
+
+	if e.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.SMTPHost)); err != nil {
+			return fmt.Errorf("error authenticating: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.From); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+
+	var accepted []string
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			slog.Warn("email recipient rejected", "recipient", to, "error", err)
+			continue
+		}
+		accepted = append(accepted, to)
+	}
+	if len(accepted) == 0 {
+		return fmt.Errorf("all recipients were rejected")
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message: %w", err)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(accepted, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	if _, err := w.Write([]byte(msg.String())); err != nil {
+		return fmt.Errorf("error writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts one SMTP session, rejecting the recipients in
+// rejected, and returns the session's message data once it ends
+func fakeSMTPServer(t *testing.T, rejected string) (string, int, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 smtp.test ready")
+
+		var message strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 smtp.test")
+			case strings.HasPrefix(command, "RCPT TO:") && strings.Contains(command, strings.ToUpper(rejected)):
+				reply("550 no such user")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					message.WriteString(line)
+				}
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				data <- message.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return host, portNumber, data
+}
+
+// TestSendEmail checks that an email reaches the accepted recipients with
+// an encoded subject and CRLF line endings, skipping rejected recipients
+func TestSendEmail(t *testing.T) {
+	host, port, data := fakeSMTPServer(t, "gone@example.com")
+	email := EmailConfig{SMTPHost: host, SMTPPort: port, From: "agent@example.com", To: []string{"ops@example.com", "gone@example.com"}}
+
+	if err := email.sendEmail("🚨 Alert", "line one\nline two"); err != nil {
+		t.Fatalf("sendEmail: %v", err)
+	}
+
+	message := <-data
+	for _, expected := range []string{"To: ops@example.com\r\n", "Subject: =?utf-8?q?", "line one\r\nline two\r\n"} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected %q in the message, got:\n%s", expected, message)
+		}
+	}
+}
+
+// TestSendEmailRejected checks that an email no recipient accepted fails
+func TestSendEmailRejected(t *testing.T) {
+	host, port, _ := fakeSMTPServer(t, "gone@example.com")
+	email := EmailConfig{SMTPHost: host, SMTPPort: port, From: "agent@example.com", To: []string{"gone@example.com"}}
+
+	if err := email.sendEmail("alert", "body"); err == nil {
+		t.Error("expected an error when every recipient is rejected")
+	}
+}
+
+// TestEmailConfigValidate checks the required fields and port defaults
+func TestEmailConfigValidate(t *testing.T) {
+	tls := EmailConfig{SMTPHost: "smtp.test", From: "agent@example.com", To: []string{"ops@example.com"}, UseTLS: true}
+	if err := tls.validate(); err != nil || tls.SMTPPort != 465 {
+		t.Errorf("expected port 465 with use_tls, got %d (%v)", tls.SMTPPort, err)
+	}
+	starttls := EmailConfig{SMTPHost: "smtp.test", From: "agent@example.com", To: []string{"ops@example.com"}}
+	if err := starttls.validate(); err != nil || starttls.SMTPPort != 587 {
+		t.Errorf("expected port 587 without use_tls, got %d (%v)", starttls.SMTPPort, err)
+	}
+
+	if err := (&EmailConfig{From: "not an address", To: []string{"ops@example.com"}}).validate(); err == nil {
+		t.Error("expected an invalid from address to be rejected")
+	}
+	if err := (&EmailConfig{From: "agent@example.com"}).validate(); err == nil {
+		t.Error("expected an email config without recipients to be rejected")
+	}
+}
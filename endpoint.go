@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// hostPortFormat describes the host:port form of TCP, certificate and gRPC
+// addresses in errors, net.SplitHostPort needs IPv6 hosts in brackets
+const hostPortFormat = "expected host:port, with IPv6 hosts in brackets like [2001:db8::1]:26657"
+
+// validateEndpoint checks that an endpoint is an absolute http(s) URL. An
+// IPv6 host without brackets can't be told apart from its port, so it gets
+// a hint instead of the parser's "invalid port" error.
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	// Depending on the Go version an unbracketed IPv6 host fails to parse or
+	// is split at its last colon
+	if (err != nil && strings.Count(endpoint, ":") > 2 && !strings.Contains(endpoint, "[")) ||
+		(err == nil && strings.Contains(u.Hostname(), ":") && !strings.HasPrefix(u.Host, "[")) {
+		return fmt.Errorf("invalid endpoint %q: IPv6 hosts must be in brackets, e.g. http://[2001:db8::1]:26657", endpoint)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q: must be an absolute http(s) URL", endpoint)
+	}
+	return nil
+}
+
+// joinEndpoint appends a path, which may end with a query, to an endpoint
+// URL. The endpoint is parsed rather than concatenated, so bracketed IPv6
+// hosts, ports, a base path, a trailing slash and a query of the endpoint
+// all carry over to the request URL.
+func joinEndpoint(endpoint, path string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+
+	path, query, _ := strings.Cut(path, "?")
+	u = u.JoinPath(path)
+	if query != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += query
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJoinEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		path     string
+		expected string
+	}{
+		{name: "host", endpoint: "http://localhost:1317", path: "/addresses/kaspa:test/balance", expected: "http://localhost:1317/addresses/kaspa:test/balance"},
+		{name: "trailing slash", endpoint: "https://rest.example.com/", path: "/address/bc1test", expected: "https://rest.example.com/address/bc1test"},
+		{name: "IPv6", endpoint: "http://[2001:db8::1]:26657", path: "/cosmos/bank/v1beta1/balances/dym1test", expected: "http://[2001:db8::1]:26657/cosmos/bank/v1beta1/balances/dym1test"},
+		{name: "IPv6 zone", endpoint: "http://[fe80::1%25eth0]:1317", path: "/addresses/kaspa:test/balance", expected: "http://[fe80::1%25eth0]:1317/addresses/kaspa:test/balance"},
+		{name: "base path", endpoint: "https://gateway.example.com/dymension/rest/", path: "/cosmos/bank/v1beta1/balances/dym1test", expected: "https://gateway.example.com/dymension/rest/cosmos/bank/v1beta1/balances/dym1test"},
+		{name: "queries", endpoint: "https://gateway.example.com?key=secret", path: "/cosmos/bank/v1beta1/balances/dym1test/by_denom?denom=adym", expected: "https://gateway.example.com/cosmos/bank/v1beta1/balances/dym1test/by_denom?key=secret&denom=adym"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinEndpoint(tt.endpoint, tt.path)
+			if err != nil {
+				t.Fatalf("joinEndpoint: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  string
+	}{
+		{endpoint: "http://[2001:db8::1]:26657"},
+		{endpoint: "https://[::1]/metrics"},
+		{endpoint: "http://localhost:2112/metrics"},
+		{endpoint: "http://2001:db8::1:26657", wantErr: "in brackets"},
+		{endpoint: "2001:db8::1", wantErr: "in brackets"},
+		{endpoint: "localhost:1317", wantErr: "absolute http(s) URL"},
+		{endpoint: "node.test:26657", wantErr: "absolute http(s) URL"},
+	}
+
+	for _, tt := range tests {
+		err := validateEndpoint(tt.endpoint)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("expected %s to be valid, got %v", tt.endpoint, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("expected %s to be rejected with %q, got %v", tt.endpoint, tt.wantErr, err)
+		}
+	}
+}
+
+// TestLoadConfigEndpoints checks that the endpoints of every item type that
+// requests a URL as configured are validated when the config is loaded
+func TestLoadConfigEndpoints(t *testing.T) {
+	sections := map[string]string{
+		"health":           "health:\n  - endpoints:\n      - name: rpc\n        endpoint: %q\n",
+		"kaspa_validators": "kaspa_validators:\n  - validators:\n      - endpoint: %q\n",
+		"block_height":     "block_height:\n  - endpoints:\n      - endpoint: %q\n",
+		"sync_checks":      "sync_checks:\n  - endpoints:\n      - endpoint: %q\n",
+		"peer_checks":      "peer_checks:\n  - min_peers: 5\n    endpoints:\n      - endpoint: %q\n",
+	}
+	endpoints := map[string]bool{"http://[2001:db8::1]:26657/status": true, "http://2001:db8::1:26657/status": false, "node.test:26657": false}
+
+	for section, config := range sections {
+		for endpoint, valid := range endpoints {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(fmt.Sprintf(config, endpoint)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := loadConfig(path, ""); (err == nil) != valid {
+				t.Errorf("%s endpoint %q: expected valid %v, got %v", section, endpoint, valid, err)
+			}
+		}
+	}
+}
+
+// listenIPv6 starts a listener on the IPv6 loopback, skipping the test if the host has none
+func listenIPv6(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	return listener
+}
+
+// TestIPv6Endpoints checks balance, metric and TCP checks against an
+// endpoint on the IPv6 loopback
+func TestIPv6Endpoints(t *testing.T) {
+	noRetries(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/bank/v1beta1/balances/dym1test":
+			_, _ = w.Write([]byte(`{"balances":[{"denom":"adym","amount":"5"}]}`))
+		case "/addresses/kaspa:test/balance":
+			_, _ = w.Write([]byte(`{"address":"kaspa:test","balance":5}`))
+		case "/metrics":
+			_, _ = w.Write([]byte("peers 12\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	server.Listener = listenIPv6(t)
+	server.Start()
+	defer server.Close()
+
+	if !strings.HasPrefix(server.URL, "http://[::1]:") {
+		t.Fatalf("expected an IPv6 server URL, got %s", server.URL)
+	}
+	if err := validateEndpoint(server.URL); err != nil {
+		t.Fatalf("validateEndpoint: %v", err)
+	}
+
+	balances, err := getBalance(context.Background(), server.URL, "dym1test", "", RequestOptions{})
+	if err != nil || len(balances.Balances) != 1 {
+		t.Errorf("getBalance: %+v, %v", balances, err)
+	}
+	kaspa, err := getKaspaBalance(context.Background(), server.URL+"/", "kaspa:test", RequestOptions{})
+	if err != nil || kaspa.Balance != 5 {
+		t.Errorf("getKaspaBalance: %+v, %v", kaspa, err)
+	}
+	value, err := getMetricValue(context.Background(), server.URL+"/metrics", RequestMethod{}, "peers", nil, RequestOptions{})
+	if err != nil || value != 12 {
+		t.Errorf("getMetricValue: %v, %v", value, err)
+	}
+	if err := checkTCP(context.Background(), strings.TrimPrefix(server.URL, "http://"), time.Second); err != nil {
+		t.Errorf("checkTCP: %v", err)
+	}
+}
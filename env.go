@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/spf13/viper"
+)
+
+// envVarPattern matches ${VAR} references in config values. Bare $VAR is left
+// alone so values like the regular expression "^$" keep working.
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvVars replaces ${VAR} references in every string value of the
+// config that was read with the value of the environment variable
+func expandEnvVars() error {
+	settings, err := expandEnvValue(viper.AllSettings())
+	if err != nil {
+		return err
+	}
+	return viper.MergeConfigMap(settings.(map[string]interface{}))
+}
+
+// expandEnvValue expands the strings in a config value, recursing into maps and lists
+func expandEnvValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return expandEnvString(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			expanded, err := expandEnvValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			v[key] = expanded
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			expanded, err := expandEnvValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			v[i] = expanded
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// expandEnvString expands the ${VAR} references in s, an unset variable is an
+// error so a missing secret doesn't silently disable a channel
+func expandEnvString(s string) (string, error) {
+	var missing string
+	expanded := envVarPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envVarPattern.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExpandEnvString checks ${VAR} expansion, with bare $ left alone and an
+// unset variable rejected
+func TestExpandEnvString(t *testing.T) {
+	t.Setenv("ALERT_AGENT_TOKEN", "secret")
+
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "${ALERT_AGENT_TOKEN}", expected: "secret"},
+		{value: "Bearer ${ALERT_AGENT_TOKEN}!", expected: "Bearer secret!"},
+		{value: "^$", expected: "^$"},
+		{value: "$ALERT_AGENT_TOKEN", expected: "$ALERT_AGENT_TOKEN"},
+		{value: "${ALERT_AGENT_UNSET}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := expandEnvString(tt.value)
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "ALERT_AGENT_UNSET") {
+				t.Errorf("%q: expected an error naming the unset variable, got %v", tt.value, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("%q: expected %q, got %q (%v)", tt.value, tt.expected, got, err)
+		}
+	}
+}
+
+// TestLoadConfigEnvVars checks that nested config values are expanded
+func TestLoadConfigEnvVars(t *testing.T) {
+	t.Setenv("ALERT_AGENT_SLACK_WEBHOOK", "https://hooks.slack.test/services/x")
+	t.Setenv("ALERT_AGENT_NODE", "http://node.test")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := `slack:
+  webhook_url: "${ALERT_AGENT_SLACK_WEBHOOK}"
+health:
+  - name: "group"
+    endpoints:
+      - endpoint: "${ALERT_AGENT_NODE}/health"
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadConfig(path, "")
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if loaded.Slack.WebhookURL != "https://hooks.slack.test/services/x" {
+		t.Errorf("expected the expanded Slack webhook, got %q", loaded.Slack.WebhookURL)
+	}
+	if endpoint := loaded.Health[0].Endpoints[0].Endpoint; endpoint != "http://node.test/health" {
+		t.Errorf("expected the expanded endpoint, got %q", endpoint)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// evmAddressPattern matches a 0x-prefixed 20 byte hex address
+var evmAddressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// evmDefaultDecimals is used when display_denom is set without decimals
+const evmDefaultDecimals = 18
+
+type EVMAddressItem struct {
+	Name             string  `mapstructure:"name"`
+	Address          string  `mapstructure:"address"`           // 0x-prefixed account address
+	Threshold        string  `mapstructure:"threshold"`         // Threshold amount in wei
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-address cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-address recovery check interval in seconds
+	Decimals         int     `mapstructure:"decimals"`          // Optional display decimals (default: 18 with display_denom)
+	DisplayDenom     string  `mapstructure:"display_denom"`     // Optional display denom, e.g. ETH
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type EVMAddressConfig struct {
+	Name          string           `mapstructure:"name"`
+	RPCEndpoint   string           `mapstructure:"rpc_endpoint"`   // JSON-RPC endpoint of the chain
+	CheckInterval Seconds          `mapstructure:"check_interval"` // Optional per-group check interval
+	ExplorerURL   string           `mapstructure:"explorer_url"`   // Optional explorer address page, e.g. https://etherscan.io/address/{address}
+	Addresses     []EVMAddressItem `mapstructure:"addresses"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// validateEVMAddresses validates the EVM address groups and prepares their items
+func validateEVMAddresses(config *Config) error {
+	for i, evmGroup := range config.EVMAddresses {
+		if evmGroup.RPCEndpoint == "" {
+			return fmt.Errorf("RPC endpoint is required for EVM address group #%d", i+1)
+		}
+		if evmGroup.Name == "" {
+			config.EVMAddresses[i].Name = fmt.Sprintf("EVM Address Group %d", i+1) // Set default name if not provided
+			evmGroup.Name = config.EVMAddresses[i].Name
+		}
+		if err := evmGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("EVM address group '%s': %w", config.EVMAddresses[i].Name, err)
+		}
+		if err := validateExplorerURL(evmGroup.ExplorerURL); err != nil {
+			return fmt.Errorf("EVM address group '%s': %w", config.EVMAddresses[i].Name, err)
+		}
+
+		for j, addr := range evmGroup.Addresses {
+			item := &config.EVMAddresses[i].Addresses[j]
+			if !evmAddressPattern.MatchString(addr.Address) {
+				return fmt.Errorf("invalid address '%s' for EVM address item #%d in group '%s': must be 0x followed by 40 hex characters", addr.Address, j+1, evmGroup.Name)
+			}
+			if _, ok := new(big.Int).SetString(addr.Threshold, 10); !ok {
+				return fmt.Errorf("threshold must be an amount in wei for EVM address '%s' in group '%s'", addr.Address, evmGroup.Name)
+			}
+			if addr.Decimals < 0 {
+				return fmt.Errorf("decimals must not be negative for EVM address '%s' in group '%s'", addr.Address, evmGroup.Name)
+			}
+			if addr.Decimals == 0 && addr.DisplayDenom != "" {
+				item.Decimals = evmDefaultDecimals
+			}
+			if addr.Name == "" {
+				item.Name = fmt.Sprintf("EVM Wallet %d", j+1) // Set default name if not provided
+			}
+			if err := addr.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("EVM address '%s' in group '%s': %w", addr.Address, evmGroup.Name, err)
+			}
+			if addr.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for EVM address '%s' in group '%s'", addr.Address, evmGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// format renders a wei amount for messages
+func (e *EVMAddressItem) format(raw string) string {
+	return formatBalance(raw, "wei", e.Decimals, e.DisplayDenom)
+}
+
+// getEVMBalance fetches the latest balance of an address in wei with eth_getBalance
+func getEVMBalance(ctx context.Context, rpcEndpoint, address string, opts RequestOptions) (*big.Int, error) {
+	payload, err := json.Marshal(jsonRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_getBalance",
+		Params:  []interface{}{address, "latest"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	opts.apply(req)
+
+	resp, err := doRequestWithRetry(opts, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RPC returned %w", unexpectedStatus(resp))
+	}
+
+	var rpcResp jsonRPCResponse
+	if err := json.Unmarshal(resp.Body, &rpcResp); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+
+	var quantity string
+	if err := json.Unmarshal(rpcResp.Result, &quantity); err != nil {
+		return nil, fmt.Errorf("error parsing result: %w", err)
+	}
+
+	return parseHexQuantity(quantity)
+}
+
+// parseHexQuantity parses a 0x-prefixed JSON-RPC quantity such as "0x1bc16d674ec80000"
+func parseHexQuantity(quantity string) (*big.Int, error) {
+	digits, ok := strings.CutPrefix(quantity, "0x")
+	if !ok {
+		digits, ok = strings.CutPrefix(quantity, "0X")
+	}
+	if !ok || digits == "" {
+		return nil, fmt.Errorf("invalid quantity %q: must be 0x-prefixed hex", quantity)
+	}
+
+	value, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q: must be 0x-prefixed hex", quantity)
+	}
+	return value, nil
+}
+
+// evmAddressCheck checks an EVM balance with its group's endpoint
+type evmAddressCheck struct {
+	group *EVMAddressConfig
+	item  *EVMAddressItem
+}
+
+// Check fetches the balance and reports it unhealthy below the threshold
+func (c evmAddressCheck) Check(ctx context.Context) (bool, string, error) {
+	currentAmount, err := getEVMBalance(ctx, c.group.RPCEndpoint, c.item.Address, c.group.RequestOptions)
+	if err != nil {
+		return false, "", fmt.Errorf("error checking %s: %w", c.item.Name, err)
+	}
+
+	thresholdAmount, ok := new(big.Int).SetString(c.item.Threshold, 10)
+	if !ok {
+		return false, "", fmt.Errorf("invalid threshold amount for %s: %s", c.item.Name, c.item.Threshold)
+	}
+
+	recordBalance("evm", c.group.Name, c.item.Name, c.item.Address, "wei", currentAmount, thresholdAmount)
+	return currentAmount.Cmp(thresholdAmount) >= 0, c.item.format(currentAmount.String()), nil
+}
+
+// checkGroup implementation for EVM address groups, run by runMonitor
+
+func (c *EVMAddressConfig) checkType() string      { return "evm_balance" }
+func (c *EVMAddressConfig) groupName() string      { return c.Name }
+func (c *EVMAddressConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *EVMAddressConfig) itemCount() int         { return len(c.Addresses) }
+
+func (c *EVMAddressConfig) item(i int) monitoredItem {
+	item := &c.Addresses[i]
+	return monitoredItem{
+		Checker: evmAddressCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Address,
+		threshold: "below " + item.format(item.Threshold), link: explorerLine(c.ExplorerURL, item.Address),
+		keyParts: []string{item.Address}, severity: severityWarning,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestGetEVMBalance checks the eth_getBalance request and the parsing of its
+// hex result and of RPC errors
+func TestGetEVMBalance(t *testing.T) {
+	noRetries(t)
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr string
+	}{
+		{name: "balance", body: `{"jsonrpc":"2.0","id":1,"result":"0x1bc16d674ec80000"}`, want: "2000000000000000000"},
+		{name: "zero", body: `{"jsonrpc":"2.0","id":1,"result":"0x0"}`, want: "0"},
+		{name: "rpc error", body: `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid address"}}`, wantErr: "RPC error -32602: invalid address"},
+		{name: "not hex", body: `{"jsonrpc":"2.0","id":1,"result":"1000"}`, wantErr: "must be 0x-prefixed hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request jsonRPCRequest
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				if err := json.Unmarshal(body, &request); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				return respond(http.StatusOK, tt.body)(req)
+			})
+
+			balance, err := getEVMBalance(context.Background(), "http://rpc.test", "0xabc", RequestOptions{httpClient: client})
+			if request.Method != "eth_getBalance" || len(request.Params) != 2 || request.Params[0] != "0xabc" || request.Params[1] != "latest" {
+				t.Errorf("unexpected request %+v", request)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getEVMBalance: %v", err)
+			}
+			if balance.String() != tt.want {
+				t.Errorf("expected %s wei, got %s", tt.want, balance)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// explorerAddressPlaceholder is replaced with the address in explorer_url
+const explorerAddressPlaceholder = "{address}"
+
+// validateExplorerURL checks that an explorer URL template is an absolute
+// http(s) URL with an {address} placeholder
+func validateExplorerURL(explorerURL string) error {
+	if explorerURL == "" {
+		return nil
+	}
+	u, err := url.Parse(strings.ReplaceAll(explorerURL, explorerAddressPlaceholder, "address"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid explorer_url %q: must be an absolute http(s) URL", explorerURL)
+	}
+	if !strings.Contains(explorerURL, explorerAddressPlaceholder) {
+		return fmt.Errorf("invalid explorer_url %q: must contain %s", explorerURL, explorerAddressPlaceholder)
+	}
+	return nil
+}
+
+// explorerLine returns a message line linking to the address on the group's
+// block explorer, or an empty string if the group has no explorer_url
+func explorerLine(explorerURL, address string) string {
+	if explorerURL == "" {
+		return ""
+	}
+	link := strings.ReplaceAll(explorerURL, explorerAddressPlaceholder, url.PathEscape(address))
+	return fmt.Sprintf("\n[View on explorer](%s)", link)
+}
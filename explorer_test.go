@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+// TestExplorerLine checks that the address is escaped into the explorer URL
+// and that plain text channels get the link written out
+func TestExplorerLine(t *testing.T) {
+	if got := explorerLine("", "dym1abc"); got != "" {
+		t.Errorf("expected no line without an explorer_url, got %q", got)
+	}
+
+	line := explorerLine("https://explorer.test/address/{address}", "dym1abc/x")
+	if expected := "\n[View on explorer](https://explorer.test/address/dym1abc%2Fx)"; line != expected {
+		t.Errorf("expected %q, got %q", expected, line)
+	}
+	if got := plainText("Balance is low" + line); got != "Balance is low\nView on explorer: https://explorer.test/address/dym1abc%2Fx" {
+		t.Errorf("expected the link written out as text, got %q", got)
+	}
+}
+
+// TestValidateExplorerURL checks that explorer URLs are absolute and contain the placeholder
+func TestValidateExplorerURL(t *testing.T) {
+	for explorerURL, valid := range map[string]bool{
+		"":                                   true,
+		"https://explorer.test/{address}":    true,
+		"http://explorer.test/a?q={address}": true,
+		"https://explorer.test/address":      false,
+		"explorer.test/{address}":            false,
+		"ftp://explorer.test/{address}":      false,
+	} {
+		if err := validateExplorerURL(explorerURL); (err == nil) != valid {
+			t.Errorf("%q: expected valid %v, got %v", explorerURL, valid, err)
+		}
+	}
+}
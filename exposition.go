@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// promSample is one series of a Prometheus text exposition
+type promSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// findMetricValue returns the value of the only series named metricName whose
+// labels include every label of the selector. Comment lines and series of
+// other metrics are skipped without being fully parsed.
+func findMetricValue(body, metricName string, selector map[string]string) (float64, error) {
+	var matches []promSample
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if sampleName(line) != metricName {
+			continue
+		}
+
+		sample, err := parseSample(line)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing metric %s: %w", metricName, err)
+		}
+		if matchesSelector(sample.Labels, selector) {
+			matches = append(matches, sample)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("%w: %s", errMetricNotFound, seriesString(metricName, selector))
+	case 1:
+		return matches[0].Value, nil
+	default:
+		series := make([]string, 0, len(matches))
+		for _, match := range matches {
+			series = append(series, seriesString(match.Name, match.Labels))
+		}
+		return 0, fmt.Errorf("%d series match metric %s, add labels to select one: %s",
+			len(matches), seriesString(metricName, selector), strings.Join(series, ", "))
+	}
+}
+
+// sampleName returns the metric name at the start of a sample line
+func sampleName(line string) string {
+	end := strings.IndexAny(line, "{ \t")
+	if end < 0 {
+		return line
+	}
+	return line[:end]
+}
+
+// parseSample parses a `name{label="value",...} value [timestamp]` line
+func parseSample(line string) (promSample, error) {
+	sample := promSample{Name: sampleName(line), Labels: make(map[string]string)}
+	rest := line[len(sample.Name):]
+
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		rest, err = parseLabels(rest[1:], sample.Labels)
+		if err != nil {
+			return sample, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("missing value in %q", line)
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("invalid value in %q: %w", line, err)
+	}
+	sample.Value = value
+
+	return sample, nil
+}
+
+// parseLabels parses the label pairs following the opening brace into labels
+// and returns the remainder of the line after the closing brace
+func parseLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if strings.HasPrefix(s, "}") {
+			return s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return "", fmt.Errorf("malformed labels")
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		if !strings.HasPrefix(s, `"`) {
+			return "", fmt.Errorf("label %s value is not quoted", name)
+		}
+
+		var value strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("label %s value is not terminated", name)
+		}
+		labels[name] = value.String()
+
+		s = strings.TrimLeft(s[i+1:], " \t")
+		s = strings.TrimPrefix(s, ",")
+	}
+}
+
+// matchesSelector reports whether labels contain every label of the selector
+func matchesSelector(labels, selector map[string]string) bool {
+	for name, value := range selector {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// seriesString formats a metric and its labels as name{label="value",...}
+func seriesString(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(labels)*2)
+	for _, label := range names {
+		pairs = append(pairs, label, labels[label])
+	}
+	return name + renderLabels(pairs)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestFindMetricValue checks parsing of the exposition format, including
+// escaped label values, timestamps, prefixed metric names and ambiguous selectors
+func TestFindMetricValue(t *testing.T) {
+	const body = `# HELP requests_total Requests served
+# TYPE requests_total counter
+requests_total{path="/a",code="200"} 10 1700000000000
+requests_total{path="/a",code="500"} 2
+requests_total{ path = "/b\"quoted\"" , code="200" , } 7
+requests_total_created{path="/a"} 1.7e9
+up 1
+weird{path="/\\back\nslash"} 5
+`
+
+	tests := []struct {
+		name     string
+		metric   string
+		selector map[string]string
+		want     float64
+		wantErr  string
+	}{
+		{name: "labels and timestamp", metric: "requests_total", selector: map[string]string{"path": "/a", "code": "200"}, want: 10},
+		{name: "escaped quotes and spacing", metric: "requests_total", selector: map[string]string{"path": `/b"quoted"`}, want: 7},
+		{name: "no labels", metric: "up", want: 1},
+		{name: "escaped backslash and newline", metric: "weird", selector: map[string]string{"path": "/\\back\nslash"}, want: 5},
+		{name: "prefix of another metric", metric: "requests_total_created", want: 1.7e9},
+		{name: "ambiguous", metric: "requests_total", selector: map[string]string{"code": "200"}, wantErr: "2 series match"},
+		{name: "not found", metric: "requests_total", selector: map[string]string{"code": "404"}, wantErr: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findMetricValue(body, tt.metric, tt.selector)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findMetricValue: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %g, got %g", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := findMetricValue(body, "missing", nil); !errors.Is(err, errMetricNotFound) {
+		t.Errorf("expected errMetricNotFound, got %v", err)
+	}
+	if _, err := findMetricValue(`broken{path="/a} 1`, "broken", nil); err == nil {
+		t.Error("expected an unterminated label value to be rejected")
+	}
+}
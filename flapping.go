@@ -0,0 +1,60 @@
+package main
+
+import "fmt"
+
+// Hysteresis damps flapping by requiring a condition to hold for several
+// consecutive checks before alerting and before declaring recovery
+type Hysteresis struct {
+	TriggerAfter int `mapstructure:"trigger_after"` // Consecutive breaching checks before alerting (default: 1)
+	RecoverAfter int `mapstructure:"recover_after"` // Consecutive healthy checks before recovering (default: 1)
+
+	breaches   int // Consecutive breaching checks
+	recoveries int // Consecutive healthy recovery checks
+}
+
+// validate checks the counts and applies the defaults
+func (h *Hysteresis) validate() error {
+	if h.TriggerAfter < 0 {
+		return fmt.Errorf("trigger_after must not be negative")
+	}
+	if h.RecoverAfter < 0 {
+		return fmt.Errorf("recover_after must not be negative")
+	}
+	if h.TriggerAfter == 0 {
+		h.TriggerAfter = 1
+	}
+	if h.RecoverAfter == 0 {
+		h.RecoverAfter = 1
+	}
+	return nil
+}
+
+// observe records the result of a regular check and reports whether the
+// condition has breached for trigger_after consecutive checks.
+// The caller must hold the item's recoveryMonitorMu.
+func (h *Hysteresis) observe(breaching bool) bool {
+	if !breaching {
+		h.breaches = 0
+		return false
+	}
+	h.breaches++
+	h.recoveries = 0
+	return h.breaches >= h.TriggerAfter
+}
+
+// recovered records the result of a recovery check and reports whether the
+// item has been healthy for recover_after consecutive checks.
+// The caller must hold the item's recoveryMonitorMu.
+func (h *Hysteresis) recovered(healthy bool) bool {
+	if !healthy {
+		h.recoveries = 0
+		return false
+	}
+	h.recoveries++
+	if h.recoveries < h.RecoverAfter {
+		return false
+	}
+	h.breaches = 0
+	h.recoveries = 0
+	return true
+}
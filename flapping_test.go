@@ -0,0 +1,50 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestHysteresis checks that alerting and recovering each need their
+// number of consecutive checks, and that an interruption resets the count
+func TestHysteresis(t *testing.T) {
+	h := &Hysteresis{TriggerAfter: 3, RecoverAfter: 2}
+	if err := h.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	var alerts []bool
+	for _, breaching := range []bool{true, true, false, true, true, true} {
+		alerts = append(alerts, h.observe(breaching))
+	}
+	if expected := []bool{false, false, false, false, false, true}; !slices.Equal(alerts, expected) {
+		t.Errorf("expected alerts %v, got %v", expected, alerts)
+	}
+
+	var recoveries []bool
+	for _, healthy := range []bool{true, false, true, true} {
+		recoveries = append(recoveries, h.recovered(healthy))
+	}
+	if expected := []bool{false, false, false, true}; !slices.Equal(recoveries, expected) {
+		t.Errorf("expected recoveries %v, got %v", expected, recoveries)
+	}
+
+	// Recovering resets the breaches
+	if h.observe(true) {
+		t.Error("expected a single breach after recovering not to alert")
+	}
+}
+
+// TestHysteresisDefaults checks that unset counts default to a single check
+func TestHysteresisDefaults(t *testing.T) {
+	h := &Hysteresis{}
+	if err := h.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !h.observe(true) || !h.recovered(true) {
+		t.Error("expected a single check to alert and recover by default")
+	}
+	if err := (&Hysteresis{TriggerAfter: -1}).validate(); err == nil {
+		t.Error("expected a negative trigger_after to be rejected")
+	}
+}
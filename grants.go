@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Grant types supported by grant checks
+const (
+	grantTypeFeegrant = "feegrant"
+	grantTypeAuthz    = "authz"
+)
+
+type GrantItem struct {
+	Name             string  `mapstructure:"name"`
+	Type             string  `mapstructure:"type"`              // feegrant or authz
+	Grantee          string  `mapstructure:"grantee"`           // Account relying on the grant
+	Granter          string  `mapstructure:"granter"`           // Optional: only consider grants from this granter
+	MsgTypeURL       string  `mapstructure:"msg_type_url"`      // Optional: authz message type, e.g. /ibc.core.client.v1.MsgUpdateClient
+	WarnDays         int     `mapstructure:"warn_days"`         // Optional per-grant override of the group warn_days
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-grant cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-grant recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type GrantConfig struct {
+	Name          string      `mapstructure:"name"`
+	RESTEndpoint  string      `mapstructure:"rest_endpoint"`
+	CheckInterval Seconds     `mapstructure:"check_interval"` // Optional per-group check interval
+	WarnDays      int         `mapstructure:"warn_days"`      // Alert when a grant expires within this many days
+	Grants        []GrantItem `mapstructure:"grants"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+type FeegrantAllowancesResponse struct {
+	Allowances []struct {
+		Granter   string                 `json:"granter"`
+		Grantee   string                 `json:"grantee"`
+		Allowance map[string]interface{} `json:"allowance"`
+	} `json:"allowances"`
+}
+
+type AuthzGrantsResponse struct {
+	Grants []struct {
+		Granter       string                 `json:"granter"`
+		Grantee       string                 `json:"grantee"`
+		Authorization map[string]interface{} `json:"authorization"`
+		Expiration    *string                `json:"expiration"`
+	} `json:"grants"`
+}
+
+// validateGrantChecks validates the grant check groups and prepares their items
+func validateGrantChecks(config *Config) error {
+	for i, grantGroup := range config.GrantChecks {
+		if grantGroup.RESTEndpoint == "" {
+			return fmt.Errorf("REST endpoint is required for grant check group #%d", i+1)
+		}
+		if err := validateEndpoint(grantGroup.RESTEndpoint); err != nil {
+			return fmt.Errorf("grant check group #%d: %w", i+1, err)
+		}
+		if grantGroup.Name == "" {
+			config.GrantChecks[i].Name = fmt.Sprintf("Grant Check Group %d", i+1) // Set default name if not provided
+			grantGroup.Name = config.GrantChecks[i].Name
+		}
+		if err := grantGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("grant check group '%s': %w", config.GrantChecks[i].Name, err)
+		}
+		if grantGroup.WarnDays == 0 {
+			config.GrantChecks[i].WarnDays = 7 // Default to 7 days if not specified
+		}
+
+		for j, grant := range grantGroup.Grants {
+			item := &config.GrantChecks[i].Grants[j]
+			if grant.Grantee == "" {
+				return fmt.Errorf("grantee is required for grant item #%d in group '%s'", j+1, grantGroup.Name)
+			}
+			if grant.Type != grantTypeFeegrant && grant.Type != grantTypeAuthz {
+				return fmt.Errorf("type must be %s or %s for grant '%s' in group '%s'", grantTypeFeegrant, grantTypeAuthz, grant.Grantee, grantGroup.Name)
+			}
+			if grant.MsgTypeURL != "" && grant.Type != grantTypeAuthz {
+				return fmt.Errorf("msg_type_url is only supported for authz grants, grant '%s' in group '%s'", grant.Grantee, grantGroup.Name)
+			}
+			if grant.WarnDays < 0 {
+				return fmt.Errorf("warn_days must not be negative for grant '%s' in group '%s'", grant.Grantee, grantGroup.Name)
+			}
+			if grant.Name == "" {
+				item.Name = fmt.Sprintf("Grant %d", j+1) // Set default name if not provided
+			}
+			if err := grant.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("grant '%s' in group '%s': %w", grant.Grantee, grantGroup.Name, err)
+			}
+			if grant.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for grant '%s' in group '%s'", grant.Grantee, grantGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// getGrantExpiration looks up the grants matching the item and returns whether
+// one exists and the latest expiration among them (nil if one never expires)
+func getGrantExpiration(ctx context.Context, restEndpoint string, item *GrantItem, opts RequestOptions) (bool, *time.Time, error) {
+	grantPath := "/cosmos/authz/v1beta1/grants/grantee/" + item.Grantee
+	if item.Type == grantTypeFeegrant {
+		grantPath = "/cosmos/feegrant/v1beta1/allowances/" + item.Grantee
+	}
+	grantURL, err := joinEndpoint(restEndpoint, grantPath)
+	if err != nil {
+		return false, nil, err
+	}
+
+	resp, err := httpGet(ctx, grantURL, opts)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, nil, fmt.Errorf("API returned %w", unexpectedStatus(resp))
+	}
+
+	var expirations []*time.Time
+	if item.Type == grantTypeFeegrant {
+		var allowances FeegrantAllowancesResponse
+		if err := json.Unmarshal(resp.Body, &allowances); err != nil {
+			return false, nil, fmt.Errorf("error parsing response: %w", err)
+		}
+		for _, allowance := range allowances.Allowances {
+			if item.Granter != "" && allowance.Granter != item.Granter {
+				continue
+			}
+			expiration, err := parseGrantExpiration(findAllowanceExpiration(allowance.Allowance))
+			if err != nil {
+				return false, nil, err
+			}
+			expirations = append(expirations, expiration)
+		}
+	} else {
+		var grants AuthzGrantsResponse
+		if err := json.Unmarshal(resp.Body, &grants); err != nil {
+			return false, nil, fmt.Errorf("error parsing response: %w", err)
+		}
+		for _, grant := range grants.Grants {
+			if item.Granter != "" && grant.Granter != item.Granter {
+				continue
+			}
+			if item.MsgTypeURL != "" && grant.Authorization["msg"] != item.MsgTypeURL {
+				continue
+			}
+			expiration, err := parseGrantExpiration(grant.Expiration)
+			if err != nil {
+				return false, nil, err
+			}
+			expirations = append(expirations, expiration)
+		}
+	}
+
+	if len(expirations) == 0 {
+		return false, nil, nil
+	}
+
+	// The grant that lasts the longest decides whether the grantee is covered
+	var latest *time.Time
+	for i, expiration := range expirations {
+		if expiration == nil {
+			return true, nil, nil
+		}
+		if i == 0 || expiration.After(*latest) {
+			latest = expiration
+		}
+	}
+	return true, latest, nil
+}
+
+// findAllowanceExpiration finds the expiration of a feegrant allowance, which
+// sits in the basic allowance of periodic and allowed-msg allowances
+func findAllowanceExpiration(allowance map[string]interface{}) *string {
+	if expiration, ok := allowance["expiration"].(string); ok {
+		return &expiration
+	}
+	for _, nested := range []string{"basic", "allowance"} {
+		if inner, ok := allowance[nested].(map[string]interface{}); ok {
+			if expiration := findAllowanceExpiration(inner); expiration != nil {
+				return expiration
+			}
+		}
+	}
+	return nil
+}
+
+// parseGrantExpiration parses an optional RFC 3339 expiration timestamp
+func parseGrantExpiration(expiration *string) (*time.Time, error) {
+	if expiration == nil || *expiration == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, *expiration)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing grant expiration %q: %w", *expiration, err)
+	}
+	return &t, nil
+}
+
+// grantProblem describes why a grant needs attention, or returns an empty string if it's fine
+func grantProblem(found bool, expiration *time.Time, warnDays int) string {
+	if !found {
+		return "is missing"
+	}
+	if expiration == nil {
+		return ""
+	}
+
+	remaining := time.Until(*expiration)
+	if remaining <= 0 {
+		return fmt.Sprintf("expired on %s", expiration.UTC().Format(time.RFC3339))
+	}
+	if remaining < time.Duration(warnDays)*24*time.Hour {
+		return fmt.Sprintf("expires in %.1f days (%s)", remaining.Hours()/24, expiration.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+// warnDays returns the item's warn_days, falling back to the group setting
+func (g *GrantItem) warnDays(grantConfig *GrantConfig) int {
+	if g.WarnDays > 0 {
+		return g.WarnDays
+	}
+	return grantConfig.WarnDays
+}
+
+// describeExpiration formats a grant expiration for messages
+func describeExpiration(expiration *time.Time) string {
+	if expiration == nil {
+		return "never"
+	}
+	return expiration.UTC().Format(time.RFC3339)
+}
+
+// grantCheck checks a grant against its group's endpoint and warn_days
+type grantCheck struct {
+	group *GrantConfig
+	item  *GrantItem
+}
+
+// Check looks up the grant and reports it unhealthy when it is missing or
+// expires within its warn_days
+func (c grantCheck) Check(ctx context.Context) (bool, string, error) {
+	found, expiration, err := getGrantExpiration(ctx, c.group.RESTEndpoint, c.item, c.group.RequestOptions)
+	if err != nil {
+		return false, "", fmt.Errorf("error checking %s: %w", c.item.Name, err)
+	}
+	if !found {
+		return false, "not found", nil
+	}
+	if problem := grantProblem(found, expiration, c.item.warnDays(c.group)); problem != "" {
+		return false, problem, nil
+	}
+	return true, "expires " + describeExpiration(expiration), nil
+}
+
+// checkGroup implementation for grant check groups, run by runMonitor
+
+func (c *GrantConfig) checkType() string      { return "grant" }
+func (c *GrantConfig) groupName() string      { return c.Name }
+func (c *GrantConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *GrantConfig) itemCount() int         { return len(c.Grants) }
+
+func (c *GrantConfig) item(i int) monitoredItem {
+	item := &c.Grants[i]
+	granter := item.Granter
+	if granter == "" {
+		granter = "any"
+	}
+	target := fmt.Sprintf("%s %s from %s", item.Grantee, item.Type, granter)
+	if item.MsgTypeURL != "" {
+		target += " for " + item.MsgTypeURL
+	}
+	return monitoredItem{
+		Checker: grantCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: target,
+		threshold: fmt.Sprintf("%d days", item.warnDays(c)), severity: severityWarning,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGetGrantExpiration checks grant lookups, including nested feegrant
+// allowances, granter and message filters, and grants that never expire
+func TestGetGrantExpiration(t *testing.T) {
+	noRetries(t)
+
+	const periodicAllowances = `{"allowances":[
+		{"granter":"dym1other","grantee":"dym1grantee","allowance":{"@type":"/cosmos.feegrant.v1beta1.BasicAllowance","expiration":"2030-01-01T00:00:00Z"}},
+		{"granter":"dym1granter","grantee":"dym1grantee","allowance":{"@type":"/cosmos.feegrant.v1beta1.PeriodicAllowance","basic":{"expiration":"2031-06-01T00:00:00Z"}}}
+	]}`
+	const authzGrants = `{"grants":[
+		{"granter":"dym1granter","grantee":"dym1grantee","authorization":{"msg":"/cosmos.bank.v1beta1.MsgSend"},"expiration":"2030-01-01T00:00:00Z"},
+		{"granter":"dym1granter","grantee":"dym1grantee","authorization":{"msg":"/ibc.core.client.v1.MsgUpdateClient"},"expiration":null}
+	]}`
+
+	tests := []struct {
+		name       string
+		body       string
+		item       GrantItem
+		found      bool
+		expiration string
+	}{
+		{name: "feegrant from granter", body: periodicAllowances, item: GrantItem{Type: grantTypeFeegrant, Granter: "dym1granter"}, found: true, expiration: "2031-06-01T00:00:00Z"},
+		{name: "feegrant latest expiration", body: periodicAllowances, item: GrantItem{Type: grantTypeFeegrant}, found: true, expiration: "2031-06-01T00:00:00Z"},
+		{name: "feegrant from unknown granter", body: periodicAllowances, item: GrantItem{Type: grantTypeFeegrant, Granter: "dym1unknown"}},
+		{name: "authz message", body: authzGrants, item: GrantItem{Type: grantTypeAuthz, MsgTypeURL: "/cosmos.bank.v1beta1.MsgSend"}, found: true, expiration: "2030-01-01T00:00:00Z"},
+		{name: "authz never expires", body: authzGrants, item: GrantItem{Type: grantTypeAuthz}, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				path = req.URL.Path
+				return respond(http.StatusOK, tt.body)(req)
+			})
+			tt.item.Grantee = "dym1grantee"
+
+			found, expiration, err := getGrantExpiration(context.Background(), "http://rest.test", &tt.item, RequestOptions{httpClient: client})
+			if err != nil {
+				t.Fatalf("getGrantExpiration: %v", err)
+			}
+			if !strings.HasSuffix(path, "/"+tt.item.Grantee) || strings.Contains(path, "feegrant") != (tt.item.Type == grantTypeFeegrant) {
+				t.Errorf("unexpected %s lookup path %s", tt.item.Type, path)
+			}
+			if found != tt.found {
+				t.Fatalf("expected found %v, got %v", tt.found, found)
+			}
+			var got string
+			if expiration != nil {
+				got = expiration.Format(time.RFC3339)
+			}
+			if got != tt.expiration {
+				t.Errorf("expected expiration %q, got %q", tt.expiration, got)
+			}
+		})
+	}
+}
+
+// TestGrantProblem checks when a grant needs attention
+func TestGrantProblem(t *testing.T) {
+	soon := time.Now().Add(36 * time.Hour)
+	later := time.Now().Add(30 * 24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		found      bool
+		expiration *time.Time
+		problem    string
+	}{
+		{name: "missing", problem: "is missing"},
+		{name: "never expires", found: true},
+		{name: "expires later", found: true, expiration: &later},
+		{name: "expires soon", found: true, expiration: &soon, problem: "expires in 1.5 days"},
+		{name: "expired", found: true, expiration: &past, problem: "expired on"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := grantProblem(tt.found, tt.expiration, 7)
+			if tt.problem == "" && problem != "" || !strings.HasPrefix(problem, tt.problem) {
+				t.Errorf("expected %q, got %q", tt.problem, problem)
+			}
+		})
+	}
+}
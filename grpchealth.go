@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// grpcHealthTimeout bounds connecting to the target and the health check call
+const grpcHealthTimeout = 10 * time.Second
+
+type GRPCHealthItem struct {
+	Name             string  `mapstructure:"name"`
+	Target           string  `mapstructure:"target"`            // host:port of the gRPC server
+	Service          string  `mapstructure:"service"`           // Optional service name, empty checks the server as a whole
+	UseTLS           bool    `mapstructure:"use_tls"`           // Connect with TLS instead of plaintext
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-check cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-check recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type GRPCHealthConfig struct {
+	Name          string           `mapstructure:"name"`
+	CheckInterval Seconds          `mapstructure:"check_interval"` // Optional per-group check interval
+	Checks        []GRPCHealthItem `mapstructure:"checks"`
+}
+
+// validateGRPCHealthChecks validates the gRPC health check groups and prepares their items
+func validateGRPCHealthChecks(config *Config) error {
+	for i, grpcGroup := range config.GRPCHealth {
+		if grpcGroup.Name == "" {
+			config.GRPCHealth[i].Name = fmt.Sprintf("gRPC Health Group %d", i+1) // Set default name if not provided
+			grpcGroup.Name = config.GRPCHealth[i].Name
+		}
+
+		for j, check := range grpcGroup.Checks {
+			item := &config.GRPCHealth[i].Checks[j]
+			if _, _, err := net.SplitHostPort(check.Target); err != nil {
+				return fmt.Errorf("invalid target '%s' for gRPC health check #%d in group '%s': %s", check.Target, j+1, grpcGroup.Name, hostPortFormat)
+			}
+			if check.Name == "" {
+				item.Name = check.Target // Default to the target if no name is provided
+			}
+			if err := check.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("gRPC health check '%s' in group '%s': %w", check.Target, grpcGroup.Name, err)
+			}
+			if check.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for gRPC health check '%s' in group '%s'", check.Target, grpcGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// Check calls grpc.health.v1.Health/Check on the target. A service that
+// answers with a status other than SERVING is unhealthy, a call that fails is
+// an error.
+func (g *GRPCHealthItem) Check(ctx context.Context) (bool, string, error) {
+	creds := insecure.NewCredentials()
+	if g.UseTLS {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+
+	conn, err := grpc.NewClient(g.Target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return false, "", fmt.Errorf("error creating client: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, grpcHealthTimeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: g.Service})
+	if err != nil {
+		return false, "", fmt.Errorf("health check failed: %w", err)
+	}
+	return resp.GetStatus() == healthpb.HealthCheckResponse_SERVING, resp.GetStatus().String(), nil
+}
+
+// checkGroup implementation for gRPC health check groups, run by runMonitor
+
+func (c *GRPCHealthConfig) checkType() string      { return "grpc_health" }
+func (c *GRPCHealthConfig) groupName() string      { return c.Name }
+func (c *GRPCHealthConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *GRPCHealthConfig) itemCount() int         { return len(c.Checks) }
+
+func (c *GRPCHealthConfig) item(i int) monitoredItem {
+	item := &c.Checks[i]
+	return monitoredItem{
+		Checker: item, state: &item.itemState, name: item.Name, target: item.Target,
+		severity: severityCritical, cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// TestGRPCHealthCheck checks the serving status of the server as a whole and
+// of a service, and that an unknown service is an error
+func TestGRPCHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("cosmos.Query", healthpb.HealthCheckResponse_NOT_SERVING)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	target := listener.Addr().String()
+	tests := []struct {
+		name    string
+		service string
+		healthy bool
+		status  string
+		wantErr bool
+	}{
+		{name: "server", healthy: true, status: "SERVING"},
+		{name: "service not serving", service: "cosmos.Query", status: "NOT_SERVING"},
+		{name: "unknown service", service: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &GRPCHealthItem{Name: "node", Target: target, Service: tt.service}
+			healthy, status, err := item.Check(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if healthy != tt.healthy || status != tt.status {
+				t.Errorf("expected %v with %s, got %v with %s", tt.healthy, tt.status, healthy, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type HeaderCheckItem struct {
+	Name             string  `mapstructure:"name"`
+	Endpoint         string  `mapstructure:"endpoint"`
+	Header           string  `mapstructure:"header"`            // Response header to inspect, e.g. X-Node-Synced
+	Expected         string  `mapstructure:"expected"`          // Exact value the header must have
+	ExpectedRegex    string  `mapstructure:"expected_regex"`    // Regular expression the header must match
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-check cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-check recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	expectedRegex *regexp.Regexp // Compiled ExpectedRegex
+	itemState                    // Internal tracking, not from config
+}
+
+type HeaderCheckConfig struct {
+	Name          string            `mapstructure:"name"`
+	CheckInterval Seconds           `mapstructure:"check_interval"` // Optional per-group check interval
+	Checks        []HeaderCheckItem `mapstructure:"checks"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+// validateHeaderChecks validates the header check groups and prepares their items
+func validateHeaderChecks(config *Config) error {
+	for i, headerGroup := range config.HeaderChecks {
+		if headerGroup.Name == "" {
+			config.HeaderChecks[i].Name = fmt.Sprintf("Header Check Group %d", i+1) // Set default name if not provided
+			headerGroup.Name = config.HeaderChecks[i].Name
+		}
+		if err := headerGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("header check group '%s': %w", config.HeaderChecks[i].Name, err)
+		}
+
+		for j, check := range headerGroup.Checks {
+			item := &config.HeaderChecks[i].Checks[j]
+			if check.Endpoint == "" {
+				return fmt.Errorf("endpoint is required for header check item #%d in group '%s'", j+1, headerGroup.Name)
+			}
+			if check.Header == "" {
+				return fmt.Errorf("header is required for header check '%s' in group '%s'", check.Endpoint, headerGroup.Name)
+			}
+			if (check.Expected == "") == (check.ExpectedRegex == "") {
+				return fmt.Errorf("exactly one of expected or expected_regex is required for header check '%s' in group '%s'", check.Endpoint, headerGroup.Name)
+			}
+			if check.ExpectedRegex != "" {
+				re, err := regexp.Compile(check.ExpectedRegex)
+				if err != nil {
+					return fmt.Errorf("invalid expected_regex for header check '%s' in group '%s': %w", check.Endpoint, headerGroup.Name, err)
+				}
+				item.expectedRegex = re
+			}
+			if check.Name == "" {
+				item.Name = fmt.Sprintf("Header Check %d", j+1) // Set default name if not provided
+			}
+			if err := check.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("header check '%s' in group '%s': %w", check.Endpoint, headerGroup.Name, err)
+			}
+			if check.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for header check '%s' in group '%s'", check.Endpoint, headerGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// expectation describes the value the header check is looking for
+func (h *HeaderCheckItem) expectation() string {
+	if h.expectedRegex != nil {
+		return fmt.Sprintf("matches /%s/", h.ExpectedRegex)
+	}
+	return fmt.Sprintf("= %q", h.Expected)
+}
+
+// checkHeader fetches the endpoint and reports whether the configured header
+// has the expected value, along with the actual value
+func checkHeader(ctx context.Context, item *HeaderCheckItem, opts RequestOptions) (bool, string, error) {
+	resp, err := httpGet(ctx, item.Endpoint, opts)
+	if err != nil {
+		return false, "", err
+	}
+
+	// Any status code is acceptable, services often pair the header with a 503.
+	// A missing header is compared as an empty value so "^$" can require absence.
+	actual := strings.Join(resp.Header.Values(item.Header), ", ")
+
+	if item.expectedRegex != nil {
+		return item.expectedRegex.MatchString(actual), displayHeaderValue(actual), nil
+	}
+	return actual == item.Expected, displayHeaderValue(actual), nil
+}
+
+// displayHeaderValue makes a missing header visible in messages
+func displayHeaderValue(value string) string {
+	if value == "" {
+		return "(missing)"
+	}
+	return value
+}
+
+// headerCheck checks a header with its group's request options
+type headerCheck struct {
+	group *HeaderCheckConfig
+	item  *HeaderCheckItem
+}
+
+// Check fetches the endpoint and compares the header with its expectation
+func (c headerCheck) Check(ctx context.Context) (bool, string, error) {
+	return checkHeader(ctx, c.item, c.group.RequestOptions)
+}
+
+// checkGroup implementation for header check groups, run by runMonitor
+
+func (c *HeaderCheckConfig) checkType() string      { return "header" }
+func (c *HeaderCheckConfig) groupName() string      { return c.Name }
+func (c *HeaderCheckConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *HeaderCheckConfig) itemCount() int         { return len(c.Checks) }
+
+func (c *HeaderCheckConfig) item(i int) monitoredItem {
+	item := &c.Checks[i]
+	return monitoredItem{
+		Checker: headerCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Endpoint,
+		threshold: item.Header + " " + item.expectation(), severity: severityCritical,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestCheckHeader checks header values against exact and regex expectations,
+// including a missing header
+func TestCheckHeader(t *testing.T) {
+	noRetries(t)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		item     HeaderCheckItem
+		expected bool
+		actual   string
+	}{
+		{name: "exact match", header: http.Header{"X-Node-Synced": {"true"}}, item: HeaderCheckItem{Expected: "true"}, expected: true, actual: "true"},
+		{name: "exact mismatch", header: http.Header{"X-Node-Synced": {"false"}}, item: HeaderCheckItem{Expected: "true"}, actual: "false"},
+		{name: "missing", header: http.Header{}, item: HeaderCheckItem{Expected: "true"}, actual: "(missing)"},
+		{name: "regex match", header: http.Header{"X-Node-Synced": {"TRUE"}}, item: HeaderCheckItem{expectedRegex: regexp.MustCompile("(?i)^true$")}, expected: true, actual: "TRUE"},
+		{name: "regex requires absence", header: http.Header{}, item: HeaderCheckItem{expectedRegex: regexp.MustCompile("^$")}, expected: true, actual: "(missing)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				// Services often pair the header with a 503
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: tt.header, Body: io.NopCloser(strings.NewReader(""))}, nil
+			})
+			tt.item.Endpoint = "http://node.test/status"
+			tt.item.Header = "X-Node-Synced"
+
+			ok, actual, err := checkHeader(context.Background(), &tt.item, RequestOptions{httpClient: client})
+			if err != nil {
+				t.Fatalf("checkHeader: %v", err)
+			}
+			if ok != tt.expected || actual != tt.actual {
+				t.Errorf("expected %v with %q, got %v with %q", tt.expected, tt.actual, ok, actual)
+			}
+		})
+	}
+}
+
+// TestValidateHeaderChecks checks that a header check needs exactly one expectation
+func TestValidateHeaderChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		check HeaderCheckItem
+		valid bool
+	}{
+		{name: "expected", check: HeaderCheckItem{Endpoint: "http://node.test", Header: "X-Node-Synced", Expected: "true"}, valid: true},
+		{name: "expected_regex", check: HeaderCheckItem{Endpoint: "http://node.test", Header: "X-Node-Synced", ExpectedRegex: "^true$"}, valid: true},
+		{name: "no expectation", check: HeaderCheckItem{Endpoint: "http://node.test", Header: "X-Node-Synced"}},
+		{name: "both expectations", check: HeaderCheckItem{Endpoint: "http://node.test", Header: "X-Node-Synced", Expected: "true", ExpectedRegex: "^true$"}},
+		{name: "invalid regex", check: HeaderCheckItem{Endpoint: "http://node.test", Header: "X-Node-Synced", ExpectedRegex: "("}},
+		{name: "no header", check: HeaderCheckItem{Endpoint: "http://node.test", Expected: "true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{HeaderChecks: []HeaderCheckConfig{{Checks: []HeaderCheckItem{tt.check}}}}
+			err := validateHeaderChecks(config)
+			if (err == nil) != tt.valid {
+				t.Errorf("expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultHeartbeatEvery = 24 * time.Hour
+
+type HeartbeatConfig struct {
+	Every string `mapstructure:"every"` // How often to send the summary, e.g. 24h
+	At    string `mapstructure:"at"`    // Optional local time of day to send the first summary at, e.g. 09:00
+
+	every time.Duration // Parsed from Every
+}
+
+// enabled reports whether a heartbeat is configured
+func (h *HeartbeatConfig) enabled() bool {
+	return h.Every != "" || h.At != ""
+}
+
+// validateHeartbeat parses the heartbeat schedule
+func validateHeartbeat(config *Config) error {
+	heartbeat := &config.Heartbeat
+	if !heartbeat.enabled() {
+		return nil
+	}
+
+	heartbeat.every = defaultHeartbeatEvery
+	if heartbeat.Every != "" {
+		every, err := time.ParseDuration(heartbeat.Every)
+		if err != nil {
+			return fmt.Errorf("invalid heartbeat every %q: %w", heartbeat.Every, err)
+		}
+		if every < time.Minute {
+			return fmt.Errorf("heartbeat every must be at least 1m, got %s", heartbeat.Every)
+		}
+		heartbeat.every = every
+	}
+	if heartbeat.At != "" {
+		if _, err := time.Parse("15:04", heartbeat.At); err != nil {
+			return fmt.Errorf("invalid heartbeat at %q: must be a time of day like 09:00", heartbeat.At)
+		}
+	}
+	return nil
+}
+
+// firstHeartbeat returns when the first heartbeat is due after now: the next
+// occurrence of the at time, or one interval from now without one
+func (h *HeartbeatConfig) firstHeartbeat(now time.Time) time.Time {
+	if h.At == "" {
+		return now.Add(h.every)
+	}
+	at, _ := time.Parse("15:04", h.At)
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// itemCounts is the number of healthy and unhealthy items of an item type
+type itemCounts struct {
+	healthy   int
+	unhealthy int
+}
+
+// heartbeatMessage summarizes the monitored items, one line per item type
+func heartbeatMessage(counts map[string]*itemCounts) string {
+	sections := make([]string, 0, len(counts))
+	var healthy, unhealthy int
+	for section, c := range counts {
+		sections = append(sections, section)
+		healthy += c.healthy
+		unhealthy += c.unhealthy
+	}
+	sort.Strings(sections)
+
+	emoji := messageEmoji(emojiHealthy)
+	if unhealthy > 0 {
+		emoji = messageEmoji(emojiDegraded)
+	}
+	lines := []string{fmt.Sprintf("%s Heartbeat: all systems monitored\n%d items healthy, %d unhealthy", emoji, healthy, unhealthy)}
+	for _, section := range sections {
+		c := counts[section]
+		lines = append(lines, fmt.Sprintf("`%s`: %d healthy, %d unhealthy", section, c.healthy, c.unhealthy))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sendHeartbeats posts the summary on the heartbeat schedule, so operators
+// hear from the agent even when nothing is wrong
+func sendHeartbeats(config *Config, notifier *Notifier) {
+	heartbeat := &config.Heartbeat
+	first := heartbeat.firstHeartbeat(time.Now())
+	slog.Info("sending heartbeats", "every", heartbeat.every, "first", first.Format(time.RFC3339))
+
+	time.Sleep(time.Until(first))
+
+	ticker := time.NewTicker(heartbeat.every)
+	defer ticker.Stop()
+
+	for {
+		message := heartbeatMessage(statusSummary(statusItems(config)))
+		if !notifier.Send(message, WebhookOverride{}) {
+			slog.Info("heartbeat", "summary", plainText(message))
+		} else {
+			slog.Info("heartbeat sent")
+		}
+		<-ticker.C
+	}
+}
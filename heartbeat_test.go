@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFirstHeartbeat checks that the first heartbeat waits for the next at
+// time, or one interval without one
+func TestFirstHeartbeat(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		heartbeat HeartbeatConfig
+		expected  time.Time
+	}{
+		{name: "no at time", heartbeat: HeartbeatConfig{every: 6 * time.Hour}, expected: now.Add(6 * time.Hour)},
+		{name: "later today", heartbeat: HeartbeatConfig{At: "18:00", every: 24 * time.Hour}, expected: time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)},
+		{name: "tomorrow", heartbeat: HeartbeatConfig{At: "09:00", every: 24 * time.Hour}, expected: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)},
+		{name: "right now", heartbeat: HeartbeatConfig{At: "10:30", every: 24 * time.Hour}, expected: time.Date(2024, 5, 2, 10, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.heartbeat.firstHeartbeat(now); !got.Equal(tt.expected) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestValidateHeartbeat checks the every default and that bad schedules are rejected
+func TestValidateHeartbeat(t *testing.T) {
+	tests := []struct {
+		heartbeat HeartbeatConfig
+		valid     bool
+		every     time.Duration
+	}{
+		{heartbeat: HeartbeatConfig{At: "09:00"}, valid: true, every: 24 * time.Hour},
+		{heartbeat: HeartbeatConfig{Every: "6h"}, valid: true, every: 6 * time.Hour},
+		{heartbeat: HeartbeatConfig{Every: "30s"}},
+		{heartbeat: HeartbeatConfig{Every: "daily"}},
+		{heartbeat: HeartbeatConfig{At: "9am"}},
+	}
+
+	for _, tt := range tests {
+		config := &Config{Heartbeat: tt.heartbeat}
+		err := validateHeartbeat(config)
+		if (err == nil) != tt.valid {
+			t.Errorf("%+v: expected valid %v, got %v", tt.heartbeat, tt.valid, err)
+		}
+		if tt.valid && config.Heartbeat.every != tt.every {
+			t.Errorf("%+v: expected every %s, got %s", tt.heartbeat, tt.every, config.Heartbeat.every)
+		}
+	}
+}
+
+// TestHeartbeatMessage checks the totals and the sorted line per item type
+func TestHeartbeatMessage(t *testing.T) {
+	message := heartbeatMessage(map[string]*itemCounts{
+		"health":  {healthy: 3},
+		"balance": {healthy: 2, unhealthy: 1},
+	})
+	expected := "💛 Heartbeat: all systems monitored\n5 items healthy, 1 unhealthy\n`balance`: 2 healthy, 1 unhealthy\n`health`: 3 healthy, 0 unhealthy"
+	if message != expected {
+		t.Errorf("expected %q, got %q", expected, message)
+	}
+
+	if message := heartbeatMessage(map[string]*itemCounts{"health": {healthy: 3}}); !strings.HasPrefix(message, "💚") {
+		t.Errorf("expected a healthy heartbeat, got %q", message)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHistorySize        = 100
+	defaultHistoryMaxFileSize = 10 // MB
+)
+
+type HistoryConfig struct {
+	Size          int    `mapstructure:"size"`             // Number of recent alerts and recoveries kept in memory
+	File          string `mapstructure:"file"`             // Optional JSON lines file every event is appended to
+	MaxFileSizeMB int    `mapstructure:"max_file_size_mb"` // Size at which the file is rotated, keeping one rotated file
+}
+
+// validateHistory applies the history defaults
+func validateHistory(config *Config) error {
+	history := &config.History
+	if history.Size < 0 {
+		return fmt.Errorf("history size must not be negative")
+	}
+	if history.Size == 0 {
+		history.Size = defaultHistorySize
+	}
+	if history.MaxFileSizeMB < 0 {
+		return fmt.Errorf("history max_file_size_mb must not be negative")
+	}
+	if history.MaxFileSizeMB == 0 {
+		history.MaxFileSizeMB = defaultHistoryMaxFileSize
+	}
+	return nil
+}
+
+// historyEvent is an alert or recovery in the history
+type historyEvent struct {
+	Time     time.Time `json:"time"`
+	Event    string    `json:"event"` // alert or recovery
+	Type     string    `json:"type"`
+	Group    string    `json:"group"`
+	Name     string    `json:"name"`
+	Severity string    `json:"severity"`
+	Message  string    `json:"message"`
+}
+
+// alertHistory keeps the most recent alerts and recoveries in a ring buffer
+type alertHistory struct {
+	mu     sync.Mutex
+	events []historyEvent // Ring buffer, next is the oldest entry once it's full
+	next   int
+	full   bool
+
+	file *rotatingFile // Optional JSON lines file, rotated with one backup kept
+}
+
+// newAlertHistory creates the history, opening its file if one is configured.
+// A file that can't be opened is logged and the history is kept in memory only.
+func newAlertHistory(config HistoryConfig) *alertHistory {
+	h := &alertHistory{events: make([]historyEvent, config.Size)}
+	if config.File == "" {
+		return h
+	}
+
+	file, err := newRotatingFile(config.File, int64(config.MaxFileSizeMB)<<20, 1, 0)
+	if err != nil {
+		slog.Error("error opening alert history file, keeping the history in memory only", "file", config.File, "error", err)
+		return h
+	}
+	h.file = file
+	return h
+}
+
+// add records an event, appending it to the history file if configured. A
+// nil history records nothing.
+func (h *alertHistory) add(event historyEvent) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events[h.next] = event
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+
+	if h.file == nil {
+		return
+	}
+	if err := h.appendFile(event); err != nil {
+		slog.Error("error writing alert history", "file", h.file.path, "error", err)
+	}
+}
+
+// recent returns the recorded events, oldest first
+func (h *alertHistory) recent() []historyEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]historyEvent{}, h.events[:h.next]...)
+	}
+	return append(append([]historyEvent{}, h.events[h.next:]...), h.events[:h.next]...)
+}
+
+// appendFile appends an event as a JSON line, in one write so the file is
+// only ever rotated between events
+func (h *alertHistory) appendFile(event historyEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %w", err)
+	}
+	_, err = h.file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestAlertHistory checks that alerts and recoveries are recorded even
+// without channels, and that only the most recent are kept, oldest first
+func TestAlertHistory(t *testing.T) {
+	n := &Notifier{deduper: newAlertDeduper(0), history: newAlertHistory(HistoryConfig{Size: 2})}
+
+	n.Alert("🚨 Alert: `rpc` is down", WebhookOverride{}, "health/Hub/rpc", severityCritical, messageData{Group: "Hub", Name: "rpc"})
+	n.Alert("🚨 Alert: `api` is down", WebhookOverride{}, "health/Hub/api", severityCritical, messageData{Group: "Hub", Name: "api"})
+	n.Resolve("✅ Recovery: `rpc` is up", WebhookOverride{}, "health/Hub/rpc", severityCritical, messageData{Group: "Hub", Name: "rpc"})
+
+	p := newProbeServer("127.0.0.1:0", nil, nil, n.history)
+	rec := httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+	var resp historyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode /history: %v", err)
+	}
+	var events []string
+	for _, event := range resp.Events {
+		events = append(events, event.Event+" "+event.Type+"/"+event.Group+"/"+event.Name+": "+event.Message)
+	}
+	expected := []string{"alert health/Hub/api: 🚨 Alert: api is down", "recovery health/Hub/rpc: ✅ Recovery: rpc is up"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %v, got %v", expected, events)
+	}
+}
+
+// TestHistoryFile checks that events are appended as JSON lines and the file
+// is rotated once it reaches its maximum size, keeping one rotated file
+func TestHistoryFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "history.jsonl")
+	history := newAlertHistory(HistoryConfig{Size: 10, File: file, MaxFileSizeMB: 1})
+	defer history.file.Close()
+	history.file.maxSize = 300 // Two events per file
+
+	for _, name := range []string{"rpc", "api", "grpc", "p2p", "evm"} {
+		history.add(historyEvent{Event: "alert", Type: "health", Group: "Hub", Name: name, Message: "down"})
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "history-2*.jsonl"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one rotated file to be kept, got %v", backups)
+	}
+	rotated, err := os.ReadFile(backups[0])
+	if err != nil {
+		t.Fatalf("read rotated history file: %v", err)
+	}
+	current, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read history file: %v", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(string(rotated)+string(current)), "\n") {
+		var event historyEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("expected a JSON event per line, got %q (%v)", line, err)
+		}
+		names = append(names, event.Name)
+	}
+	if expected := []string{"grpc", "p2p", "evm"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the newest events %v across the current and rotated file, got %v", expected, names)
+	}
+}
+
+// TestHistoryFileRotationFailure checks that events keep being appended when
+// the file can't be rotated, that the rotation is only retried once another
+// max size was written, and that it rotates again once renaming works
+func TestHistoryFileRotationFailure(t *testing.T) {
+	var renames int
+	t.Cleanup(func() { renameFile = os.Rename })
+	renameFile = func(string, string) error {
+		renames++
+		return errors.New("read-only file system")
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "history.jsonl")
+	history := newAlertHistory(HistoryConfig{Size: 10, File: file, MaxFileSizeMB: 1})
+	defer history.file.Close()
+	history.file.maxSize = 300 // Two events per file
+
+	for _, name := range []string{"rpc", "api", "grpc", "p2p", "evm"} {
+		history.add(historyEvent{Event: "alert", Type: "health", Group: "Hub", Name: name, Message: "down"})
+	}
+	if renames != 2 {
+		t.Errorf("expected the failed rotation to be retried once after another max size, got %d attempts", renames)
+	}
+	current, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read history file: %v", err)
+	}
+	if lines := strings.Count(string(current), "\n"); lines != 5 {
+		t.Errorf("expected every event in the unrotated file, got %d lines", lines)
+	}
+
+	renameFile = os.Rename
+	for _, name := range []string{"cosmos", "kaspa"} {
+		history.add(historyEvent{Event: "alert", Type: "health", Group: "Hub", Name: name, Message: "down"})
+	}
+	if backups, _ := filepath.Glob(filepath.Join(dir, "history-2*.jsonl")); len(backups) != 1 {
+		t.Errorf("expected the file to rotate once renaming works again, got %v", backups)
+	}
+}
+
+// TestValidateHistory checks the history defaults
+func TestValidateHistory(t *testing.T) {
+	config := &Config{}
+	if err := validateHistory(config); err != nil || config.History.Size != 100 || config.History.MaxFileSizeMB != 10 {
+		t.Errorf("expected the defaults of 100 events and 10 MB, got %+v (%v)", config.History, err)
+	}
+	if err := validateHistory(&Config{History: HistoryConfig{Size: -1}}); err == nil {
+		t.Error("expected a negative size to be rejected")
+	}
+}
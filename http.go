@@ -0,0 +1,291 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPClient sends HTTP requests, implemented by *http.Client and by fakes in tests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// httpClient is shared by every check and recovery monitor so a hung endpoint
+// can't block a goroutine forever. Its timeout is set from the http_timeout
+// config setting at startup.
+var httpClient = &http.Client{Timeout: 30 * time.Second, Transport: newTransport()}
+
+// insecureHTTPClient is used by groups with insecure_skip_verify, for endpoints
+// with self-signed certificates. Its timeout follows httpClient's.
+var insecureHTTPClient = &http.Client{Timeout: 30 * time.Second, Transport: newInsecureTransport()}
+
+// notificationClient is shared by the notification channels and item
+// webhooks. Their requests carry bot tokens and API keys, so it always
+// verifies TLS certificates, whatever insecure_skip_verify says. Its timeout
+// follows httpClient's.
+var notificationClient = &http.Client{Timeout: 30 * time.Second, Transport: newTransport()}
+
+// insecureSkipVerify skips TLS verification for the checks of every group,
+// set from the global insecure_skip_verify config setting at startup
+var insecureSkipVerify = false
+
+// Connection pool limits. Groups check up to max_concurrency items of the same
+// host at once, and the default of 2 idle connections per host would close and
+// reopen most of those connections on every check.
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 32
+)
+
+// newTransport returns a default transport that keeps enough idle connections
+// for concurrent checks against the same host
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	return transport
+}
+
+// newInsecureTransport returns a transport like newTransport that doesn't verify TLS certificates
+func newInsecureTransport() *http.Transport {
+	transport := newTransport()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // Opted into with insecure_skip_verify
+	return transport
+}
+
+// Retry settings for fetches, set from the http_retries and
+// http_retry_delay_ms config settings at startup
+var (
+	httpRetries    = 2
+	httpRetryDelay = 500 * time.Millisecond
+)
+
+// httpResponse is the captured result of an HTTP request
+type httpResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// RequestOptions apply to every request of a group, e.g. extra headers for
+// endpoints behind an auth proxy
+type RequestOptions struct {
+	Headers     map[string]string `mapstructure:"headers"`      // Optional headers such as an API key
+	BearerToken string            `mapstructure:"bearer_token"` // Optional token sent as "Authorization: Bearer <token>"
+
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"` // Don't verify TLS certificates, for self-signed endpoints
+
+	httpClient HTTPClient // Replaces the shared client when set, for tests
+}
+
+// validate checks that the bearer token doesn't conflict with the headers
+func (o RequestOptions) validate() error {
+	if o.BearerToken == "" {
+		return nil
+	}
+	for name := range o.Headers {
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			return fmt.Errorf("bearer_token and an Authorization header can't both be set")
+		}
+	}
+	return nil
+}
+
+// warnInsecure logs a warning if the group skips TLS verification
+func (o RequestOptions) warnInsecure(itemType, group string) {
+	if o.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification is disabled for group", "type", itemType, "group", group)
+	}
+}
+
+// client returns the HTTP client for the group's requests
+func (o RequestOptions) client() HTTPClient {
+	if o.httpClient != nil {
+		return o.httpClient
+	}
+	if o.InsecureSkipVerify || insecureSkipVerify {
+		return insecureHTTPClient
+	}
+	return httpClient
+}
+
+// apply sets the headers on a request
+func (o RequestOptions) apply(req *http.Request) {
+	for name, value := range o.Headers {
+		req.Header.Set(name, value)
+	}
+	if o.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+o.BearerToken)
+	}
+}
+
+// RequestMethod is the method and body of an item's request, for endpoints
+// such as JSON-RPC ones that only answer a POST
+type RequestMethod struct {
+	Method string `mapstructure:"method"` // GET (default) or POST
+	Body   string `mapstructure:"body"`   // Optional body of a POST, sent as JSON unless a Content-Type header is set
+}
+
+// validate checks the method and normalizes it to upper case, defaulting to GET
+func (m *RequestMethod) validate() error {
+	switch strings.ToUpper(m.Method) {
+	case "", http.MethodGet:
+		if m.Body != "" {
+			return fmt.Errorf("body requires method POST")
+		}
+		m.Method = http.MethodGet
+	case http.MethodPost:
+		m.Method = http.MethodPost
+	default:
+		return fmt.Errorf("invalid method '%s': must be GET or POST", m.Method)
+	}
+	return nil
+}
+
+// httpGet performs a GET request with the given headers and reads the whole
+// response, regardless of status code
+func httpGet(ctx context.Context, endpoint string, opts RequestOptions) (*httpResponse, error) {
+	return httpRequest(ctx, endpoint, RequestMethod{}, opts)
+}
+
+// httpRequest performs a request with the item's method and body and the
+// given headers, and reads the whole response, regardless of status code
+func httpRequest(ctx context.Context, endpoint string, m RequestMethod, opts RequestOptions) (*httpResponse, error) {
+	method := m.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if m.Body != "" {
+		body = strings.NewReader(m.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if m.Body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Asking for gzip ourselves turns off the transport's transparent
+	// decompression, doRequest decompresses instead
+	req.Header.Set("Accept-Encoding", "gzip")
+	opts.apply(req)
+
+	return doRequestWithRetry(opts, req)
+}
+
+// doRequestWithRetry performs a request, retrying network errors, 429s and 5xx
+// responses with exponential backoff and jitter. All attempts together are
+// bounded by the HTTP timeout, or by the request's context if it ends
+// sooner, e.g. the recovery_timeout of a recovery check. Once retries are
+// exhausted the last error, or the last response for the caller to judge, is
+// returned.
+func doRequestWithRetry(opts RequestOptions, req *http.Request) (*httpResponse, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), httpClient.Timeout)
+	defer cancel()
+
+	client := opts.client()
+
+	var lastResp *httpResponse
+	var lastErr error
+	for attempt := 0; attempt <= httpRetries; attempt++ {
+		if attempt > 0 {
+			delay := retryDelay(attempt)
+			slog.Warn("retrying request", "method", req.Method, "url", req.URL.Redacted(), "delay", delay.Round(time.Millisecond),
+				"attempt", attempt, "retries", httpRetries, "reason", retryReason(lastResp, lastErr))
+
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return lastResp, lastErr
+			}
+		}
+
+		lastResp, lastErr = doRequest(ctx, client, req)
+		if lastErr == nil && !retryableStatus(lastResp.StatusCode) {
+			return lastResp, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return lastResp, lastErr
+}
+
+// doRequest performs a single attempt of a request and reads the whole response
+func doRequest(ctx context.Context, client HTTPClient, req *http.Request) (*httpResponse, error) {
+	attempt := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		attempt.Body = body
+	}
+
+	resp, err := client.Do(attempt)
+	if err != nil {
+		return nil, classifyRequestError(err)
+	}
+	defer resp.Body.Close()
+
+	// Decompress gzip responses, including those of servers that gzip
+	// whether or not the request asked for it
+	reader := io.Reader(resp.Body)
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		switch {
+		case errors.Is(err, io.EOF):
+			// An empty body, e.g. of a 204, has no gzip header to read
+			reader = strings.NewReader("")
+		case err != nil:
+			return nil, fmt.Errorf("error decompressing response: %w", classifyRequestError(err))
+		default:
+			defer gz.Close()
+			reader = gz
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", classifyRequestError(err))
+	}
+
+	return &httpResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+// retryableStatus reports whether a status code is worth retrying
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryDelay returns the exponential backoff for an attempt with up to 50% jitter
+func retryDelay(attempt int) time.Duration {
+	delay := httpRetryDelay << (attempt - 1)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryReason describes why the previous attempt is being retried
+func retryReason(resp *httpResponse, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("status code %d", resp.StatusCode)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestRequestTimeout checks that a request to a hung endpoint fails once the
+// HTTP timeout passes
+func TestRequestTimeout(t *testing.T) {
+	noRetries(t)
+	timeout := httpClient.Timeout
+	httpClient.Timeout = 50 * time.Millisecond
+	t.Cleanup(func() { httpClient.Timeout = timeout })
+
+	client := fakeClient(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	start := time.Now()
+	if _, err := httpGet(context.Background(), "http://hung.test", RequestOptions{httpClient: client}); err == nil {
+		t.Fatal("expected an error for a hung endpoint")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the request to give up after the timeout, took %s", elapsed)
+	}
+}
+
+// TestRequestRetries checks that network errors, 429s and 5xx responses are
+// retried until one succeeds, and that other responses aren't retried
+func TestRequestRetries(t *testing.T) {
+	retries, delay := httpRetries, httpRetryDelay
+	httpRetries, httpRetryDelay = 3, time.Millisecond
+	t.Cleanup(func() { httpRetries, httpRetryDelay = retries, delay })
+
+	tests := []struct {
+		name     string
+		failures []int // Status codes of the failing attempts, 0 for a network error
+		status   int
+		attempts int
+	}{
+		{name: "network error", failures: []int{0}, status: http.StatusOK, attempts: 2},
+		{name: "rate limited then server errors", failures: []int{http.StatusTooManyRequests, http.StatusBadGateway}, status: http.StatusOK, attempts: 3},
+		{name: "not found", failures: []int{http.StatusNotFound}, status: http.StatusNotFound, attempts: 1},
+		{name: "exhausted", failures: []int{500, 500, 500, 500}, status: http.StatusInternalServerError, attempts: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				if attempts > len(tt.failures) {
+					return respond(http.StatusOK, "ok")(req)
+				}
+				if status := tt.failures[attempts-1]; status != 0 {
+					return respond(status, "error")(req)
+				}
+				return nil, errors.New("connection reset")
+			})
+
+			resp, err := httpGet(context.Background(), "http://node.test", RequestOptions{httpClient: client})
+			if err != nil {
+				t.Fatalf("httpGet: %v", err)
+			}
+			if resp.StatusCode != tt.status || attempts != tt.attempts {
+				t.Errorf("expected status %d after %d attempts, got %d after %d", tt.status, tt.attempts, resp.StatusCode, attempts)
+			}
+		})
+	}
+}
+
+// TestRequestHeaders checks that a group's headers and bearer token are sent
+// with its requests, and that a bearer token can't clash with an
+// Authorization header
+func TestRequestHeaders(t *testing.T) {
+	noRetries(t)
+
+	var header http.Header
+	client := fakeClient(func(req *http.Request) (*http.Response, error) {
+		header = req.Header
+		return respond(http.StatusOK, "ok")(req)
+	})
+
+	opts := RequestOptions{Headers: map[string]string{"x-api-key": "key"}, BearerToken: "token", httpClient: client}
+	if err := opts.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if _, err := httpGet(context.Background(), "http://node.test", opts); err != nil {
+		t.Fatalf("httpGet: %v", err)
+	}
+	if header.Get("X-Api-Key") != "key" || header.Get("Authorization") != "Bearer token" {
+		t.Errorf("expected the API key and bearer token headers, got %v", header)
+	}
+
+	clash := RequestOptions{Headers: map[string]string{"authorization": "Basic abc"}, BearerToken: "token"}
+	if err := clash.validate(); err == nil {
+		t.Error("expected a bearer token with an Authorization header to be rejected")
+	}
+}
+
+// TestRequestClient checks that groups share the pooled clients unless they
+// inject their own
+func TestRequestClient(t *testing.T) {
+	if (RequestOptions{}).client() != HTTPClient(httpClient) {
+		t.Error("expected groups to share the default client")
+	}
+	if (RequestOptions{InsecureSkipVerify: true}).client() != HTTPClient(insecureHTTPClient) {
+		t.Error("expected insecure_skip_verify groups to share the insecure client")
+	}
+	fake := respond(http.StatusOK, "")
+	if got, ok := (RequestOptions{httpClient: fake}).client().(fakeClient); !ok || got == nil {
+		t.Error("expected an injected client to replace the shared one")
+	}
+	if transport := httpClient.Transport.(*http.Transport); transport.MaxIdleConnsPerHost != maxIdleConnsPerHost {
+		t.Errorf("expected %d idle connections per host, got %d", maxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// Classes of requests that got no response, as shown in alerts
+const (
+	errorClassTimeout    = "timeout"
+	errorClassRefused    = "connection refused"
+	errorClassDNS        = "DNS error"
+	errorClassTLS        = "TLS error"
+	errorClassConnection = "connection error"
+)
+
+// requestError is a request or connection that failed before a response,
+// tagged with the class of the failure
+type requestError struct {
+	class string
+	err   error
+}
+
+func (e *requestError) Error() string {
+	return e.class + ": " + e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// classifyRequestError tags the error of a failed request or connection with its class
+func classifyRequestError(err error) error {
+	return &requestError{class: networkErrorClass(err), err: err}
+}
+
+// networkErrorClass tells a timeout, a refused connection, a failed DNS
+// lookup, and a TLS failure apart
+func networkErrorClass(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var headerErr tls.RecordHeaderError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		// Checked first, a DNS lookup that timed out is still a DNS problem
+		return errorClassDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return errorClassTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return errorClassRefused
+	case errors.As(err, &certErr), errors.As(err, &authorityErr), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr), errors.As(err, &headerErr):
+		return errorClassTLS
+	default:
+		return errorClassConnection
+	}
+}
+
+// statusError is a response with an unexpected status code
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// unexpectedStatus returns the error for a response whose status code the caller didn't expect
+func unexpectedStatus(resp *httpResponse) error {
+	return &statusError{StatusCode: resp.StatusCode, Body: string(resp.Body)}
+}
+
+// errorClass returns the class of a check error: the class of a failed
+// request, "HTTP <code>" for an unexpected status, or "" for other errors
+// such as an unparseable response
+func errorClass(err error) string {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return reqErr.class
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return fmt.Sprintf("HTTP %d", statusErr.StatusCode)
+	}
+	return ""
+}
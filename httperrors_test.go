@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	noRetries(t)
+
+	failWith := func(err error) fakeClient {
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, err
+		}
+	}
+
+	tests := []struct {
+		name   string
+		client fakeClient
+		want   string
+	}{
+		{
+			name:   "timeout",
+			client: failWith(fmt.Errorf("Get: %w", context.DeadlineExceeded)),
+			want:   errorClassTimeout,
+		},
+		{
+			name:   "connection refused",
+			client: failWith(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}),
+			want:   errorClassRefused,
+		},
+		{
+			name:   "DNS error",
+			client: failWith(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "rest.test", IsNotFound: true}}),
+			want:   errorClassDNS,
+		},
+		{
+			name:   "other connection error",
+			client: failWith(errors.New("connection reset by peer")),
+			want:   errorClassConnection,
+		},
+		{
+			name:   "unexpected status",
+			client: respond(http.StatusServiceUnavailable, `unavailable`),
+			want:   "HTTP 503",
+		},
+		{
+			name:   "malformed response",
+			client: respond(http.StatusOK, `{"balances":[`),
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getBalance(context.Background(), "http://rest.test", "dym1test", "", RequestOptions{httpClient: tt.client})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errorClass(err); got != tt.want {
+				t.Errorf("expected class %q, got %q (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
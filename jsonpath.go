@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookupJSONPath follows a dotted path such as "block.header.height" or
+// "result.peers.0.id" through decoded JSON. Numeric segments index arrays.
+func lookupJSONPath(data interface{}, path string) (interface{}, error) {
+	current := data
+	for _, segment := range strings.Split(path, ".") {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			next, ok := value[segment]
+			if !ok {
+				return nil, fmt.Errorf("path %s: key %q not found", path, segment)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, fmt.Errorf("path %s: invalid array index %q", path, segment)
+			}
+			current = value[index]
+		default:
+			return nil, fmt.Errorf("path %s: cannot look up %q in a %T", path, segment, current)
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestLookupJSONPath checks map keys, array indexes and lookup errors
+func TestLookupJSONPath(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(`{"result":{"sync_info":{"catching_up":false},"peers":[{"id":"a"},{"id":"b"}]}}`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: "result.sync_info.catching_up", want: "false"},
+		{path: "result.peers.1.id", want: "b"},
+		{path: "result.status", wantErr: `key "status" not found`},
+		{path: "result.peers.2.id", wantErr: `invalid array index "2"`},
+		{path: "result.peers.first", wantErr: `invalid array index "first"`},
+		{path: "result.sync_info.catching_up.value", wantErr: "cannot look up"},
+	}
+
+	for _, tt := range tests {
+		value, err := lookupJSONPath(data, tt.path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.path, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if got := fmt.Sprint(value); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.path, tt.want, got)
+		}
+	}
+}
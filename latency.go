@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// latencyCondition alerts when the regular check of an item with
+// max_latency_ms took longer than that, even if the item was otherwise
+// healthy, and recovers once a check is back under the budget. A slow but up
+// endpoint is degraded, and the healthy or unhealthy checks alone never
+// notice it. A failed check is alerted on its own, so only the response time
+// of an answer is judged.
+func latencyCondition(item, slow *itemState, maxLatencyMs int) condition {
+	maxLatency := time.Duration(maxLatencyMs) * time.Millisecond
+	return condition{
+		Checker: checkFunc(func(context.Context) (bool, string, error) {
+			item.recoveryMonitorMu.Lock()
+			latency := item.latency
+			item.recoveryMonitorMu.Unlock()
+			return latency <= maxLatency, latency.String(), nil
+		}),
+		state: slow, keyPart: "latency", threshold: maxLatency.String(), severity: severityWarning,
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHealthLatency checks that a healthy but slow endpoint is alerted when
+// it's over max_latency_ms, and recovers once it's back under the budget
+func TestHealthLatency(t *testing.T) {
+	noRetries(t)
+
+	var delay time.Duration
+	client := fakeClient(func(req *http.Request) (*http.Response, error) {
+		time.Sleep(delay)
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"result":{"isHealthy":true}}`))}, nil
+	})
+
+	healthConfig := &HealthConfig{Name: "group", RequestOptions: RequestOptions{httpClient: client}, Endpoints: []HealthItem{{
+		Name: "node", Endpoint: "http://node.test/health", HealthPath: defaultHealthPath, HealthyValue: defaultHealthyValue,
+		MaxLatencyMs: 20, itemState: newItemState(), slow: newItemState(),
+	}}}
+	healthItem := &healthConfig.Endpoints[0]
+	notifier := &Notifier{}
+
+	check := func() {
+		t.Helper()
+		if _, err := checkGroupItem(healthConfig, 0, notifier, 3600); err != nil {
+			t.Fatalf("checkGroupItem: %v", err)
+		}
+	}
+
+	check()
+	if healthItem.slow.isUnhealthy {
+		t.Fatal("expected a fast response to be within the budget")
+	}
+
+	delay = 50 * time.Millisecond
+	check()
+	if !healthItem.slow.isUnhealthy || healthItem.slow.lastAlertTime.IsZero() {
+		t.Fatalf("expected a slow response to be alerted, got latency %s", healthItem.latency)
+	}
+	if healthItem.isUnhealthy {
+		t.Error("expected a slow but healthy endpoint not to be marked unhealthy")
+	}
+
+	delay = 0
+	check()
+	if healthItem.slow.isUnhealthy {
+		t.Fatal("expected the endpoint to recover once it's back under the budget")
+	}
+}
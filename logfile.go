@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log file rotation defaults, for log_max_size in megabytes and log_max_backups
+const (
+	defaultLogMaxSize    = 100
+	defaultLogMaxBackups = 5
+)
+
+// logBackupTimeFormat stamps rotated log files, so they sort by age by name
+const logBackupTimeFormat = "20060102T150405.000000000"
+
+// renameFile renames a file being rotated, replaced in tests to fail
+var renameFile = os.Rename
+
+// rotatingFile is an append-only file, the log file or the alert history,
+// that is rotated once it would grow past maxSize: it is renamed with a
+// timestamp, e.g. agent-20240102T150405.000000000.log for agent.log, and a
+// new file is started. Only the newest maxBackups rotated files are kept, and
+// none older than maxAge; zero keeps them all.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// newRotatingFile opens the log file, appending to an existing one
+func newRotatingFile(path string, maxSize int64, maxBackups int, maxAge time.Duration) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating directory: %w", err)
+	}
+	f := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups, maxAge: maxAge}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write appends a log record, rotating the file first if the record would
+// take it past its maximum size. A failed rotation doesn't lose the record,
+// it is still written and the rotation error returned.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var rotateErr error
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// Close closes the current log file
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// rotate renames the current file to a backup, starts a new one, and removes
+// the backups past maxBackups or maxAge. If the file can't be renamed, e.g.
+// on a full or read-only disk, it is reopened to keep appending to, and the
+// rotation is only tried again once another maxSize has been written, so the
+// failure isn't reported on every write.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error closing file: %w", err)
+	}
+
+	// Records logged in quick succession may rotate twice within a clock tick
+	stamp := time.Now()
+	backup := f.backupName(stamp)
+	for {
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			break
+		}
+		stamp = stamp.Add(time.Nanosecond)
+		backup = f.backupName(stamp)
+	}
+	if err := renameFile(f.path, backup); err != nil {
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		f.size = 0
+		return fmt.Errorf("error rotating file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// backupName returns the name of the file rotated at t
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.UTC().Format(logBackupTimeFormat) + ext
+}
+
+// prune removes the rotated files past maxBackups or older than maxAge
+func (f *rotatingFile) prune() error {
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return fmt.Errorf("error listing rotated files: %w", err)
+	}
+	// Only touch files named like backups, not others that happen to match
+	var backups []string
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if _, err := time.Parse(logBackupTimeFormat, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	// Newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, backup := range backups {
+		expired := false
+		if f.maxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && time.Since(info.ModTime()) > f.maxAge {
+				expired = true
+			}
+		}
+		if (f.maxBackups > 0 && i >= f.maxBackups) || expired {
+			if err := os.Remove(backup); err != nil {
+				return fmt.Errorf("error removing rotated file: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRotatingFile checks that the log file is rotated at its maximum size and
+// that only the newest backups are kept
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.log")
+	// A file that merely looks like a backup must survive pruning
+	if err := os.WriteFile(filepath.Join(dir, "agent-notes.log"), []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := newRotatingFile(path, 100, 2, 0)
+	if err != nil {
+		t.Fatalf("newRotatingFile: %v", err)
+	}
+	defer f.Close()
+
+	line := strings.Repeat("x", 59) + "\n"
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != int64(len(line)) {
+		t.Fatalf("expected the current file to hold the last record, got %v, %v", info, err)
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "agent-2*.log"))
+	if len(backups) != 2 {
+		t.Errorf("expected 2 rotated files to be kept, got %v", backups)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "agent-notes.log")); err != nil {
+		t.Errorf("expected an unrelated file to be kept, got %v", err)
+	}
+}
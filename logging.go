@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Supported log formats
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// newLogger builds the agent's logger for a log_level and log_format, writing
+// to output
+func newLogger(level, format string, output io.Writer) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q, expected debug, info, warn, or error", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case logFormatText:
+		return slog.New(slog.NewTextHandler(output, opts)), nil
+	case logFormatJSON:
+		return slog.New(slog.NewJSONHandler(output, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected %s or %s", format, logFormatText, logFormatJSON)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestNewLogger checks the log level filter and the JSON format
+func TestNewLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger("warn", "JSON", &out)
+	if err != nil {
+		t.Fatalf("newLogger: %v", err)
+	}
+
+	logger.Info("check passed")
+	logger.Warn("item unhealthy", "item", "node")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected only the warning to be logged, got %q", out.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("expected a JSON line, got %q", lines[0])
+	}
+	if entry["msg"] != "item unhealthy" || entry["item"] != "node" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+
+	if _, err := newLogger("verbose", logFormatText, &out); err == nil {
+		t.Error("expected an unknown level to be rejected")
+	}
+	if _, err := newLogger("info", "xml", &out); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
@@ -1,21 +1,28 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/big"
+	"math/rand/v2"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
-	"strconv"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -26,109 +33,290 @@ var (
 	BuildTime    = "unknown"
 )
 
+// versionString describes the build for -version and the startup log
+func versionString() string {
+	return fmt.Sprintf("observability-agent %s (commit %s, built %s, %s)", BuildVersion, BuildCommit, BuildTime, runtime.Version())
+}
+
 type AddressItem struct {
-	Name          string `mapstructure:"name"`
-	Address       string `mapstructure:"address"`
-	AlertCooldown int    `mapstructure:"alert_cooldown"` // Optional per-address cooldown
-	Threshold     struct {
-		Denom  string `mapstructure:"denom"`
-		Amount string `mapstructure:"amount"`
-	} `mapstructure:"threshold"`
-
-	lastAlertTime time.Time // Internal tracking, not from config
+	Name             string           `mapstructure:"name"`
+	Address          string           `mapstructure:"address"`
+	AlertCooldown    Seconds          `mapstructure:"alert_cooldown"`    // Optional per-address cooldown
+	RecoveryInterval Seconds          `mapstructure:"recovery_interval"` // Optional per-address recovery check interval in seconds
+	Threshold        BalanceThreshold `mapstructure:"threshold"`
+	ThresholdPreset  string           `mapstructure:"threshold_preset"` // Optional name of a preset the threshold is based on
+	Direction        string           `mapstructure:"direction"`        // Optional: alert when the balance is below (default) or above the threshold
+	MaxDropPercent   float64          `mapstructure:"max_drop_percent"` // Optional: alert when the balance drops by more than this percentage between checks
+	BalancePath      string           `mapstructure:"balance_path"`     // Optional query path with {address}, e.g. for delegations (default: bank balances)
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState                 // Internal tracking, not from config
+	drop            itemState // Alerting state of max_drop_percent
+	balance         *big.Int  // Balance seen by the last regular check
+	previousBalance *big.Int  // Balance seen by the previous regular check, for max_drop_percent
 }
 
 type KaspaAddressItem struct {
-	Name          string `mapstructure:"name"`
-	Address       string `mapstructure:"address"`
-	AlertCooldown int    `mapstructure:"alert_cooldown"` // Optional per-address cooldown
-	Threshold     string `mapstructure:"threshold"`      // Threshold amount in sompi
-
-	lastAlertTime       time.Time   // Internal tracking, not from config
-	isUnhealthy         bool        // Track if currently in unhealthy state
-	recoveryMonitorStop chan bool   // Channel to stop recovery monitoring
-	recoveryMonitorMu   *sync.Mutex // Pointer to avoid copy issues
+	Name             string  `mapstructure:"name"`
+	Address          string  `mapstructure:"address"`
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-address cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-address recovery check interval in seconds
+	Threshold        string  `mapstructure:"threshold"`         // Threshold amount in sompi
+	Decimals         int     `mapstructure:"decimals"`          // Optional display decimals (default: 8)
+	DisplayDenom     string  `mapstructure:"display_denom"`     // Optional display denom (default: KAS)
+	Direction        string  `mapstructure:"direction"`         // Optional: alert when the balance is below (default) or above the threshold
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+// format renders a sompi amount for messages
+func (k *KaspaAddressItem) format(raw string) string {
+	return formatBalance(raw, "sompi", k.Decimals, k.DisplayDenom)
 }
 
 type AddressConfig struct {
 	Name          string        `mapstructure:"name"`
 	RESTEndpoint  string        `mapstructure:"rest_endpoint"`
-	CheckInterval int           `mapstructure:"check_interval"` // Optional per-group check interval
+	CheckInterval Seconds       `mapstructure:"check_interval"` // Optional per-group check interval
+	ExplorerURL   string        `mapstructure:"explorer_url"`   // Optional explorer address page, e.g. https://www.mintscan.io/cosmos/address/{address}
 	Addresses     []AddressItem `mapstructure:"addresses"`
+
+	RequestOptions `mapstructure:",squash"`
 }
 
 type KaspaAddressConfig struct {
 	Name          string             `mapstructure:"name"`
 	RESTEndpoint  string             `mapstructure:"rest_endpoint"`
-	CheckInterval int                `mapstructure:"check_interval"` // Optional per-group check interval
+	CheckInterval Seconds            `mapstructure:"check_interval"` // Optional per-group check interval
+	ExplorerURL   string             `mapstructure:"explorer_url"`   // Optional explorer address page, e.g. https://explorer.kaspa.org/addresses/{address}
 	Addresses     []KaspaAddressItem `mapstructure:"addresses"`
+
+	RequestOptions `mapstructure:",squash"`
 }
 
 type MetricItem struct {
-	Name      string `mapstructure:"name"`
-	Metric    string `mapstructure:"metric"`
-	Threshold int    `mapstructure:"threshold"`
-
-	lastAlertTime       time.Time   // Internal tracking, not from config
-	isUnhealthy         bool        // Track if currently in unhealthy state
-	recoveryMonitorStop chan bool   // Channel to stop recovery monitoring
-	recoveryMonitorMu   *sync.Mutex // Pointer to avoid copy issues
+	Name             string            `mapstructure:"name"`
+	Metric           string            `mapstructure:"metric"`
+	Labels           map[string]string `mapstructure:"labels"` // Optional label selector for metrics with several series
+	Query            string            `mapstructure:"query"`  // Optional PromQL query run on the group's Prometheus server instead of reading metric
+	Threshold        int               `mapstructure:"threshold"`
+	Operator         string            `mapstructure:"operator"`          // gt, gte (default), lt, lte, eq, or ne
+	AlertCooldown    Seconds           `mapstructure:"alert_cooldown"`    // Optional per-metric cooldown
+	RecoveryInterval Seconds           `mapstructure:"recovery_interval"` // Optional per-metric recovery check interval in seconds
+
+	AlertOnMissing     bool `mapstructure:"alert_on_missing"`     // Optional: alert when the metric is missing from the endpoint
+	MissingGracePeriod int  `mapstructure:"missing_grace_period"` // Seconds the metric may be missing before alerting (default: 600)
+
+	MaxLatencyMs int `mapstructure:"max_latency_ms"` // Optional: alert when the metrics endpoint takes longer to respond
+
+	RequestMethod   `mapstructure:",squash"`
+	WebhookOverride `mapstructure:",squash"`
+	Hysteresis      `mapstructure:",squash"`
+
+	itemState              // Internal tracking, not from config
+	slow         itemState // Alerting state of max_latency_ms
+	missingSince time.Time // First check that didn't find the metric, zero while it's present
+}
+
+// series describes the monitored series, including its label selector, or
+// the PromQL query
+func (m *MetricItem) series() string {
+	if m.Query != "" {
+		return m.Query
+	}
+	return seriesString(m.Metric, m.Labels)
+}
+
+// Comparison operators for metric thresholds, the alert fires when
+// "value <operator> threshold" holds
+const (
+	operatorGT  = "gt"
+	operatorGTE = "gte"
+	operatorLT  = "lt"
+	operatorLTE = "lte"
+	operatorEQ  = "eq"
+	operatorNE  = "ne"
+)
+
+// compareMetric reports whether value compared to threshold with op is true,
+// i.e. whether the metric is in alert
+func compareMetric(value, threshold float64, op string) bool {
+	switch op {
+	case operatorGT:
+		return value > threshold
+	case operatorLT:
+		return value < threshold
+	case operatorLTE:
+		return value <= threshold
+	case operatorEQ:
+		return value == threshold
+	case operatorNE:
+		return value != threshold
+	default:
+		return value >= threshold
+	}
+}
+
+// alerting reports whether the metric value breaches the threshold
+func (m *MetricItem) alerting(value float64) bool {
+	return compareMetric(value, float64(m.Threshold), m.Operator)
 }
 
 type MetricConfig struct {
 	Name          string       `mapstructure:"name"`
 	RESTEndpoint  string       `mapstructure:"rest_endpoint"`
-	CheckInterval int          `mapstructure:"check_interval"` // Optional per-group check interval
+	CheckInterval Seconds      `mapstructure:"check_interval"` // Optional per-group check interval
 	Metrics       []MetricItem `mapstructure:"metrics"`
+
+	RequestOptions `mapstructure:",squash"`
 }
 
 type HealthItem struct {
-	Name                string      `mapstructure:"name"`
-	Endpoint            string      `mapstructure:"endpoint"`
-	lastAlertTime       time.Time   // Internal tracking, not from config
-	isUnhealthy         bool        // Track if currently in unhealthy state
-	recoveryMonitorStop chan bool   // Channel to stop recovery monitoring
-	recoveryMonitorMu   *sync.Mutex // Pointer to avoid copy issues
+	Name             string  `mapstructure:"name"`
+	Endpoint         string  `mapstructure:"endpoint"`
+	HealthPath       string  `mapstructure:"health_path"`       // Dotted JSON path of the health value (default: result.isHealthy)
+	HealthyValue     string  `mapstructure:"healthy_value"`     // Value at health_path that means healthy (default: true)
+	ErrorPath        string  `mapstructure:"error_path"`        // Optional dotted JSON path of an error message (default: result.error)
+	ExpectStatus     string  `mapstructure:"expect_status"`     // Optional status code or range, e.g. 200-299, that alone means healthy
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-endpoint cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-endpoint recovery check interval in seconds
+	MaxLatencyMs     int     `mapstructure:"max_latency_ms"`    // Optional: alert when the endpoint takes longer to respond
+
+	RequestMethod   `mapstructure:",squash"`
+	WebhookOverride `mapstructure:",squash"`
+	Hysteresis      `mapstructure:",squash"`
+
+	itemState                 // Internal tracking, not from config
+	slow            itemState // Alerting state of max_latency_ms
+	expectStatusMin int       // Parsed from expect_status
+	expectStatusMax int       // Parsed from expect_status
 }
 
 type HealthConfig struct {
 	Name          string       `mapstructure:"name"`
-	CheckInterval int          `mapstructure:"check_interval"` // Optional per-group check interval
+	CheckInterval Seconds      `mapstructure:"check_interval"` // Optional per-group check interval
 	Endpoints     []HealthItem `mapstructure:"endpoints"`
+
+	RequestOptions `mapstructure:",squash"`
 }
 
 type KaspaValidatorItem struct {
-	Name          string `mapstructure:"name"`
-	Endpoint      string `mapstructure:"endpoint"`
-	AlertCooldown int    `mapstructure:"alert_cooldown"` // Optional per-validator cooldown
-
-	lastAlertTime       time.Time   // Internal tracking, not from config
-	isUnhealthy         bool        // Track if currently in unhealthy state
-	unhealthySince      time.Time   // When the validator first became unhealthy
-	alertSent           bool        // Whether alert has been sent for current unhealthy period
-	recoveryMonitorStop chan bool   // Channel to stop recovery monitoring
-	recoveryMonitorMu   *sync.Mutex // Pointer to avoid copy issues
+	Name             string  `mapstructure:"name"`
+	Endpoint         string  `mapstructure:"endpoint"`
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-validator cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-validator recovery check interval in seconds
+	ExpectContains   string  `mapstructure:"expect_contains"`   // Optional text the response body must contain
+	ExpectJSONField  string  `mapstructure:"expect_json_field"` // Optional dotted JSON path that must exist, or path=value it must equal
+
+	RequestMethod   `mapstructure:",squash"`
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
 }
 
 type KaspaValidatorConfig struct {
 	Name          string               `mapstructure:"name"`
-	CheckInterval int                  `mapstructure:"check_interval"` // Optional per-group check interval
+	CheckInterval Seconds              `mapstructure:"check_interval"` // Optional per-group check interval
 	AlertDelay    int                  `mapstructure:"alert_delay"`    // Seconds validator must be unhealthy before alerting
 	Validators    []KaspaValidatorItem `mapstructure:"validators"`
+
+	RequestOptions `mapstructure:",squash"`
 }
 
 type Config struct {
-	CheckInterval   int                    `mapstructure:"check_interval"`
-	AlertCooldown   int                    `mapstructure:"alert_cooldown"` // Global cooldown setting
-	Metrics         []MetricConfig         `mapstructure:"metrics"`
-	Addresses       []AddressConfig        `mapstructure:"addresses"`
-	KaspaAddresses  []KaspaAddressConfig   `mapstructure:"kaspa_addresses"`
-	KaspaValidators []KaspaValidatorConfig `mapstructure:"kaspa_validators"`
-	Health          []HealthConfig         `mapstructure:"health"`
-	Telegram        struct {
-		BotToken string `mapstructure:"bot_token"`
-		ChatID   int64  `mapstructure:"chat_id"`
+	CheckInterval    Seconds                     `mapstructure:"check_interval"`
+	AlertCooldown    Seconds                     `mapstructure:"alert_cooldown"` // Global cooldown setting
+	Metrics          []MetricConfig              `mapstructure:"metrics"`
+	Addresses        []AddressConfig             `mapstructure:"addresses"`
+	KaspaAddresses   []KaspaAddressConfig        `mapstructure:"kaspa_addresses"`
+	KaspaValidators  []KaspaValidatorConfig      `mapstructure:"kaspa_validators"`
+	Health           []HealthConfig              `mapstructure:"health"`
+	HeaderChecks     []HeaderCheckConfig         `mapstructure:"header_checks"`
+	Wallets          []WalletConfig              `mapstructure:"wallets"`
+	GrantChecks      []GrantConfig               `mapstructure:"grant_checks"`
+	EVMAddresses     []EVMAddressConfig          `mapstructure:"evm_addresses"`
+	BTCAddresses     []BTCAddressConfig          `mapstructure:"btc_addresses"`
+	Silences         []Silence                   `mapstructure:"silences"`
+	Presets          map[string]BalanceThreshold `mapstructure:"presets"`   // Named thresholds that addresses can reference
+	Templates        map[string]MessageTemplate  `mapstructure:"templates"` // Optional message overrides per item type
+	BlockHeight      []BlockHeightConfig         `mapstructure:"block_height"`
+	TCPChecks        []TCPCheckConfig            `mapstructure:"tcp_checks"`
+	GRPCHealth       []GRPCHealthConfig          `mapstructure:"grpc_health"`
+	CertChecks       []CertCheckConfig           `mapstructure:"cert_checks"`
+	DNSChecks        []DNSCheckConfig            `mapstructure:"dns_checks"`
+	CosmosValidators []CosmosValidatorConfig     `mapstructure:"cosmos_validators"`
+	PeerChecks       []PeerCountConfig           `mapstructure:"peer_checks"`
+	SyncChecks       []SyncConfig                `mapstructure:"sync_checks"`
+	Telegram         struct {
+		BotToken    string  `mapstructure:"bot_token"`
+		ChatID      int64   `mapstructure:"chat_id"`
+		MinSeverity string  `mapstructure:"min_severity"` // Optional lowest alert severity to send
+		ParseMode   string  `mapstructure:"parse_mode"`   // markdownv2 (default), markdown, or plain
+		SendTimeout Seconds `mapstructure:"send_timeout"` // Seconds a send may take, rate-limit waits included
 	} `mapstructure:"telegram"`
+	Slack      SlackConfig      `mapstructure:"slack"`
+	Discord    DiscordConfig    `mapstructure:"discord"`
+	Mattermost MattermostConfig `mapstructure:"mattermost"`
+	Teams      TeamsConfig      `mapstructure:"teams"`
+	Pushover   PushoverConfig   `mapstructure:"pushover"`
+	Matrix     MatrixConfig     `mapstructure:"matrix"`
+	PagerDuty  PagerDutyConfig  `mapstructure:"pagerduty"`
+	Opsgenie   OpsgenieConfig   `mapstructure:"opsgenie"`
+	SMS        SMSConfig        `mapstructure:"sms"`
+	Email      EmailConfig      `mapstructure:"email"`
+
+	StartupMessage string `mapstructure:"startup_message"` // Text sent to test the channels at startup, false tests them silently
+
+	DeadLetterFile   string `mapstructure:"dead_letter_file"`   // Optional JSON lines file for undeliverable alerts
+	DeadLetterReplay bool   `mapstructure:"dead_letter_replay"` // Retry dead-lettered alerts after the next successful delivery
+
+	NotificationQueue struct {
+		Size           int    `mapstructure:"size"`            // Maximum number of queued notifications
+		OverflowPolicy string `mapstructure:"overflow_policy"` // block, drop_oldest, or drop_newest
+	} `mapstructure:"notification_queue"`
+
+	DedupWindow int  `mapstructure:"dedup_window"` // Seconds to suppress identical alerts for, 0 disables
+	Digest      bool `mapstructure:"digest"`       // Send the alerts of a group's check cycle as one message
+
+	MaxAlertsPerMinute int `mapstructure:"max_alerts_per_minute"` // Alerts sent per minute across all items before they're summarized, 0 disables
+
+	Heartbeat HeartbeatConfig `mapstructure:"heartbeat"` // Optional periodic summary of the monitored items
+	History   HistoryConfig   `mapstructure:"history"`   // Recent alerts and recoveries served on /history
+
+	MetricsListen string `mapstructure:"metrics_listen"` // Optional address to expose Prometheus metrics on, e.g. ":9090"
+	HTTPTimeout   int    `mapstructure:"http_timeout"`   // Timeout in seconds for every HTTP request
+
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"` // Don't verify TLS certificates of any check, groups can opt in individually
+
+	HTTPRetries      int `mapstructure:"http_retries"`        // Retries for failed fetches, 0 disables retrying
+	HTTPRetryDelayMs int `mapstructure:"http_retry_delay_ms"` // Base delay in milliseconds before the first retry
+
+	MaxConcurrency int `mapstructure:"max_concurrency"` // Maximum items of a group checked at once
+
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Seconds between recovery checks of an unhealthy item
+	RecoveryTimeout  int     `mapstructure:"recovery_timeout"`  // Seconds each recovery check may take
+
+	CheckJitter int `mapstructure:"check_jitter"` // Largest random delay before each check as a percentage of the interval, 0 disables
+
+	CheckBackoff int `mapstructure:"check_backoff"` // Largest multiple of the interval a failing item's checks back off to, 0 disables
+
+	HealthListen string `mapstructure:"health_listen"` // Address of the agent's own /healthz and /readyz, empty disables
+
+	UseEmoji bool `mapstructure:"use_emoji"` // Start messages with emoji rather than plain tags like [ALERT]
+
+	ThousandsSeparator string `mapstructure:"thousands_separator"` // Separator between groups of three digits of displayed amounts, empty disables grouping
+	DecimalSeparator   string `mapstructure:"decimal_separator"`   // Separator before the fractional digits of displayed amounts
+
+	LogLevel  string `mapstructure:"log_level"`  // debug, info, warn, or error
+	LogFormat string `mapstructure:"log_format"` // text or json
+
+	LogFile       string `mapstructure:"log_file"`        // Optional file the logs are also written to, empty logs to stdout only
+	LogMaxSize    int    `mapstructure:"log_max_size"`    // Megabytes the log file may grow to before it is rotated
+	LogMaxBackups int    `mapstructure:"log_max_backups"` // Rotated log files kept, 0 keeps all
+	LogMaxAge     int    `mapstructure:"log_max_age"`     // Days rotated log files are kept, 0 keeps them regardless of age
 }
 
 type BalanceResponse struct {
@@ -145,52 +333,308 @@ type KaspaBalanceResponse struct {
 	Balance int64  `json:"balance"`
 }
 
+// Defaults that match the JSON-RPC {"result":{"isHealthy":bool,"error":string}} health response
+const (
+	defaultHealthPath      = "result.isHealthy"
+	defaultHealthyValue    = "true"
+	defaultHealthErrorPath = "result.error"
+)
+
+// HealthResponse is the health read from a health endpoint's JSON response
 type HealthResponse struct {
-	JSONRPC string `json:"jsonrpc"`
-	Result  struct {
-		IsHealthy bool   `json:"isHealthy"`
-		Error     string `json:"error"`
-	} `json:"result"`
-	ID int `json:"id"`
+	IsHealthy bool
+	Error     string // Error reported by the endpoint, if any
 }
 
-func loadConfig(configPath string) (*Config, error) {
-	if configPath != "" {
-		// If a config path is provided, use it directly
-		viper.SetConfigFile(configPath)
-	} else {
-		// Default behavior: look for config.yaml in the current directory
-		viper.SetConfigName("config")
-		viper.SetConfigType("yaml")
-		viper.AddConfigPath(".")
+// loadConfig loads the config file, merged with the files of configDir if set
+func loadConfig(configPath, configDir string) (*Config, error) {
+	if err := readConfig(configPath, configDir); err != nil {
+		return nil, err
 	}
-
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+	configSource.path, configSource.dir = configPath, configDir
+	if err := expandEnvVars(); err != nil {
+		return nil, fmt.Errorf("error expanding environment variables in config: %w", err)
 	}
 
+	// Record the keys no field took, so a typo like "thresold" fails loading
+	// instead of silently leaving the item unconfigured, and accept duration
+	// strings like 5m for the settings in seconds
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	var metadata mapstructure.Metadata
+	if err := viper.Unmarshal(&config, func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = &metadata
+		dc.DecodeHook = mapstructure.ComposeDecodeHookFunc(secondsHook, dc.DecodeHook)
+	}); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
+	if len(metadata.Unused) > 0 {
+		sort.Strings(metadata.Unused)
+		return nil, fmt.Errorf("unknown config keys: %s", strings.Join(metadata.Unused, ", "))
+	}
 
 	// Only validate Telegram config if bot token is provided
 	if config.Telegram.BotToken != "" && config.Telegram.ChatID == 0 {
 		return nil, fmt.Errorf("telegram chat ID is required when bot token is provided")
 	}
+	if err := validateTelegramParseMode(config.Telegram.ParseMode); err != nil {
+		return nil, err
+	}
+	if config.Telegram.SendTimeout < 0 {
+		return nil, fmt.Errorf("telegram send timeout must not be negative")
+	}
+	if config.Telegram.SendTimeout == 0 {
+		config.Telegram.SendTimeout = defaultTelegramSendTimeout // Default to 30 seconds if not specified
+	}
+
+	if !viper.IsSet("use_emoji") {
+		config.UseEmoji = true // Default to emoji if not specified
+	}
+
+	// startup_message is the text of the startup message, or false to test the
+	// channels without posting, e.g. when pods are restarted frequently
+	if enabled, isBool := viper.Get("startup_message").(bool); isBool && !enabled {
+		config.StartupMessage = ""
+	} else if isBool || config.StartupMessage == "" {
+		config.StartupMessage = defaultStartupMessage(config.UseEmoji)
+	}
+
+	// Only validate Slack config if a webhook URL is provided
+	if config.Slack.WebhookURL != "" {
+		if err := validateWebhookURL(config.Slack.WebhookURL); err != nil {
+			return nil, fmt.Errorf("slack: %w", err)
+		}
+	}
+
+	// Only validate Discord config if a webhook URL is provided
+	if config.Discord.WebhookURL != "" {
+		if err := validateWebhookURL(config.Discord.WebhookURL); err != nil {
+			return nil, fmt.Errorf("discord: %w", err)
+		}
+	}
+
+	// Only validate Mattermost config if a webhook URL is provided
+	if config.Mattermost.WebhookURL != "" {
+		if err := validateWebhookURL(config.Mattermost.WebhookURL); err != nil {
+			return nil, fmt.Errorf("mattermost: %w", err)
+		}
+	}
+
+	// Only validate Teams config if a webhook URL is provided
+	if config.Teams.WebhookURL != "" {
+		if err := validateWebhookURL(config.Teams.WebhookURL); err != nil {
+			return nil, fmt.Errorf("teams: %w", err)
+		}
+	}
+
+	// Only validate Pushover config if an application token is provided
+	if config.Pushover.Token != "" {
+		if err := config.Pushover.validate(); err != nil {
+			return nil, fmt.Errorf("pushover: %w", err)
+		}
+	}
+
+	// Only validate Matrix config if a homeserver is provided
+	if config.Matrix.Homeserver != "" {
+		if err := config.Matrix.validate(); err != nil {
+			return nil, fmt.Errorf("matrix: %w", err)
+		}
+	}
+
+	// Only validate Opsgenie config if an API key is provided
+	if config.Opsgenie.APIKey != "" {
+		if err := config.Opsgenie.validate(); err != nil {
+			return nil, fmt.Errorf("opsgenie: %w", err)
+		}
+	}
+
+	// Only validate SMS config if a Twilio account is provided
+	if config.SMS.AccountSID != "" {
+		if err := config.SMS.validate(); err != nil {
+			return nil, fmt.Errorf("sms: %w", err)
+		}
+	}
 
+	// Only validate email config if an SMTP host is provided
+	if config.Email.SMTPHost != "" {
+		if err := config.Email.validate(); err != nil {
+			return nil, fmt.Errorf("email: %w", err)
+		}
+	}
+
+	// Check the min_severity of every channel
+	for channel, minSeverity := range map[string]string{
+		"telegram":   config.Telegram.MinSeverity,
+		"slack":      config.Slack.MinSeverity,
+		"discord":    config.Discord.MinSeverity,
+		"mattermost": config.Mattermost.MinSeverity,
+		"teams":      config.Teams.MinSeverity,
+		"pushover":   config.Pushover.MinSeverity,
+		"matrix":     config.Matrix.MinSeverity,
+		"email":      config.Email.MinSeverity,
+		"pagerduty":  config.PagerDuty.MinSeverity,
+		"opsgenie":   config.Opsgenie.MinSeverity,
+	} {
+		if err := validateSeverity(minSeverity); err != nil {
+			return nil, fmt.Errorf("%s: min_severity: %w", channel, err)
+		}
+	}
+
+	if config.CheckInterval < 0 {
+		return nil, fmt.Errorf("check interval must not be negative")
+	}
 	if config.CheckInterval == 0 {
 		config.CheckInterval = 600 // Default to 600 seconds if not specified
 	}
 
+	if config.HTTPTimeout < 0 {
+		return nil, fmt.Errorf("http timeout must not be negative")
+	}
+	if config.HTTPTimeout == 0 {
+		config.HTTPTimeout = 30 // Default to 30 seconds if not specified
+	}
+
+	if config.MaxConcurrency < 0 {
+		return nil, fmt.Errorf("max concurrency must not be negative")
+	}
+	if config.MaxConcurrency == 0 {
+		config.MaxConcurrency = 8 // Default to 8 concurrent checks per group if not specified
+	}
+
+	if config.RecoveryInterval < 0 {
+		return nil, fmt.Errorf("recovery interval must not be negative")
+	}
+	if config.RecoveryInterval == 0 {
+		config.RecoveryInterval = 5 // Default to 5 seconds if not specified
+	}
+	if config.RecoveryTimeout < 0 {
+		return nil, fmt.Errorf("recovery timeout must not be negative")
+	}
+	if config.RecoveryTimeout == 0 {
+		config.RecoveryTimeout = 10 // Default to 10 seconds if not specified
+	}
+
+	if config.CheckJitter < 0 || config.CheckJitter > 100 {
+		return nil, fmt.Errorf("check jitter must be a percentage between 0 and 100")
+	}
+	if !viper.IsSet("check_jitter") {
+		config.CheckJitter = 10 // Default to up to 10% of the interval if not specified
+	}
+
+	if config.CheckBackoff < 0 {
+		return nil, fmt.Errorf("check backoff must not be negative")
+	}
+	if !viper.IsSet("check_backoff") {
+		config.CheckBackoff = 8 // Default to backing off up to 8 intervals if not specified
+	}
+
+	if config.HTTPRetries < 0 {
+		return nil, fmt.Errorf("http retries must not be negative")
+	}
+	if !viper.IsSet("http_retries") {
+		config.HTTPRetries = 2 // Default to 2 retries if not specified
+	}
+	if config.HTTPRetryDelayMs < 0 {
+		return nil, fmt.Errorf("http retry delay must not be negative")
+	}
+	if config.HTTPRetryDelayMs == 0 {
+		config.HTTPRetryDelayMs = 500 // Default to 500 milliseconds if not specified
+	}
+
+	if config.LogLevel == "" {
+		config.LogLevel = "info" // Default to info if not specified
+	}
+	if config.LogFormat == "" {
+		config.LogFormat = logFormatText // Default to human-friendly text if not specified
+	}
+	if _, err := newLogger(config.LogLevel, config.LogFormat, io.Discard); err != nil {
+		return nil, err
+	}
+	if config.LogMaxSize < 0 {
+		return nil, fmt.Errorf("log_max_size must not be negative")
+	}
+	if config.LogMaxSize == 0 {
+		config.LogMaxSize = defaultLogMaxSize // Default to 100 MB if not specified
+	}
+	if config.LogMaxBackups < 0 {
+		return nil, fmt.Errorf("log_max_backups must not be negative")
+	}
+	if !viper.IsSet("log_max_backups") {
+		config.LogMaxBackups = defaultLogMaxBackups // 0 keeps every rotated file, so only default when unset
+	}
+	if config.LogMaxAge < 0 {
+		return nil, fmt.Errorf("log_max_age must not be negative")
+	}
+
+	if !viper.IsSet("health_listen") {
+		config.HealthListen = ":8080" // Default to :8080 if not specified
+	}
+
+	if config.NotificationQueue.Size < 0 {
+		return nil, fmt.Errorf("notification queue size must not be negative")
+	}
+	if config.NotificationQueue.Size == 0 {
+		config.NotificationQueue.Size = 100 // Default to 100 queued notifications if not specified
+	}
+	switch config.NotificationQueue.OverflowPolicy {
+	case "":
+		config.NotificationQueue.OverflowPolicy = overflowBlock
+	case overflowBlock, overflowDropOldest, overflowDropNewest:
+	default:
+		return nil, fmt.Errorf("invalid notification queue overflow policy '%s': must be %s, %s, or %s",
+			config.NotificationQueue.OverflowPolicy, overflowBlock, overflowDropOldest, overflowDropNewest)
+	}
+
+	if config.DedupWindow < 0 {
+		return nil, fmt.Errorf("dedup window must not be negative")
+	}
+	if !viper.IsSet("thousands_separator") {
+		config.ThousandsSeparator = "," // An empty separator disables grouping, so only default when unset
+	}
+	if config.DecimalSeparator == "" {
+		config.DecimalSeparator = "." // Default to a decimal point if not specified
+	}
+	if err := validateSeparators(config.ThousandsSeparator, config.DecimalSeparator); err != nil {
+		return nil, err
+	}
+	if config.MaxAlertsPerMinute < 0 {
+		return nil, fmt.Errorf("max_alerts_per_minute must not be negative")
+	}
+	if !viper.IsSet("max_alerts_per_minute") {
+		config.MaxAlertsPerMinute = defaultMaxAlertsPerMinute // 0 disables the cap, so only default when unset
+	}
+
+	if err := validateHeartbeat(&config); err != nil {
+		return nil, err
+	}
+	if err := validateHistory(&config); err != nil {
+		return nil, err
+	}
+
+	// Expand multi-chain wallets into address groups so they're validated and monitored like any other
+	if err := expandWallets(&config); err != nil {
+		return nil, err
+	}
+	if err := applyThresholdPresets(&config); err != nil {
+		return nil, err
+	}
+
 	// Validate each address configuration if any are provided
 	for i, addrGroup := range config.Addresses {
 		if addrGroup.RESTEndpoint == "" {
 			return nil, fmt.Errorf("REST endpoint is required for address group #%d", i+1)
 		}
+		if err := validateEndpoint(addrGroup.RESTEndpoint); err != nil {
+			return nil, fmt.Errorf("address group #%d: %w", i+1, err)
+		}
 		if addrGroup.Name == "" {
 			config.Addresses[i].Name = fmt.Sprintf("Address Group %d", i+1) // Set default name if not provided
 		}
+		if err := addrGroup.RequestOptions.validate(); err != nil {
+			return nil, fmt.Errorf("address group '%s': %w", config.Addresses[i].Name, err)
+		}
+		if err := validateExplorerURL(addrGroup.ExplorerURL); err != nil {
+			return nil, fmt.Errorf("address group '%s': %w", config.Addresses[i].Name, err)
+		}
 
 		// Validate each address within the group
 		for j, addr := range addrGroup.Addresses {
@@ -200,12 +644,46 @@ func loadConfig(configPath string) (*Config, error) {
 			if addr.Threshold.Denom == "" {
 				return nil, fmt.Errorf("threshold denom is required for address '%s' in group '%s'", addr.Address, addrGroup.Name)
 			}
+			if addr.Threshold.Decimals < 0 {
+				return nil, fmt.Errorf("threshold decimals must not be negative for address '%s' in group '%s'", addr.Address, addrGroup.Name)
+			}
+			if addr.Threshold.DisplayAmount != "" {
+				if addr.Threshold.Amount != "" {
+					return nil, fmt.Errorf("threshold amount and display_amount are mutually exclusive for address '%s' in group '%s'", addr.Address, addrGroup.Name)
+				}
+				amount, err := parseDisplayAmount(addr.Threshold.DisplayAmount, addr.Threshold.Decimals)
+				if err != nil {
+					return nil, fmt.Errorf("threshold of address '%s' in group '%s': %w", addr.Address, addrGroup.Name, err)
+				}
+				addr.Threshold.Amount = amount
+				config.Addresses[i].Addresses[j].Threshold.Amount = amount
+			}
 			if addr.Threshold.Amount == "" {
 				return nil, fmt.Errorf("threshold amount is required for address '%s' in group '%s'", addr.Address, addrGroup.Name)
 			}
+			if err := validateDirection(addr.Direction); err != nil {
+				return nil, fmt.Errorf("address '%s' in group '%s': %w", addr.Address, addrGroup.Name, err)
+			}
+			if addr.Direction == "" {
+				config.Addresses[i].Addresses[j].Direction = directionBelow
+			}
+			if err := validateBalancePath(addr.BalancePath); err != nil {
+				return nil, fmt.Errorf("address '%s' in group '%s': %w", addr.Address, addrGroup.Name, err)
+			}
+			if addr.MaxDropPercent < 0 || addr.MaxDropPercent > 100 {
+				return nil, fmt.Errorf("max_drop_percent must be between 0 and 100 for address '%s' in group '%s'", addr.Address, addrGroup.Name)
+			}
 			if addr.Name == "" {
 				config.Addresses[i].Addresses[j].Name = fmt.Sprintf("Wallet %d", j+1) // Set default name if not provided
 			}
+			if err := addr.WebhookOverride.validate(); err != nil {
+				return nil, fmt.Errorf("address '%s' in group '%s': %w", addr.Address, addrGroup.Name, err)
+			}
+			if addr.RecoveryInterval < 0 {
+				return nil, fmt.Errorf("recovery_interval must not be negative for address '%s' in group '%s'", addr.Address, addrGroup.Name)
+			}
+			config.Addresses[i].Addresses[j].itemState = newItemState()
+			config.Addresses[i].Addresses[j].drop = newItemState()
 		}
 	}
 
@@ -214,9 +692,18 @@ func loadConfig(configPath string) (*Config, error) {
 		if kaspaGroup.RESTEndpoint == "" {
 			return nil, fmt.Errorf("REST endpoint is required for Kaspa address group #%d", i+1)
 		}
+		if err := validateEndpoint(kaspaGroup.RESTEndpoint); err != nil {
+			return nil, fmt.Errorf("Kaspa address group #%d: %w", i+1, err)
+		}
 		if kaspaGroup.Name == "" {
 			config.KaspaAddresses[i].Name = fmt.Sprintf("Kaspa Address Group %d", i+1) // Set default name if not provided
 		}
+		if err := kaspaGroup.RequestOptions.validate(); err != nil {
+			return nil, fmt.Errorf("Kaspa address group '%s': %w", config.KaspaAddresses[i].Name, err)
+		}
+		if err := validateExplorerURL(kaspaGroup.ExplorerURL); err != nil {
+			return nil, fmt.Errorf("Kaspa address group '%s': %w", config.KaspaAddresses[i].Name, err)
+		}
 
 		// Validate each Kaspa address within the group
 		for j, addr := range kaspaGroup.Addresses {
@@ -226,23 +713,122 @@ func loadConfig(configPath string) (*Config, error) {
 			if addr.Threshold == "" {
 				return nil, fmt.Errorf("threshold is required for Kaspa address '%s' in group '%s'", addr.Address, kaspaGroup.Name)
 			}
+			if addr.Decimals < 0 {
+				return nil, fmt.Errorf("decimals must not be negative for Kaspa address '%s' in group '%s'", addr.Address, kaspaGroup.Name)
+			}
+			if err := validateDirection(addr.Direction); err != nil {
+				return nil, fmt.Errorf("Kaspa address '%s' in group '%s': %w", addr.Address, kaspaGroup.Name, err)
+			}
+			if addr.Direction == "" {
+				config.KaspaAddresses[i].Addresses[j].Direction = directionBelow
+			}
+			if addr.Decimals == 0 && addr.DisplayDenom == "" {
+				// Show KAS by default, sompi amounts are hard to read
+				config.KaspaAddresses[i].Addresses[j].Decimals = kaspaDecimals
+				config.KaspaAddresses[i].Addresses[j].DisplayDenom = kaspaDisplayDenom
+			}
 			if addr.Name == "" {
 				config.KaspaAddresses[i].Addresses[j].Name = fmt.Sprintf("Kaspa Wallet %d", j+1) // Set default name if not provided
 			}
+			if err := addr.WebhookOverride.validate(); err != nil {
+				return nil, fmt.Errorf("Kaspa address '%s' in group '%s': %w", addr.Address, kaspaGroup.Name, err)
+			}
+			if addr.RecoveryInterval < 0 {
+				return nil, fmt.Errorf("recovery_interval must not be negative for Kaspa address '%s' in group '%s'", addr.Address, kaspaGroup.Name)
+			}
+			config.KaspaAddresses[i].Addresses[j].itemState = newItemState()
 		}
 	}
 
 	// Initialize mutexes for metrics
 	for i := range config.Metrics {
+		if err := config.Metrics[i].RequestOptions.validate(); err != nil {
+			return nil, fmt.Errorf("metric group '%s': %w", config.Metrics[i].Name, err)
+		}
+		if err := validateEndpoint(config.Metrics[i].RESTEndpoint); err != nil {
+			return nil, fmt.Errorf("metric group '%s': %w", config.Metrics[i].Name, err)
+		}
 		for j := range config.Metrics[i].Metrics {
-			config.Metrics[i].Metrics[j].recoveryMonitorMu = &sync.Mutex{}
+			if err := config.Metrics[i].Metrics[j].WebhookOverride.validate(); err != nil {
+				return nil, fmt.Errorf("metric '%s' in group '%s': %w", config.Metrics[i].Metrics[j].Metric, config.Metrics[i].Name, err)
+			}
+			if config.Metrics[i].Metrics[j].RecoveryInterval < 0 {
+				return nil, fmt.Errorf("recovery_interval must not be negative for metric '%s' in group '%s'", config.Metrics[i].Metrics[j].Metric, config.Metrics[i].Name)
+			}
+			if err := config.Metrics[i].Metrics[j].Hysteresis.validate(); err != nil {
+				return nil, fmt.Errorf("metric '%s' in group '%s': %w", config.Metrics[i].Metrics[j].Metric, config.Metrics[i].Name, err)
+			}
+			if config.Metrics[i].Metrics[j].MissingGracePeriod < 0 {
+				return nil, fmt.Errorf("missing_grace_period must not be negative for metric '%s' in group '%s'", config.Metrics[i].Metrics[j].Metric, config.Metrics[i].Name)
+			}
+			if err := config.Metrics[i].Metrics[j].RequestMethod.validate(); err != nil {
+				return nil, fmt.Errorf("metric '%s' in group '%s': %w", config.Metrics[i].Metrics[j].Metric, config.Metrics[i].Name, err)
+			}
+			if config.Metrics[i].Metrics[j].MaxLatencyMs < 0 {
+				return nil, fmt.Errorf("max_latency_ms must not be negative for metric '%s' in group '%s'", config.Metrics[i].Metrics[j].Metric, config.Metrics[i].Name)
+			}
+			if err := config.Metrics[i].Metrics[j].validateQuery(); err != nil {
+				return nil, fmt.Errorf("metric '%s' in group '%s': %w", config.Metrics[i].Metrics[j].series(), config.Metrics[i].Name, err)
+			}
+			if config.Metrics[i].Metrics[j].MissingGracePeriod == 0 {
+				config.Metrics[i].Metrics[j].MissingGracePeriod = defaultMissingGracePeriod
+			}
+			switch config.Metrics[i].Metrics[j].Operator {
+			case "":
+				config.Metrics[i].Metrics[j].Operator = operatorGTE // Default to gte for backward compatibility
+			case operatorGT, operatorGTE, operatorLT, operatorLTE, operatorEQ, operatorNE:
+			default:
+				return nil, fmt.Errorf("invalid operator '%s' for metric '%s' in group '%s': must be gt, gte, lt, lte, eq, or ne",
+					config.Metrics[i].Metrics[j].Operator, config.Metrics[i].Metrics[j].Metric, config.Metrics[i].Name)
+			}
+			config.Metrics[i].Metrics[j].itemState = newItemState()
+			config.Metrics[i].Metrics[j].slow = newItemState()
 		}
 	}
 
 	// Initialize mutexes for health endpoints
 	for i := range config.Health {
+		if err := config.Health[i].RequestOptions.validate(); err != nil {
+			return nil, fmt.Errorf("health group '%s': %w", config.Health[i].Name, err)
+		}
 		for j := range config.Health[i].Endpoints {
-			config.Health[i].Endpoints[j].recoveryMonitorMu = &sync.Mutex{}
+			if err := validateEndpoint(config.Health[i].Endpoints[j].Endpoint); err != nil {
+				return nil, fmt.Errorf("health endpoint '%s' in group '%s': %w", config.Health[i].Endpoints[j].Name, config.Health[i].Name, err)
+			}
+			if err := config.Health[i].Endpoints[j].WebhookOverride.validate(); err != nil {
+				return nil, fmt.Errorf("health endpoint '%s' in group '%s': %w", config.Health[i].Endpoints[j].Name, config.Health[i].Name, err)
+			}
+			if config.Health[i].Endpoints[j].RecoveryInterval < 0 {
+				return nil, fmt.Errorf("recovery_interval must not be negative for health endpoint '%s' in group '%s'", config.Health[i].Endpoints[j].Name, config.Health[i].Name)
+			}
+			if err := config.Health[i].Endpoints[j].Hysteresis.validate(); err != nil {
+				return nil, fmt.Errorf("health endpoint '%s' in group '%s': %w", config.Health[i].Endpoints[j].Name, config.Health[i].Name, err)
+			}
+			if err := config.Health[i].Endpoints[j].RequestMethod.validate(); err != nil {
+				return nil, fmt.Errorf("health endpoint '%s' in group '%s': %w", config.Health[i].Endpoints[j].Name, config.Health[i].Name, err)
+			}
+			if config.Health[i].Endpoints[j].MaxLatencyMs < 0 {
+				return nil, fmt.Errorf("max_latency_ms must not be negative for health endpoint '%s' in group '%s'", config.Health[i].Endpoints[j].Name, config.Health[i].Name)
+			}
+			if expectStatus := config.Health[i].Endpoints[j].ExpectStatus; expectStatus != "" {
+				minStatus, maxStatus, err := parseStatusRange(expectStatus)
+				if err != nil {
+					return nil, fmt.Errorf("expect_status for health endpoint '%s' in group '%s': %w", config.Health[i].Endpoints[j].Name, config.Health[i].Name, err)
+				}
+				config.Health[i].Endpoints[j].expectStatusMin = minStatus
+				config.Health[i].Endpoints[j].expectStatusMax = maxStatus
+			}
+			if config.Health[i].Endpoints[j].HealthPath == "" {
+				config.Health[i].Endpoints[j].HealthPath = defaultHealthPath
+			}
+			if config.Health[i].Endpoints[j].HealthyValue == "" {
+				config.Health[i].Endpoints[j].HealthyValue = defaultHealthyValue
+			}
+			if config.Health[i].Endpoints[j].ErrorPath == "" {
+				config.Health[i].Endpoints[j].ErrorPath = defaultHealthErrorPath
+			}
+			config.Health[i].Endpoints[j].itemState = newItemState()
+			config.Health[i].Endpoints[j].slow = newItemState()
 		}
 	}
 
@@ -251,1114 +837,975 @@ func loadConfig(configPath string) (*Config, error) {
 		if validatorGroup.Name == "" {
 			config.KaspaValidators[i].Name = fmt.Sprintf("Kaspa Validator Group %d", i+1) // Set default name if not provided
 		}
+		if err := validatorGroup.RequestOptions.validate(); err != nil {
+			return nil, fmt.Errorf("Kaspa validator group '%s': %w", config.KaspaValidators[i].Name, err)
+		}
 
 		// Validate each validator within the group
 		for j, validator := range validatorGroup.Validators {
 			if validator.Endpoint == "" {
 				return nil, fmt.Errorf("endpoint is required for Kaspa validator item #%d in group '%s'", j+1, validatorGroup.Name)
 			}
+			if err := validateEndpoint(validator.Endpoint); err != nil {
+				return nil, fmt.Errorf("Kaspa validator item #%d in group '%s': %w", j+1, validatorGroup.Name, err)
+			}
 			if validator.Name == "" {
 				config.KaspaValidators[i].Validators[j].Name = fmt.Sprintf("Kaspa Validator %d", j+1) // Set default name if not provided
 			}
-			// Initialize mutex for recovery monitoring
-			config.KaspaValidators[i].Validators[j].recoveryMonitorMu = &sync.Mutex{}
+			if err := validator.WebhookOverride.validate(); err != nil {
+				return nil, fmt.Errorf("Kaspa validator '%s' in group '%s': %w", validator.Endpoint, validatorGroup.Name, err)
+			}
+			if validator.RecoveryInterval < 0 {
+				return nil, fmt.Errorf("recovery_interval must not be negative for Kaspa validator '%s' in group '%s'", validator.Endpoint, validatorGroup.Name)
+			}
+			if err := config.KaspaValidators[i].Validators[j].RequestMethod.validate(); err != nil {
+				return nil, fmt.Errorf("Kaspa validator '%s' in group '%s': %w", validator.Endpoint, validatorGroup.Name, err)
+			}
+			if path, _, _ := strings.Cut(validator.ExpectJSONField, "="); validator.ExpectJSONField != "" && path == "" {
+				return nil, fmt.Errorf("expect_json_field of Kaspa validator '%s' in group '%s' must start with a JSON path", validator.Endpoint, validatorGroup.Name)
+			}
+			config.KaspaValidators[i].Validators[j].itemState = newItemState()
 		}
 	}
 
-	return &config, nil
-}
+	if err := validateHeaderChecks(&config); err != nil {
+		return nil, err
+	}
 
-func getBalance(restEndpoint, address string) (*BalanceResponse, error) {
-	balanceURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s", restEndpoint, address)
+	if err := validateGrantChecks(&config); err != nil {
+		return nil, err
+	}
 
-	resp, err := http.Get(balanceURL)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+	if err := validateEVMAddresses(&config); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+	if err := validateBTCAddresses(&config); err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status code %d: %s", resp.StatusCode, string(body))
+	if err := validateBlockHeights(&config); err != nil {
+		return nil, err
 	}
 
-	var balanceResp BalanceResponse
-	if err := json.Unmarshal(body, &balanceResp); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	if err := validateTCPChecks(&config); err != nil {
+		return nil, err
 	}
 
-	return &balanceResp, nil
+	if err := validateGRPCHealthChecks(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validateCertChecks(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validateDNSChecks(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validateCosmosValidators(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validatePeerChecks(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validateSyncChecks(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validateCheckIntervals(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validateSilences(&config); err != nil {
+		return nil, err
+	}
+
+	if err := validateTemplates(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
 }
 
-func getKaspaBalance(restEndpoint, address string) (*KaspaBalanceResponse, error) {
-	balanceURL := fmt.Sprintf("%s/addresses/%s/balance", restEndpoint, address)
+// getBalance fetches the balances of an address from the item's balance_path,
+// the bank balances by default
+func getBalance(ctx context.Context, restEndpoint, address, balancePath string, opts RequestOptions) (*BalanceResponse, error) {
+	queryURL, err := balanceURL(restEndpoint, balancePath, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts with many denominations are split over pages, follow the
+	// next_key of each page so a denomination on a later page isn't missed
+	var coins []Balance
+	pageKeys := make(map[string]bool)
+	for page := 1; ; page++ {
+		resp, err := httpGet(ctx, queryURL, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("API returned %w", unexpectedStatus(resp))
+		}
+
+		var balanceResp BalanceResponse
+		if err := json.Unmarshal(resp.Body, &balanceResp); err != nil {
+			return nil, fmt.Errorf("error parsing response: %w", err)
+		}
+		coins = append(coins, balanceResp.Balances...)
+
+		var pagination balancePage
+		if err := json.Unmarshal(resp.Body, &pagination); err != nil {
+			return nil, fmt.Errorf("error parsing response: %w", err)
+		}
+		if pagination.Pagination == nil || pagination.Pagination.NextKey == "" {
+			break
+		}
+		nextKey := pagination.Pagination.NextKey
+		if pageKeys[nextKey] || page == maxBalancePages {
+			return nil, fmt.Errorf("balance query didn't finish after %d pages", page)
+		}
+		pageKeys[nextKey] = true
+
+		if queryURL, err = pageURL(queryURL, nextKey); err != nil {
+			return nil, err
+		}
+	}
+
+	balances, err := sumCoins(coins)
+	if err != nil {
+		return nil, err
+	}
+	return &BalanceResponse{Balances: balances}, nil
+}
 
-	resp, err := http.Get(balanceURL)
+func getKaspaBalance(ctx context.Context, restEndpoint, address string, opts RequestOptions) (*KaspaBalanceResponse, error) {
+	balanceURL, err := joinEndpoint(restEndpoint, "/addresses/"+address+"/balance")
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	resp, err := httpGet(ctx, balanceURL, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status code %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API returned %w", unexpectedStatus(resp))
 	}
 
 	var balanceResp KaspaBalanceResponse
-	if err := json.Unmarshal(body, &balanceResp); err != nil {
+	if err := json.Unmarshal(resp.Body, &balanceResp); err != nil {
 		return nil, fmt.Errorf("error parsing response: %w", err)
 	}
 
 	return &balanceResp, nil
 }
 
-func getMetricValue(endpoint, metricName string) (float64, error) {
-	resp, err := http.Get(endpoint)
+func getMetricValue(ctx context.Context, endpoint string, request RequestMethod, metricName string, labels map[string]string, opts RequestOptions) (float64, error) {
+	resp, err := httpRequest(ctx, endpoint, request, opts)
 	if err != nil {
-		return 0, fmt.Errorf("error fetching metrics: %v", err)
+		return 0, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error reading response: %v", err)
-	}
-
-	// Split the response into lines and find the metric
-	lines := strings.Split(string(body), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, metricName+" ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				value, err := strconv.ParseFloat(parts[1], 64)
-				if err != nil {
-					return 0, fmt.Errorf("error parsing metric value: %v", err)
-				}
-				return value, nil
-			}
-		}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("metrics endpoint returned %w", &statusError{StatusCode: resp.StatusCode})
 	}
 
-	return 0, fmt.Errorf("metric %s not found", metricName)
+	return findMetricValue(string(resp.Body), metricName, labels)
 }
 
-func checkHealth(endpoint string) (*HealthResponse, error) {
-	resp, err := http.Get(endpoint)
+// checkHealth fetches the item's health endpoint and compares the value at its
+// health_path with the healthy_value
+func checkHealth(ctx context.Context, item *HealthItem, opts RequestOptions) (*HealthResponse, error) {
+	resp, err := httpRequest(ctx, item.Endpoint, item.RequestMethod, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+	// With expect_status only the status code matters, the body can be anything
+	if item.ExpectStatus != "" {
+		if resp.StatusCode < item.expectStatusMin || resp.StatusCode > item.expectStatusMax {
+			return nil, fmt.Errorf("expected status %s, health endpoint returned %w", item.ExpectStatus, unexpectedStatus(resp))
+		}
+		return &HealthResponse{IsHealthy: true}, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("health endpoint returned status code %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("health endpoint returned %w", unexpectedStatus(resp))
 	}
 
-	var healthResp HealthResponse
-	if err := json.Unmarshal(body, &healthResp); err != nil {
+	var data interface{}
+	if err := json.Unmarshal(resp.Body, &data); err != nil {
 		return nil, fmt.Errorf("error parsing health response: %w", err)
 	}
 
+	value, err := lookupJSONPath(data, item.HealthPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading health response: %w", err)
+	}
+
+	healthResp := HealthResponse{IsHealthy: fmt.Sprint(value) == item.HealthyValue}
+	// The error message is optional, most healthy responses don't have one
+	if message, err := lookupJSONPath(data, item.ErrorPath); err == nil && message != nil {
+		healthResp.Error = fmt.Sprint(message)
+	}
+
 	return &healthResp, nil
 }
 
-// pingKaspaValidator sends a GET request to the health endpoint and expects 200 OK
-func pingKaspaValidator(endpoint string) error {
-	resp, err := http.Get(endpoint)
+// parseStatusRange parses a status code such as "200" or a range such as "200-299"
+func parseStatusRange(status string) (int, int, error) {
+	low, high, isRange := strings.Cut(status, "-")
+	minStatus, err := strconv.Atoi(strings.TrimSpace(low))
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return 0, 0, fmt.Errorf("invalid status code %q", status)
+	}
+	maxStatus := minStatus
+	if isRange {
+		if maxStatus, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+			return 0, 0, fmt.Errorf("invalid status code range %q", status)
+		}
+	}
+	if minStatus < 100 || maxStatus > 599 || minStatus > maxStatus {
+		return 0, 0, fmt.Errorf("invalid status code range %q: must be within 100-599", status)
 	}
-	defer resp.Body.Close()
+	return minStatus, maxStatus, nil
+}
+
+// maxBodySnippet is how many characters of an unexpected response body an alert shows
+const maxBodySnippet = 200
 
-	body, err := io.ReadAll(resp.Body)
+// pingKaspaValidator sends a request, GET unless the validator sets a method,
+// to the health endpoint and expects 200 OK, and a body matching the
+// validator's expect_contains and expect_json_field if set
+func pingKaspaValidator(ctx context.Context, item *KaspaValidatorItem, opts RequestOptions) error {
+	resp, err := httpRequest(ctx, item.Endpoint, item.RequestMethod, opts)
 	if err != nil {
-		return fmt.Errorf("error reading response: %w", err)
+		return err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("validator health check returned status code %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("validator health check returned %w", unexpectedStatus(resp))
 	}
 
-	return nil
+	return item.checkBody(resp.Body)
 }
 
-func monitorMetricRecovery(metricConfig *MetricConfig, metricItem *MetricItem, bot *tgbotapi.BotAPI, chatID int64) {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			value, err := getMetricValue(metricConfig.RESTEndpoint, metricItem.Metric)
-			if err != nil {
-				fmt.Printf("[Recovery Monitor] Error getting metric %s: %v\n", metricItem.Metric, err)
-				continue
-			}
+// checkBody checks a 200 response body against expect_contains and
+// expect_json_field, a validator can answer 200 with an error payload
+func (item *KaspaValidatorItem) checkBody(body []byte) error {
+	if item.ExpectContains != "" && !strings.Contains(string(body), item.ExpectContains) {
+		return fmt.Errorf("validator response doesn't contain %q: %s", item.ExpectContains, bodySnippet(body))
+	}
+	if item.ExpectJSONField == "" {
+		return nil
+	}
 
-			// Check if metric has recovered (below threshold)
-			if value < float64(metricItem.Threshold) {
-				metricItem.recoveryMonitorMu.Lock()
-				if metricItem.isUnhealthy {
-					// Metric has recovered
-					metricItem.isUnhealthy = false
-
-					displayName := metricItem.Metric
-					if metricItem.Name != "" {
-						displayName = metricItem.Name
-					}
-
-					stdoutMsg := fmt.Sprintf("[%s] %s (%s) has recovered! Current value: %.2f (Threshold: %d)",
-						metricConfig.Name, displayName, metricItem.Metric, value, metricItem.Threshold)
-
-					telegramMsg := fmt.Sprintf("✅ Recovery: [%s] %s `%s` has recovered!\nCurrent value: %.2f\nThreshold: %d",
-						metricConfig.Name, displayName, metricItem.Metric, value, metricItem.Threshold)
-
-					fmt.Println(telegramMsg)
-
-					if bot != nil {
-						tgMsg := tgbotapi.NewMessage(chatID, telegramMsg)
-						tgMsg.ParseMode = tgbotapi.ModeMarkdown
-						_, err := bot.Send(tgMsg)
-						if err != nil {
-							fmt.Printf("Error sending Telegram recovery message: %v\n", err)
-						}
-					} else {
-						fmt.Println(stdoutMsg)
-					}
-
-					// Stop the recovery monitor
-					metricItem.recoveryMonitorMu.Unlock()
-					return
-				}
-				metricItem.recoveryMonitorMu.Unlock()
-			}
-		case <-metricItem.recoveryMonitorStop:
-			return
-		}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("validator response is not JSON: %s", bodySnippet(body))
 	}
+	path, expected, hasValue := strings.Cut(item.ExpectJSONField, "=")
+	value, err := lookupJSONPath(data, path)
+	if err != nil {
+		return fmt.Errorf("validator response: %w: %s", err, bodySnippet(body))
+	}
+	if hasValue && fmt.Sprint(value) != expected {
+		return fmt.Errorf("validator response has %s = %v, expected %s: %s", path, value, expected, bodySnippet(body))
+	}
+	return nil
 }
 
-func monitorMetric(metricConfig *MetricConfig, bot *tgbotapi.BotAPI, chatID int64, interval time.Duration, globalCooldown int, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	fmt.Printf("Started monitoring metrics group '%s' with %d metrics\n",
-		metricConfig.Name, len(metricConfig.Metrics))
-
-	// Initial check for each metric
-	for i := range metricConfig.Metrics {
-		metricItem := &metricConfig.Metrics[i]
-		value, err := getMetricValue(metricConfig.RESTEndpoint, metricItem.Metric)
-		if err != nil {
-			fmt.Printf("Error getting metric %s: %v\n", metricItem.Metric, err)
-		} else {
-			// Use metric name if provided, otherwise use the metric identifier
-			displayName := metricItem.Metric
-			if metricItem.Name != "" {
-				displayName = metricItem.Name
-			}
-
-			fmt.Printf("[%s] %s (%s): %.2f (Threshold: %d)\n",
-				metricConfig.Name, displayName, metricItem.Metric, value, metricItem.Threshold)
-
-			if value >= float64(metricItem.Threshold) {
-				// Format for stdout
-				stdoutMsg := fmt.Sprintf("[%s] %s (%s) is above threshold, expected: %d, got: %.2f",
-					metricConfig.Name, displayName, metricItem.Metric, metricItem.Threshold, value)
-
-				telegramMsg := fmt.Sprintf("🔴 Alert: [%s] %s `%s` is above threshold\nExpected: %d\nGot: %.2f",
-					metricConfig.Name, displayName, metricItem.Metric, metricItem.Threshold, value)
+// bodySnippet returns the start of a response body to show in an alert
+func bodySnippet(body []byte) string {
+	snippet := []rune(strings.TrimSpace(string(body)))
+	if len(snippet) > maxBodySnippet {
+		return string(snippet[:maxBodySnippet]) + "…"
+	}
+	return string(snippet)
+}
 
-				fmt.Println(telegramMsg)
+// metricCheck checks a metric with its group's endpoint
+type metricCheck struct {
+	group *MetricConfig
+	item  *MetricItem
+}
 
-				if bot != nil {
-					tgMsg := tgbotapi.NewMessage(chatID, telegramMsg)
-					tgMsg.ParseMode = tgbotapi.ModeMarkdown
-					_, err := bot.Send(tgMsg)
-					if err != nil {
-						fmt.Printf("Error sending Telegram message (%s): %v\n", telegramMsg, err)
-					}
-				} else {
-					fmt.Println(stdoutMsg)
-				}
+// Check reads the metric and reports it unhealthy while it breaches the
+// threshold, or, with alert_on_missing, once it has been missing for longer
+// than its grace period
+func (c metricCheck) Check(ctx context.Context) (bool, string, error) {
+	item := c.item
+	value, err := item.fetchValue(ctx, c.group, c.group.RequestOptions)
+	if item.AlertOnMissing && errors.Is(err, errMetricNotFound) {
+		return c.checkMissing()
+	}
+	if err != nil {
+		return false, "", err
+	}
+	item.foundAgain()
 
-				metricItem.lastAlertTime = time.Now()
+	recordMetricValue(c.group.Name, item.displayName(), item.Metric, value)
+	return !item.alerting(value), strconv.FormatFloat(value, 'f', -1, 64), nil
+}
 
-				// Start recovery monitoring if not already started
-				metricItem.recoveryMonitorMu.Lock()
-				if !metricItem.isUnhealthy {
-					metricItem.isUnhealthy = true
-					metricItem.recoveryMonitorStop = make(chan bool)
-					go monitorMetricRecovery(metricConfig, metricItem, bot, chatID)
-				}
-				metricItem.recoveryMonitorMu.Unlock()
-			}
-		}
+// displayName is the metric's name, or its series if it has none
+func (m *MetricItem) displayName() string {
+	if m.Name != "" {
+		return m.Name
 	}
+	return m.series()
+}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// checkGroup implementation for metric groups, run by runMonitor
 
-	for range ticker.C {
-		for i := range metricConfig.Metrics {
-			metricItem := &metricConfig.Metrics[i]
-			value, err := getMetricValue(metricConfig.RESTEndpoint, metricItem.Metric)
-			if err != nil {
-				fmt.Printf("Error getting metric %s: %v\n", metricItem.Metric, err)
-			} else {
-				// Use metric name if provided, otherwise use the metric identifier
-				displayName := metricItem.Metric
-				if metricItem.Name != "" {
-					displayName = metricItem.Name
-				}
+func (c *MetricConfig) checkType() string      { return "metric" }
+func (c *MetricConfig) groupName() string      { return c.Name }
+func (c *MetricConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *MetricConfig) itemCount() int         { return len(c.Metrics) }
 
-				fmt.Printf("[%s] %s (%s): %.2f (Threshold: %d)\n",
-					metricConfig.Name, displayName, metricItem.Metric, value, metricItem.Threshold)
-
-				if value >= float64(metricItem.Threshold) {
-					// Check if enough time has passed since the last alert
-					if time.Since(metricItem.lastAlertTime) >= time.Duration(globalCooldown)*time.Second {
-						// Format for stdout
-						stdoutMsg := fmt.Sprintf("[%s] %s `%s` is above threshold, expected: %d, got: %.2f",
-							metricConfig.Name, displayName, metricItem.Metric, metricItem.Threshold, value)
-
-						telegramMsg := fmt.Sprintf("🔴 Alert: [%s] %s `%s` is above threshold\nExpected: %d\nGot: %.2f",
-							metricConfig.Name, displayName, metricItem.Metric, metricItem.Threshold, value)
-
-						fmt.Println(telegramMsg)
-
-						if bot != nil {
-							tgMsg := tgbotapi.NewMessage(chatID, telegramMsg)
-							tgMsg.ParseMode = tgbotapi.ModeMarkdown
-							_, err := bot.Send(tgMsg)
-							if err != nil {
-								fmt.Printf("Error sending Telegram message (%s): %v\n", telegramMsg, err)
-							}
-						} else {
-							fmt.Println(stdoutMsg)
-						}
-
-						metricItem.lastAlertTime = time.Now()
-					}
-
-					// Start recovery monitoring if not already started
-					metricItem.recoveryMonitorMu.Lock()
-					if !metricItem.isUnhealthy {
-						metricItem.isUnhealthy = true
-						metricItem.recoveryMonitorStop = make(chan bool)
-						go monitorMetricRecovery(metricConfig, metricItem, bot, chatID)
-					}
-					metricItem.recoveryMonitorMu.Unlock()
-				}
-			}
-		}
+func (c *MetricConfig) item(i int) monitoredItem {
+	item := &c.Metrics[i]
+	m := monitoredItem{
+		Checker: metricCheck{group: c, item: item}, state: &item.itemState, name: item.displayName(), target: item.series(),
+		threshold: fmt.Sprintf("%s %d", item.Operator, item.Threshold), keyParts: []string{item.series()},
+		severity: severityWarning, cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+		hysteresis: &item.Hysteresis,
+	}
+	if item.MaxLatencyMs > 0 {
+		m.conditions = append(m.conditions, latencyCondition(&item.itemState, &item.slow, item.MaxLatencyMs))
 	}
+	return m
+}
+
+// addressCheck checks a balance with its group's endpoint
+type addressCheck struct {
+	group    *AddressConfig
+	item     *AddressItem
+	recovery bool // Recovery monitor check, which leaves the max_drop_percent baseline alone
 }
 
-func checkAndNotify(addrGroupConfig *AddressConfig, addrItem *AddressItem, bot *tgbotapi.BotAPI, chatID int64, globalCooldown int) error {
-	balances, err := getBalance(addrGroupConfig.RESTEndpoint, addrItem.Address)
+// Check fetches the balance of the threshold's denomination and reports it
+// unhealthy past the threshold
+func (c addressCheck) Check(ctx context.Context) (bool, string, error) {
+	item := c.item
+	balances, err := getBalance(ctx, c.group.RESTEndpoint, item.Address, item.BalancePath, c.group.RequestOptions)
 	if err != nil {
-		return fmt.Errorf("error checking %s: %w", addrItem.Name, err)
+		return false, "", fmt.Errorf("error checking %s: %w", item.Name, err)
 	}
 
 	if len(balances.Balances) == 0 {
-		return fmt.Errorf("no balances found for %s (%s)", addrItem.Name, addrItem.Address)
+		return false, "", fmt.Errorf("no balances found for %s (%s)", item.Name, item.Address)
 	}
 
-	thresholdAmount := new(big.Int)
-	_, ok := thresholdAmount.SetString(addrItem.Threshold.Amount, 10)
+	thresholdAmount, ok := new(big.Int).SetString(item.Threshold.Amount, 10)
 	if !ok {
-		return fmt.Errorf("invalid threshold amount for %s: %s", addrItem.Name, addrItem.Threshold.Amount)
+		return false, "", fmt.Errorf("invalid threshold amount for %s: %s", item.Name, item.Threshold.Amount)
 	}
 
 	// Find the balance for the specified denomination
 	for _, balance := range balances.Balances {
-		if balance.Denom == addrItem.Threshold.Denom {
-			currentAmount := new(big.Int)
-			_, ok := currentAmount.SetString(balance.Amount, 10)
-			if !ok {
-				return fmt.Errorf("invalid balance amount for %s: %s", addrItem.Name, balance.Amount)
-			}
-
-			// Always print to stdout
-			fmt.Printf("[%s] %s Balance: %s %s (Threshold: %s %s)\n",
-				addrGroupConfig.Name,
-				addrItem.Name,
-				balance.Amount, balance.Denom,
-				addrItem.Threshold.Amount, addrItem.Threshold.Denom)
-
-			if currentAmount.Cmp(thresholdAmount) < 0 {
-				// Check if we're still in cooldown period
-				cooldown := globalCooldown
-				if addrItem.AlertCooldown > 0 {
-					cooldown = addrItem.AlertCooldown
-				}
-
-				if !addrItem.lastAlertTime.IsZero() {
-					timeSinceLastAlert := time.Since(addrItem.lastAlertTime)
-					if timeSinceLastAlert < time.Duration(cooldown)*time.Second {
-						// Still in cooldown, just log to stdout
-						fmt.Printf("[%s] %s Balance still below threshold, but in alert cooldown (%s remaining)\n",
-							addrGroupConfig.Name,
-							addrItem.Name,
-							time.Duration(cooldown)*time.Second-timeSinceLastAlert)
-						return nil
-					}
-				}
-
-				// Format for stdout
-				stdoutMsg := fmt.Sprintf("[%s] %s balance is below threshold! Expected: %s %s, Actual: %s %s",
-					addrGroupConfig.Name,
-					addrItem.Name,
-					addrItem.Threshold.Amount, addrItem.Threshold.Denom,
-					balance.Amount, balance.Denom)
-
-				// Format for Telegram with markdown
-				// Escape special characters in strings to avoid Markdown parsing issues
-
-				telegramMsg := fmt.Sprintf("📉 Alert: [%s] `%s` balance is below threshold!\nAddress: `%s`\nCurrent balance: %s %s\nThreshold: %s %s",
-					addrGroupConfig.Name,
-					addrItem.Name,
-					addrItem.Address,
-					balance.Amount, balance.Denom,
-					addrItem.Threshold.Amount, addrItem.Threshold.Denom)
-
-				fmt.Println(telegramMsg)
-
-				// Only send Telegram message if bot is configured
-				if bot != nil {
-					msg := tgbotapi.NewMessage(chatID, telegramMsg)
-					msg.ParseMode = tgbotapi.ModeMarkdown
-					if _, err := bot.Send(msg); err != nil {
-						// Log the Telegram error but don't stop monitoring
-						fmt.Printf("Warning: Failed to send Telegram message: %v\n", err)
-					}
-				}
-				// Always print to stdout
-				fmt.Println(stdoutMsg)
-
-				// Update last alert time
-				addrItem.lastAlertTime = time.Now()
-			}
-			return nil
+		if balance.Denom != item.Threshold.Denom {
+			continue
 		}
-	}
-
-	return fmt.Errorf("denomination %s not found in balances for %s", addrItem.Threshold.Denom, addrItem.Name)
-}
-
-func monitorHealthRecovery(healthConfig *HealthConfig, healthItem *HealthItem, bot *tgbotapi.BotAPI, chatID int64) {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			healthResp, err := checkHealth(healthItem.Endpoint)
-
-			// Check if health has recovered (no error and isHealthy is true)
-			if err == nil && healthResp.Result.IsHealthy {
-				healthItem.recoveryMonitorMu.Lock()
-				if healthItem.isUnhealthy {
-					// Health has recovered
-					healthItem.isUnhealthy = false
-
-					stdoutMsg := fmt.Sprintf("[%s] %s has recovered! Health is now: %v",
-						healthConfig.Name, healthItem.Name, healthResp.Result.IsHealthy)
-
-					telegramMsg := fmt.Sprintf("✅ Recovery: [%s] `%s` has recovered!\nEndpoint: `%s`\nHealth is now: %v",
-						healthConfig.Name, healthItem.Name, healthItem.Endpoint, healthResp.Result.IsHealthy)
-
-					fmt.Println(telegramMsg)
-
-					if bot != nil {
-						tgMsg := tgbotapi.NewMessage(chatID, telegramMsg)
-						tgMsg.ParseMode = tgbotapi.ModeMarkdown
-						_, sendErr := bot.Send(tgMsg)
-						if sendErr != nil {
-							fmt.Printf("Error sending Telegram recovery message: %v\n", sendErr)
-						}
-					} else {
-						fmt.Println(stdoutMsg)
-					}
-
-					// Stop the recovery monitor
-					healthItem.recoveryMonitorMu.Unlock()
-					return
-				}
-				healthItem.recoveryMonitorMu.Unlock()
-			}
-		case <-healthItem.recoveryMonitorStop:
-			return
+		currentAmount, ok := new(big.Int).SetString(balance.Amount, 10)
+		if !ok {
+			return false, "", fmt.Errorf("invalid balance amount for %s: %s", item.Name, balance.Amount)
 		}
-	}
-}
 
-func monitorKaspaValidatorRecovery(validatorConfig *KaspaValidatorConfig, validatorItem *KaspaValidatorItem, bot *tgbotapi.BotAPI, chatID int64) {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			err := pingKaspaValidator(validatorItem.Endpoint)
-
-			// Check if validator has recovered (no error means healthy)
-			if err == nil {
-				validatorItem.recoveryMonitorMu.Lock()
-				if validatorItem.isUnhealthy {
-					// Validator has recovered
-					validatorItem.isUnhealthy = false
-					validatorItem.unhealthySince = time.Time{} // Reset unhealthy tracking
-					alertWasSent := validatorItem.alertSent
-					validatorItem.alertSent = false
-
-					// Only send recovery message if an alert was previously sent
-					if alertWasSent {
-						stdoutMsg := fmt.Sprintf("[%s] %s has recovered! Validator is now responding",
-							validatorConfig.Name, validatorItem.Name)
-
-						telegramMsg := fmt.Sprintf("✅ Recovery: [%s] `%s` has recovered!\nEndpoint: `%s`",
-							validatorConfig.Name, validatorItem.Name, validatorItem.Endpoint)
-
-						fmt.Println(telegramMsg)
-
-						if bot != nil {
-							tgMsg := tgbotapi.NewMessage(chatID, telegramMsg)
-							tgMsg.ParseMode = tgbotapi.ModeMarkdown
-							_, sendErr := bot.Send(tgMsg)
-							if sendErr != nil {
-								fmt.Printf("Error sending Telegram recovery message: %v\n", sendErr)
-							}
-						} else {
-							fmt.Println(stdoutMsg)
-						}
-					} else {
-						fmt.Printf("[%s] %s recovered before alert delay threshold\n",
-							validatorConfig.Name, validatorItem.Name)
-					}
-
-					// Stop the recovery monitor
-					validatorItem.recoveryMonitorMu.Unlock()
-					return
-				}
-				validatorItem.recoveryMonitorMu.Unlock()
-			}
-		case <-validatorItem.recoveryMonitorStop:
-			return
+		recordBalance("cosmos", c.group.Name, item.Name, item.Address, balance.Denom, currentAmount, thresholdAmount)
+		// Drops are judged between regular checks only
+		if !c.recovery {
+			item.recoveryMonitorMu.Lock()
+			item.balance = currentAmount
+			item.recoveryMonitorMu.Unlock()
 		}
+		return !breachesThreshold(currentAmount, thresholdAmount, item.Direction), item.Threshold.format(balance.Amount), nil
 	}
-}
-
-func checkAndNotifyKaspaValidator(validatorConfig *KaspaValidatorConfig, validatorItem *KaspaValidatorItem, bot *tgbotapi.BotAPI, chatID int64, globalCooldown int) error {
-	err := pingKaspaValidator(validatorItem.Endpoint)
-	if err != nil {
-		validatorItem.recoveryMonitorMu.Lock()
-
-		// Track when validator first became unhealthy
-		if validatorItem.unhealthySince.IsZero() {
-			validatorItem.unhealthySince = time.Now()
-			fmt.Printf("[%s] %s validator ping failed, starting alert delay timer: %v\n",
-				validatorConfig.Name,
-				validatorItem.Name, err)
-		}
-
-		// Check if alert delay has passed
-		alertDelay := time.Duration(validatorConfig.AlertDelay) * time.Second
-		unhealthyDuration := time.Since(validatorItem.unhealthySince)
-
-		if alertDelay > 0 && unhealthyDuration < alertDelay {
-			// Still within alert delay period, don't send alert yet
-			fmt.Printf("[%s] %s validator ping failed, waiting for alert delay (%s remaining): %v\n",
-				validatorConfig.Name,
-				validatorItem.Name,
-				alertDelay-unhealthyDuration,
-				err)
-
-			// Start recovery monitoring if not already started
-			if !validatorItem.isUnhealthy {
-				validatorItem.isUnhealthy = true
-				validatorItem.recoveryMonitorStop = make(chan bool)
-				go monitorKaspaValidatorRecovery(validatorConfig, validatorItem, bot, chatID)
-			}
-			validatorItem.recoveryMonitorMu.Unlock()
-			return nil
-		}
-
-		// Alert delay has passed (or no delay configured), check cooldown
-		cooldown := globalCooldown
-		if validatorItem.AlertCooldown > 0 {
-			cooldown = validatorItem.AlertCooldown
-		}
-
-		// Check if we already sent an alert and are in cooldown
-		if validatorItem.alertSent && !validatorItem.lastAlertTime.IsZero() {
-			timeSinceLastAlert := time.Since(validatorItem.lastAlertTime)
-			if timeSinceLastAlert < time.Duration(cooldown)*time.Second {
-				// Still in cooldown, just log to stdout
-				fmt.Printf("[%s] %s validator ping failed, but in alert cooldown (%s remaining)\n",
-					validatorConfig.Name,
-					validatorItem.Name,
-					time.Duration(cooldown)*time.Second-timeSinceLastAlert)
-
-				// Start recovery monitoring if not already started
-				if !validatorItem.isUnhealthy {
-					validatorItem.isUnhealthy = true
-					validatorItem.recoveryMonitorStop = make(chan bool)
-					go monitorKaspaValidatorRecovery(validatorConfig, validatorItem, bot, chatID)
-				}
-				validatorItem.recoveryMonitorMu.Unlock()
-				return nil
-			}
-		}
 
-		// Format for stdout
-		stdoutMsg := fmt.Sprintf("[%s] %s validator ping failed (unhealthy for %s): %v",
-			validatorConfig.Name,
-			validatorItem.Name,
-			unhealthyDuration.Round(time.Second),
-			err)
-
-		// Format for Telegram with markdown
-		telegramMsg := fmt.Sprintf("🚨 Alert: [%s] `%s` validator is unavailable!\nEndpoint: `%s`\nUnhealthy for: %s\nError: %v",
-			validatorConfig.Name,
-			validatorItem.Name,
-			validatorItem.Endpoint,
-			unhealthyDuration.Round(time.Second),
-			err)
-
-		fmt.Println(telegramMsg)
-
-		// Only send Telegram message if bot is configured
-		if bot != nil {
-			msg := tgbotapi.NewMessage(chatID, telegramMsg)
-			msg.ParseMode = tgbotapi.ModeMarkdown
-			if _, err := bot.Send(msg); err != nil {
-				// Log the Telegram error but don't stop monitoring
-				fmt.Printf("Warning: Failed to send Telegram message: %v\n", err)
-			}
-		}
-		// Always print to stdout
-		fmt.Println(stdoutMsg)
+	return false, "", fmt.Errorf("denomination %s not found in balances for %s", item.Threshold.Denom, item.Name)
+}
 
-		// Update last alert time and mark alert as sent
-		validatorItem.lastAlertTime = time.Now()
-		validatorItem.alertSent = true
+// checkGroup implementation for address groups, run by runMonitor
 
-		// Start recovery monitoring if not already started
-		if !validatorItem.isUnhealthy {
-			validatorItem.isUnhealthy = true
-			validatorItem.recoveryMonitorStop = make(chan bool)
-			go monitorKaspaValidatorRecovery(validatorConfig, validatorItem, bot, chatID)
-		}
-		validatorItem.recoveryMonitorMu.Unlock()
+func (c *AddressConfig) checkType() string      { return "balance" }
+func (c *AddressConfig) groupName() string      { return c.Name }
+func (c *AddressConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *AddressConfig) itemCount() int         { return len(c.Addresses) }
 
-		return nil
+func (c *AddressConfig) item(i int) monitoredItem {
+	item := &c.Addresses[i]
+	m := monitoredItem{
+		Checker: addressCheck{group: c, item: item}, recoveryChecker: addressCheck{group: c, item: item, recovery: true},
+		state: &item.itemState, name: item.Name, target: item.Address,
+		threshold: item.Direction + " " + item.Threshold.format(item.Threshold.Amount), link: explorerLine(c.ExplorerURL, item.Address),
+		keyParts: []string{item.Address}, severity: severityWarning,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
 	}
-
-	// Validator is healthy - reset unhealthy tracking if it was set
-	validatorItem.recoveryMonitorMu.Lock()
-	if !validatorItem.unhealthySince.IsZero() {
-		validatorItem.unhealthySince = time.Time{}
-		validatorItem.alertSent = false
+	if item.MaxDropPercent > 0 {
+		m.conditions = append(m.conditions, item.dropCondition())
 	}
-	validatorItem.recoveryMonitorMu.Unlock()
-
-	// Always print to stdout when healthy
-	fmt.Printf("[%s] %s Validator: OK (Endpoint: %s)\n",
-		validatorConfig.Name,
-		validatorItem.Name,
-		validatorItem.Endpoint)
+	return m
+}
 
-	return nil
+// healthCheck checks a health endpoint
+type healthCheck struct {
+	group *HealthConfig
+	item  *HealthItem
 }
 
-func checkAndNotifyHealth(healthConfig *HealthConfig, healthItem *HealthItem, bot *tgbotapi.BotAPI, chatID int64, globalCooldown int) error {
-	healthResp, err := checkHealth(healthItem.Endpoint)
+// Check fetches the health endpoint and reports its health, with the
+// endpoint's error message if it has one
+func (c healthCheck) Check(ctx context.Context) (bool, string, error) {
+	healthResp, err := checkHealth(ctx, c.item, c.group.RequestOptions)
 	if err != nil {
-		// Check if we're still in cooldown period
-		if !healthItem.lastAlertTime.IsZero() {
-			timeSinceLastAlert := time.Since(healthItem.lastAlertTime)
-			if timeSinceLastAlert < time.Duration(globalCooldown)*time.Second {
-				// Still in cooldown, just log to stdout
-				fmt.Printf("[%s] %s health check failed, but in alert cooldown (%s remaining)\n",
-					healthConfig.Name,
-					healthItem.Name,
-					time.Duration(globalCooldown)*time.Second-timeSinceLastAlert)
-				return nil
-			}
-		}
-
-		// Format for stdout
-		stdoutMsg := fmt.Sprintf("[%s] %s health check failed: %v",
-			healthConfig.Name,
-			healthItem.Name, err)
-
-		// Format for Telegram with markdown
-		telegramMsg := fmt.Sprintf("🚨 Alert: [%s] `%s` health check failed!\nEndpoint: `%s`\nError: %v",
-			healthConfig.Name,
-			healthItem.Name,
-			healthItem.Endpoint, err)
-
-		fmt.Println(telegramMsg)
-
-		// Only send Telegram message if bot is configured
-		if bot != nil {
-			msg := tgbotapi.NewMessage(chatID, telegramMsg)
-			msg.ParseMode = tgbotapi.ModeMarkdown
-			if _, err := bot.Send(msg); err != nil {
-				// Log the Telegram error but don't stop monitoring
-				fmt.Printf("Warning: Failed to send Telegram message: %v\n", err)
-			}
-		}
-		// Always print to stdout
-		fmt.Println(stdoutMsg)
+		return false, "", err
+	}
+	if !healthResp.IsHealthy && healthResp.Error != "" {
+		return false, fmt.Sprintf("%s (%s)", healthValue(false), healthResp.Error), nil
+	}
+	return healthResp.IsHealthy, healthValue(healthResp.IsHealthy), nil
+}
 
-		// Update last alert time
-		healthItem.lastAlertTime = time.Now()
+// checkGroup implementation for health groups, run by runMonitor
 
-		// Start recovery monitoring if not already started
-		healthItem.recoveryMonitorMu.Lock()
-		if !healthItem.isUnhealthy {
-			healthItem.isUnhealthy = true
-			healthItem.recoveryMonitorStop = make(chan bool)
-			go monitorHealthRecovery(healthConfig, healthItem, bot, chatID)
-		}
-		healthItem.recoveryMonitorMu.Unlock()
+func (c *HealthConfig) checkType() string      { return "health" }
+func (c *HealthConfig) groupName() string      { return c.Name }
+func (c *HealthConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *HealthConfig) itemCount() int         { return len(c.Endpoints) }
 
-		return nil
+func (c *HealthConfig) item(i int) monitoredItem {
+	item := &c.Endpoints[i]
+	m := monitoredItem{
+		Checker: healthCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Endpoint,
+		severity: severityCritical, cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+		hysteresis: &item.Hysteresis,
+	}
+	if item.MaxLatencyMs > 0 {
+		m.conditions = append(m.conditions, latencyCondition(&item.itemState, &item.slow, item.MaxLatencyMs))
 	}
+	return m
+}
 
-	// Always print to stdout
-	fmt.Printf("[%s] %s Health: %v (Endpoint: %s)\n",
-		healthConfig.Name,
-		healthItem.Name,
-		healthResp.Result.IsHealthy,
-		healthItem.Endpoint)
-
-	// Check if health is not true
-	if !healthResp.Result.IsHealthy {
-		// Check if we're still in cooldown period
-		if !healthItem.lastAlertTime.IsZero() {
-			timeSinceLastAlert := time.Since(healthItem.lastAlertTime)
-			if timeSinceLastAlert < time.Duration(globalCooldown)*time.Second {
-				// Still in cooldown, just log to stdout
-				fmt.Printf("[%s] %s health is unhealthy, but in alert cooldown (%s remaining)\n",
-					healthConfig.Name,
-					healthItem.Name,
-					time.Duration(globalCooldown)*time.Second-timeSinceLastAlert)
-				return nil
-			}
-		}
+// kaspaValidatorCheck checks a Kaspa validator's health endpoint
+type kaspaValidatorCheck struct {
+	group *KaspaValidatorConfig
+	item  *KaspaValidatorItem
+}
 
-		// Format for stdout
-		stdoutMsg := fmt.Sprintf("[%s] %s health is unhealthy! isHealthy: %v, error: %s",
-			healthConfig.Name,
-			healthItem.Name,
-			healthResp.Result.IsHealthy,
-			healthResp.Result.Error)
-
-		// Format for Telegram with markdown
-		telegramMsg := fmt.Sprintf("⚠️ Alert: [%s] `%s` health is unhealthy!\nEndpoint: `%s`\nIsHealthy: %v\nError: %s",
-			healthConfig.Name,
-			healthItem.Name,
-			healthItem.Endpoint,
-			healthResp.Result.IsHealthy,
-			healthResp.Result.Error)
-
-		fmt.Println(telegramMsg)
-
-		// Only send Telegram message if bot is configured
-		if bot != nil {
-			msg := tgbotapi.NewMessage(chatID, telegramMsg)
-			msg.ParseMode = tgbotapi.ModeMarkdown
-			if _, err := bot.Send(msg); err != nil {
-				// Log the Telegram error but don't stop monitoring
-				fmt.Printf("Warning: Failed to send Telegram message: %v\n", err)
-			}
-		}
-		// Always print to stdout
-		fmt.Println(stdoutMsg)
+// Check pings the validator, which is healthy when it answers as expected
+func (c kaspaValidatorCheck) Check(ctx context.Context) (bool, string, error) {
+	err := pingKaspaValidator(ctx, c.item, c.group.RequestOptions)
+	return err == nil, healthValue(err == nil), err
+}
 
-		// Update last alert time
-		healthItem.lastAlertTime = time.Now()
+// checkGroup implementation for Kaspa validator groups, run by runMonitor
 
-		// Start recovery monitoring if not already started
-		healthItem.recoveryMonitorMu.Lock()
-		if !healthItem.isUnhealthy {
-			healthItem.isUnhealthy = true
-			healthItem.recoveryMonitorStop = make(chan bool)
-			go monitorHealthRecovery(healthConfig, healthItem, bot, chatID)
-		}
-		healthItem.recoveryMonitorMu.Unlock()
+func (c *KaspaValidatorConfig) checkType() string      { return "kaspa_validator" }
+func (c *KaspaValidatorConfig) groupName() string      { return c.Name }
+func (c *KaspaValidatorConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *KaspaValidatorConfig) itemCount() int         { return len(c.Validators) }
+
+func (c *KaspaValidatorConfig) item(i int) monitoredItem {
+	item := &c.Validators[i]
+	return monitoredItem{
+		Checker: kaspaValidatorCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Endpoint,
+		severity: severityCritical, cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+		alertDelay: time.Duration(c.AlertDelay) * time.Second,
 	}
+}
 
-	return nil
+// kaspaAddressCheck checks a Kaspa balance with its group's endpoint
+type kaspaAddressCheck struct {
+	group *KaspaAddressConfig
+	item  *KaspaAddressItem
 }
 
-func checkAndNotifyKaspa(kaspaGroupConfig *KaspaAddressConfig, kaspaItem *KaspaAddressItem, bot *tgbotapi.BotAPI, chatID int64, globalCooldown int) error {
-	balanceResp, err := getKaspaBalance(kaspaGroupConfig.RESTEndpoint, kaspaItem.Address)
+// Check fetches the balance and reports it unhealthy past the threshold
+func (c kaspaAddressCheck) Check(ctx context.Context) (bool, string, error) {
+	balanceResp, err := getKaspaBalance(ctx, c.group.RESTEndpoint, c.item.Address, c.group.RequestOptions)
 	if err != nil {
-		return fmt.Errorf("error checking %s: %w", kaspaItem.Name, err)
+		return false, "", fmt.Errorf("error checking %s: %w", c.item.Name, err)
 	}
 
-	thresholdAmount := new(big.Int)
-	_, ok := thresholdAmount.SetString(kaspaItem.Threshold, 10)
+	thresholdAmount, ok := new(big.Int).SetString(c.item.Threshold, 10)
 	if !ok {
-		return fmt.Errorf("invalid threshold amount for %s: %s", kaspaItem.Name, kaspaItem.Threshold)
+		return false, "", fmt.Errorf("invalid threshold amount for %s: %s", c.item.Name, c.item.Threshold)
 	}
 
 	currentAmount := big.NewInt(balanceResp.Balance)
+	recordBalance("kaspa", c.group.Name, c.item.Name, c.item.Address, "sompi", currentAmount, thresholdAmount)
+	return !breachesThreshold(currentAmount, thresholdAmount, c.item.Direction), c.item.format(currentAmount.String()), nil
+}
 
-	// Always print to stdout
-	fmt.Printf("[%s] %s Kaspa Balance: %d sompi (Threshold: %s sompi)\n",
-		kaspaGroupConfig.Name,
-		kaspaItem.Name,
-		balanceResp.Balance,
-		kaspaItem.Threshold)
-
-	if currentAmount.Cmp(thresholdAmount) < 0 {
-		// Check if we're still in cooldown period
-		cooldown := globalCooldown
-		if kaspaItem.AlertCooldown > 0 {
-			cooldown = kaspaItem.AlertCooldown
-		}
-
-		if !kaspaItem.lastAlertTime.IsZero() {
-			timeSinceLastAlert := time.Since(kaspaItem.lastAlertTime)
-			if timeSinceLastAlert < time.Duration(cooldown)*time.Second {
-				// Still in cooldown, just log to stdout
-				fmt.Printf("[%s] %s Kaspa balance still below threshold, but in alert cooldown (%s remaining)\n",
-					kaspaGroupConfig.Name,
-					kaspaItem.Name,
-					time.Duration(cooldown)*time.Second-timeSinceLastAlert)
-				return nil
-			}
-		}
+// checkGroup implementation for Kaspa address groups, run by runMonitor
 
-		// Format for stdout
-		stdoutMsg := fmt.Sprintf("[%s] %s Kaspa balance is below threshold! Expected: %s sompi, Actual: %d sompi",
-			kaspaGroupConfig.Name,
-			kaspaItem.Name,
-			kaspaItem.Threshold,
-			balanceResp.Balance)
-
-		// Format for Telegram with markdown
-		telegramMsg := fmt.Sprintf("📉 Alert: [%s] `%s` Kaspa balance is below threshold!\nAddress: `%s`\nCurrent balance: %d sompi\nThreshold: %s sompi",
-			kaspaGroupConfig.Name,
-			kaspaItem.Name,
-			kaspaItem.Address,
-			balanceResp.Balance,
-			kaspaItem.Threshold)
-
-		fmt.Println(telegramMsg)
-
-		// Only send Telegram message if bot is configured
-		if bot != nil {
-			msg := tgbotapi.NewMessage(chatID, telegramMsg)
-			msg.ParseMode = tgbotapi.ModeMarkdown
-			if _, err := bot.Send(msg); err != nil {
-				// Log the Telegram error but don't stop monitoring
-				fmt.Printf("Warning: Failed to send Telegram message: %v\n", err)
-			}
-		}
-		// Always print to stdout
-		fmt.Println(stdoutMsg)
+func (c *KaspaAddressConfig) checkType() string      { return "kaspa_balance" }
+func (c *KaspaAddressConfig) groupName() string      { return c.Name }
+func (c *KaspaAddressConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *KaspaAddressConfig) itemCount() int         { return len(c.Addresses) }
 
-		// Update last alert time
-		kaspaItem.lastAlertTime = time.Now()
+func (c *KaspaAddressConfig) item(i int) monitoredItem {
+	item := &c.Addresses[i]
+	return monitoredItem{
+		Checker: kaspaAddressCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Address,
+		threshold: item.Direction + " " + item.format(item.Threshold), link: explorerLine(c.ExplorerURL, item.Address),
+		keyParts: []string{item.Address}, severity: severityWarning,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
 	}
-
-	return nil
 }
 
-func monitorKaspaAddressGroup(kaspaGroupConfig *KaspaAddressConfig, bot *tgbotapi.BotAPI, chatID int64, interval time.Duration, globalCooldown int, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	fmt.Printf("Started monitoring Kaspa address group '%s' with %d addresses\n",
-		kaspaGroupConfig.Name, len(kaspaGroupConfig.Addresses))
-
-	// Initial check for each address
-	for i := range kaspaGroupConfig.Addresses {
-		kaspaItem := &kaspaGroupConfig.Addresses[i]
-		if err := checkAndNotifyKaspa(kaspaGroupConfig, kaspaItem, bot, chatID, globalCooldown); err != nil {
-			fmt.Printf("Error checking %s: %v\n", kaspaItem.Name, err)
-		}
-	}
+// maxConcurrency limits how many items of a group are checked at once, set
+// from the max_concurrency config setting at startup
+var maxConcurrency = 8
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// recoveryInterval is how often recovery monitors re-check an unhealthy item,
+// set from the recovery_interval config setting at startup
+var recoveryInterval = 5 * time.Second
 
-	for range ticker.C {
-		for i := range kaspaGroupConfig.Addresses {
-			kaspaItem := &kaspaGroupConfig.Addresses[i]
-			if err := checkAndNotifyKaspa(kaspaGroupConfig, kaspaItem, bot, chatID, globalCooldown); err != nil {
-				fmt.Printf("Error checking %s: %v\n", kaspaItem.Name, err)
-			}
-		}
-	}
+// groupInterval is the check_interval override of a group
+type groupInterval struct {
+	section  string
+	group    string
+	interval Seconds
 }
 
-func monitorAddressGroup(addrGroupConfig *AddressConfig, bot *tgbotapi.BotAPI, chatID int64, interval time.Duration, globalCooldown int, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	fmt.Printf("Started monitoring address group '%s' with %d addresses\n",
-		addrGroupConfig.Name, len(addrGroupConfig.Addresses))
-
-	// Initial check for each address
-	for i := range addrGroupConfig.Addresses {
-		addrItem := &addrGroupConfig.Addresses[i]
-		if err := checkAndNotify(addrGroupConfig, addrItem, bot, chatID, globalCooldown); err != nil {
-			fmt.Printf("Error checking %s: %v\n", addrItem.Name, err)
-		}
+// validateCheckIntervals checks that every per-group check_interval override
+// is positive, groups without one use the global check_interval
+func validateCheckIntervals(config *Config) error {
+	var groups []groupInterval
+	for _, g := range config.Metrics {
+		groups = append(groups, groupInterval{"metrics", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.Addresses {
+		groups = append(groups, groupInterval{"addresses", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.KaspaAddresses {
+		groups = append(groups, groupInterval{"kaspa_addresses", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.EVMAddresses {
+		groups = append(groups, groupInterval{"evm_addresses", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.BTCAddresses {
+		groups = append(groups, groupInterval{"btc_addresses", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.Health {
+		groups = append(groups, groupInterval{"health", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.KaspaValidators {
+		groups = append(groups, groupInterval{"kaspa_validators", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.HeaderChecks {
+		groups = append(groups, groupInterval{"header_checks", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.GrantChecks {
+		groups = append(groups, groupInterval{"grant_checks", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.BlockHeight {
+		groups = append(groups, groupInterval{"block_height", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.TCPChecks {
+		groups = append(groups, groupInterval{"tcp_checks", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.GRPCHealth {
+		groups = append(groups, groupInterval{"grpc_health", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.CertChecks {
+		groups = append(groups, groupInterval{"cert_checks", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.DNSChecks {
+		groups = append(groups, groupInterval{"dns_checks", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.CosmosValidators {
+		groups = append(groups, groupInterval{"cosmos_validators", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.PeerChecks {
+		groups = append(groups, groupInterval{"peer_checks", g.Name, g.CheckInterval})
+	}
+	for _, g := range config.SyncChecks {
+		groups = append(groups, groupInterval{"sync_checks", g.Name, g.CheckInterval})
 	}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		for i := range addrGroupConfig.Addresses {
-			addrItem := &addrGroupConfig.Addresses[i]
-			if err := checkAndNotify(addrGroupConfig, addrItem, bot, chatID, globalCooldown); err != nil {
-				fmt.Printf("Error checking %s: %v\n", addrItem.Name, err)
-			}
+	for _, g := range groups {
+		if g.interval < 0 {
+			return fmt.Errorf("check_interval must be positive for %s group '%s', omit it to use the global check_interval", g.section, g.group)
 		}
 	}
+	return nil
 }
 
-func monitorKaspaValidators(validatorConfig *KaspaValidatorConfig, bot *tgbotapi.BotAPI, chatID int64, interval time.Duration, globalCooldown int, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	fmt.Printf("Started monitoring Kaspa validator group '%s' with %d validators\n",
-		validatorConfig.Name, len(validatorConfig.Validators))
-
-	// Initial check for each validator
-	for i := range validatorConfig.Validators {
-		validatorItem := &validatorConfig.Validators[i]
-		if err := checkAndNotifyKaspaValidator(validatorConfig, validatorItem, bot, chatID, globalCooldown); err != nil {
-			fmt.Printf("Error checking Kaspa validator %s: %v\n", validatorItem.Name, err)
-		}
+// recoveryPollInterval returns the recovery check interval of an item given
+// its recovery_interval override in seconds
+func recoveryPollInterval(itemInterval Seconds) time.Duration {
+	if itemInterval > 0 {
+		return time.Duration(itemInterval) * time.Second
 	}
+	return recoveryInterval
+}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// checkJitter is the largest random delay before a group's checks as a
+// fraction of its interval, set from the check_jitter config setting at startup
+var checkJitter = 0.1
 
-	for range ticker.C {
-		for i := range validatorConfig.Validators {
-			validatorItem := &validatorConfig.Validators[i]
-			if err := checkAndNotifyKaspaValidator(validatorConfig, validatorItem, bot, chatID, globalCooldown); err != nil {
-				fmt.Printf("Error checking Kaspa validator %s: %v\n", validatorItem.Name, err)
-			}
-		}
+// sleepJitter waits a random part of the interval, up to checkJitter of it, so
+// groups with the same interval don't hit their endpoints in lockstep
+func sleepJitter(interval time.Duration) {
+	maxDelay := time.Duration(float64(interval) * checkJitter)
+	if maxDelay <= 0 {
+		return
 	}
+	time.Sleep(rand.N(maxDelay))
 }
 
-func monitorHealth(healthConfig *HealthConfig, bot *tgbotapi.BotAPI, chatID int64, interval time.Duration, globalCooldown int, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	fmt.Printf("Started monitoring health group '%s' with %d health endpoints\n",
-		healthConfig.Name, len(healthConfig.Endpoints))
+// checkConcurrently runs check for every item index of a group, at most
+// maxConcurrency at a time, and waits for all of them to finish so the next
+// tick never overlaps with the current one
+func checkConcurrently(n int, check func(i int)) {
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
 
-	// Initial check for each health endpoint
-	for i := range healthConfig.Endpoints {
-		healthItem := &healthConfig.Endpoints[i]
-		if err := checkAndNotifyHealth(healthConfig, healthItem, bot, chatID, globalCooldown); err != nil {
-			fmt.Printf("Error checking health endpoint %s: %v\n", healthItem.Name, err)
-		}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			check(i)
+		}(i)
 	}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	wg.Wait()
+}
 
-	for range ticker.C {
-		for i := range healthConfig.Endpoints {
-			healthItem := &healthConfig.Endpoints[i]
-			if err := checkAndNotifyHealth(healthConfig, healthItem, bot, chatID, globalCooldown); err != nil {
-				fmt.Printf("Error checking health endpoint %s: %v\n", healthItem.Name, err)
-			}
-		}
-	}
+// checkCycle runs one check cycle of a group with checkConcurrently and, when
+// digests are enabled, sends the alerts it raised as a single message
+func checkCycle(notifier *Notifier, itemType, group string, n int, check func(i int)) {
+	d := notifier.startDigest(itemType, group)
+	defer notifier.flushDigest(d)
+
+	checkConcurrently(n, check)
 }
 
 func main() {
 	// Parse command line flags
 	configPath := flag.String("config-path", "", "Path to the config file (default: ./config.yaml)")
+	configDir := flag.String("config-dir", "", "Directory of config files merged into the config, e.g. one per team")
+	testAlert := flag.Bool("test-alert", false, "Send a test alert and recovery to every configured channel and exit")
+	validate := flag.Bool("validate", false, "Validate the config, probe every endpoint once, print a report, and exit")
+	version := flag.Bool("version", false, "Print the build information and exit")
 	flag.Parse()
 
+	if *version {
+		fmt.Println(versionString())
+		return
+	}
+
 	// If a relative path is provided, convert it to absolute
 	if *configPath != "" && !filepath.IsAbs(*configPath) {
 		abs, err := filepath.Abs(*configPath)
 		if err != nil {
-			fmt.Printf("Error resolving config path: %v\n", err)
+			slog.Error("error resolving config path", "error", err)
 			os.Exit(1)
 		}
 		*configPath = abs
 	}
 
-	config, err := loadConfig(*configPath)
+	config, err := loadConfig(*configPath, *configDir)
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
+		slog.Error("error loading config", "error", err)
 		os.Exit(1)
 	}
 
-	// Initialize Telegram bot only if token is provided
-	var bot *tgbotapi.BotAPI
-	if config.Telegram.BotToken != "" {
-		bot, err = tgbotapi.NewBotAPI(config.Telegram.BotToken)
+	// Log to stdout, and to the log file if one is configured
+	logOutput := io.Writer(os.Stdout)
+	if config.LogFile != "" {
+		logFile, err := newRotatingFile(config.LogFile, int64(config.LogMaxSize)<<20, config.LogMaxBackups, time.Duration(config.LogMaxAge)*24*time.Hour)
 		if err != nil {
-			fmt.Printf("Warning: Failed to initialize Telegram bot: %v\n", err)
-			fmt.Println("Continuing in stdout-only mode")
-			bot = nil
-		} else {
-			// Test the connection by sending a startup message
-			startupMsg := tgbotapi.NewMessage(config.Telegram.ChatID, "🚀 Monitor started")
-			if _, err := bot.Send(startupMsg); err != nil {
-				fmt.Printf("Warning: Failed to send test message to Telegram: %v\n", err)
-				fmt.Println("Please make sure you have started a chat with the bot and the chat ID is correct")
-				fmt.Println("Continuing in stdout-only mode")
-				bot = nil
-			} else {
-				fmt.Println("Telegram notifications enabled and tested successfully")
-			}
+			slog.Error("error configuring logging", "error", err)
+			os.Exit(1)
+		}
+		defer logFile.Close()
+		logOutput = io.MultiWriter(os.Stdout, logFile)
+	}
+	logger, err := newLogger(config.LogLevel, config.LogFormat, logOutput)
+	if err != nil {
+		slog.Error("error configuring logging", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+	slog.Info("observability agent", "version", BuildVersion, "commit", BuildCommit, "build_time", BuildTime, "go_version", runtime.Version())
+
+	httpClient.Timeout = time.Duration(config.HTTPTimeout) * time.Second
+	insecureHTTPClient.Timeout = httpClient.Timeout
+	notificationClient.Timeout = httpClient.Timeout
+	insecureSkipVerify = config.InsecureSkipVerify
+	httpRetries = config.HTTPRetries
+	maxConcurrency = config.MaxConcurrency
+	checkJitter = float64(config.CheckJitter) / 100
+	useEmoji = config.UseEmoji
+	thousandsSeparator, decimalSeparator = config.ThousandsSeparator, config.DecimalSeparator
+	maxCheckBackoff = config.CheckBackoff
+	recoveryInterval = time.Duration(config.RecoveryInterval) * time.Second
+	recoveryTimeout = time.Duration(config.RecoveryTimeout) * time.Second
+	httpRetryDelay = time.Duration(config.HTTPRetryDelayMs) * time.Millisecond
+
+	if *validate {
+		// A single probe per item, retries would only hide flaky endpoints
+		httpRetries = 0
+		if !validateEndpoints(config) {
+			os.Exit(1)
 		}
-	} else {
-		fmt.Println("Running in stdout-only mode (no Telegram notifications)")
+		return
 	}
 
-	fmt.Printf("Starting monitor...\n")
-	fmt.Printf("Check interval: %d seconds\n", config.CheckInterval)
+	if *testAlert {
+		if !sendTestAlert(config) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	notifier := newNotifier(startChannels(config), config)
+
+	if config.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification is disabled for every check, do not use this in production")
+	}
+
+	slog.Info("starting monitor",
+		"check_interval", time.Duration(config.CheckInterval)*time.Second,
+		"http_timeout", httpClient.Timeout,
+		"http_retries", config.HTTPRetries,
+		"max_concurrency", config.MaxConcurrency,
+		"recovery_interval", recoveryInterval,
+		"recovery_timeout", recoveryTimeout,
+		"check_jitter_percent", config.CheckJitter,
+		"check_backoff", config.CheckBackoff)
 
 	// Only show addresses section if we have addresses to monitor
-	if len(config.Addresses) > 0 {
-		fmt.Println("\nMonitoring addresses:")
-		for _, addrGroup := range config.Addresses {
-			fmt.Printf("- %s (endpoint: %s)\n", addrGroup.Name, addrGroup.RESTEndpoint)
-			for _, addr := range addrGroup.Addresses {
-				fmt.Printf("  • %s (%s), threshold: %s %s\n",
-					addr.Name, addr.Address, addr.Threshold.Amount, addr.Threshold.Denom)
-			}
+	for _, addrGroup := range config.Addresses {
+		addrGroup.warnInsecure("balance", addrGroup.Name)
+		for _, addr := range addrGroup.Addresses {
+			slog.Info("monitoring address", "type", "balance", "group", addrGroup.Name, "endpoint", addrGroup.RESTEndpoint,
+				"item", addr.Name, "address", addr.Address, "threshold", addr.Threshold.format(addr.Threshold.Amount))
 		}
 	}
 
 	// Only show Kaspa addresses section if we have Kaspa addresses to monitor
-	if len(config.KaspaAddresses) > 0 {
-		fmt.Println("\nMonitoring Kaspa addresses:")
-		for _, kaspaGroup := range config.KaspaAddresses {
-			fmt.Printf("- %s (endpoint: %s)\n", kaspaGroup.Name, kaspaGroup.RESTEndpoint)
-			for _, addr := range kaspaGroup.Addresses {
-				fmt.Printf("  • %s (%s), threshold: %s sompi\n",
-					addr.Name, addr.Address, addr.Threshold)
-			}
+	for _, kaspaGroup := range config.KaspaAddresses {
+		kaspaGroup.warnInsecure("kaspa_balance", kaspaGroup.Name)
+		for _, addr := range kaspaGroup.Addresses {
+			slog.Info("monitoring Kaspa address", "type", "kaspa_balance", "group", kaspaGroup.Name, "endpoint", kaspaGroup.RESTEndpoint,
+				"item", addr.Name, "address", addr.Address, "threshold", addr.format(addr.Threshold))
 		}
 	}
 
 	// Only show metrics section if we have metrics to monitor
-	if len(config.Metrics) > 0 {
-		fmt.Println("\nMonitoring metrics:")
-		for _, metricGroup := range config.Metrics {
-			fmt.Printf("- %s (endpoint: %s)\n", metricGroup.Name, metricGroup.RESTEndpoint)
-			for _, metric := range metricGroup.Metrics {
-				displayName := metric.Metric
-				if metric.Name != "" {
-					displayName = metric.Name
-				}
-				fmt.Printf("  • %s (%s), threshold: %d\n", displayName, metric.Metric, metric.Threshold)
+	for _, metricGroup := range config.Metrics {
+		metricGroup.warnInsecure("metric", metricGroup.Name)
+		for _, metric := range metricGroup.Metrics {
+			displayName := metric.series()
+			if metric.Name != "" {
+				displayName = metric.Name
 			}
+			slog.Info("monitoring metric", "type", "metric", "group", metricGroup.Name, "endpoint", metricGroup.RESTEndpoint,
+				"item", displayName, "metric", metric.series(), "operator", metric.Operator, "threshold", metric.Threshold)
 		}
 	}
 
 	// Only show health section if we have health endpoints to monitor
-	if len(config.Health) > 0 {
-		fmt.Println("\nMonitoring health endpoints:")
-		for _, healthGroup := range config.Health {
-			fmt.Printf("- %s\n", healthGroup.Name)
-			for _, health := range healthGroup.Endpoints {
-				fmt.Printf("  • %s (%s)\n", health.Name, health.Endpoint)
-			}
+	for _, healthGroup := range config.Health {
+		healthGroup.warnInsecure("health", healthGroup.Name)
+		for _, health := range healthGroup.Endpoints {
+			slog.Info("monitoring health endpoint", "type", "health", "group", healthGroup.Name, "item", health.Name, "endpoint", health.Endpoint)
 		}
 	}
 
 	// Only show Kaspa validators section if we have validators to monitor
-	if len(config.KaspaValidators) > 0 {
-		fmt.Println("\nMonitoring Kaspa validators:")
-		for _, validatorGroup := range config.KaspaValidators {
-			fmt.Printf("- %s\n", validatorGroup.Name)
-			for _, validator := range validatorGroup.Validators {
-				fmt.Printf("  • %s (%s)\n", validator.Name, validator.Endpoint)
-			}
+	for _, validatorGroup := range config.KaspaValidators {
+		validatorGroup.warnInsecure("kaspa_validator", validatorGroup.Name)
+		for _, validator := range validatorGroup.Validators {
+			slog.Info("monitoring Kaspa validator", "type", "kaspa_validator", "group", validatorGroup.Name, "item", validator.Name, "endpoint", validator.Endpoint)
 		}
 	}
 
-	// Exit if there's nothing to monitor
-	if len(config.Addresses) == 0 && len(config.KaspaAddresses) == 0 && len(config.Metrics) == 0 && len(config.Health) == 0 && len(config.KaspaValidators) == 0 {
-		fmt.Println("\nError: No addresses, Kaspa addresses, metrics, health endpoints, or Kaspa validators configured to monitor. Please add at least one to your config.")
-		os.Exit(1)
+	// Only show header checks section if we have header checks to monitor
+	for _, headerGroup := range config.HeaderChecks {
+		headerGroup.warnInsecure("header", headerGroup.Name)
+		for _, check := range headerGroup.Checks {
+			slog.Info("monitoring response header", "type", "header", "group", headerGroup.Name, "item", check.Name, "endpoint", check.Endpoint,
+				"header", check.Header, "expected", check.expectation())
+		}
 	}
 
-	var wg sync.WaitGroup
-	globalInterval := time.Duration(config.CheckInterval) * time.Second
+	// Only show grant checks section if we have grants to monitor
+	for _, grantGroup := range config.GrantChecks {
+		grantGroup.warnInsecure("grant", grantGroup.Name)
+		for _, grant := range grantGroup.Grants {
+			slog.Info("monitoring grant", "type", "grant", "group", grantGroup.Name, "endpoint", grantGroup.RESTEndpoint,
+				"item", grant.Name, "grant_type", grant.Type, "grantee", grant.Grantee, "warn_days", grant.warnDays(&grantGroup))
+		}
+	}
 
-	// Start monitoring metrics
-	for i := range config.Metrics {
-		wg.Add(1)
-		interval := globalInterval
-		if config.Metrics[i].CheckInterval > 0 {
-			interval = time.Duration(config.Metrics[i].CheckInterval) * time.Second
+	// Only show EVM addresses section if we have EVM addresses to monitor
+	for _, evmGroup := range config.EVMAddresses {
+		evmGroup.warnInsecure("evm_balance", evmGroup.Name)
+		for _, addr := range evmGroup.Addresses {
+			slog.Info("monitoring EVM address", "type", "evm_balance", "group", evmGroup.Name, "endpoint", evmGroup.RPCEndpoint,
+				"item", addr.Name, "address", addr.Address, "threshold", addr.format(addr.Threshold))
 		}
-		go monitorMetric(&config.Metrics[i], bot, config.Telegram.ChatID, interval, config.AlertCooldown, &wg)
 	}
 
-	// Start monitoring each address group in parallel
-	for i := range config.Addresses {
-		wg.Add(1)
-		interval := globalInterval
-		if config.Addresses[i].CheckInterval > 0 {
-			interval = time.Duration(config.Addresses[i].CheckInterval) * time.Second
+	// Only show BTC addresses section if we have BTC addresses to monitor
+	for _, btcGroup := range config.BTCAddresses {
+		btcGroup.warnInsecure("btc_balance", btcGroup.Name)
+		for _, addr := range btcGroup.Addresses {
+			slog.Info("monitoring BTC address", "type", "btc_balance", "group", btcGroup.Name, "endpoint", btcGroup.RESTEndpoint,
+				"item", addr.Name, "address", addr.Address, "threshold", addr.format(addr.Threshold))
 		}
-		go monitorAddressGroup(&config.Addresses[i], bot, config.Telegram.ChatID, interval, config.AlertCooldown, &wg)
 	}
 
-	// Start monitoring each Kaspa address group in parallel
-	for i := range config.KaspaAddresses {
-		wg.Add(1)
-		interval := globalInterval
-		if config.KaspaAddresses[i].CheckInterval > 0 {
-			interval = time.Duration(config.KaspaAddresses[i].CheckInterval) * time.Second
+	// Only show block height section if we have block heights to monitor
+	for _, heightGroup := range config.BlockHeight {
+		heightGroup.warnInsecure("block_height", heightGroup.Name)
+		for _, endpoint := range heightGroup.Endpoints {
+			slog.Info("monitoring block height", "type", "block_height", "group", heightGroup.Name, "item", endpoint.Name, "endpoint", endpoint.Endpoint,
+				"max_stall_checks", endpoint.maxStallChecks(&heightGroup))
 		}
-		go monitorKaspaAddressGroup(&config.KaspaAddresses[i], bot, config.Telegram.ChatID, interval, config.AlertCooldown, &wg)
 	}
 
-	// Start monitoring health groups in parallel
-	for i := range config.Health {
-		wg.Add(1)
-		interval := globalInterval
-		if config.Health[i].CheckInterval > 0 {
-			interval = time.Duration(config.Health[i].CheckInterval) * time.Second
+	// Only show TCP section if we have TCP checks to monitor
+	for _, tcpGroup := range config.TCPChecks {
+		for _, check := range tcpGroup.Checks {
+			slog.Info("monitoring TCP port", "type", "tcp", "group", tcpGroup.Name, "item", check.Name, "address", check.Address)
+		}
+	}
+
+	// Only show gRPC health section if we have gRPC health checks to monitor
+	for _, grpcGroup := range config.GRPCHealth {
+		for _, check := range grpcGroup.Checks {
+			slog.Info("monitoring gRPC health", "type", "grpc_health", "group", grpcGroup.Name, "item", check.Name, "target", check.Target,
+				"service", check.Service)
+		}
+	}
+
+	// Only show certificate section if we have certificate checks to monitor
+	for _, certGroup := range config.CertChecks {
+		for _, check := range certGroup.Checks {
+			slog.Info("monitoring TLS certificate", "type", "cert", "group", certGroup.Name, "item", check.Name, "address", check.Address,
+				"warn_days", check.warnDays(&certGroup))
+		}
+	}
+
+	// Only show DNS section if we have DNS checks to monitor
+	for _, dnsGroup := range config.DNSChecks {
+		for _, check := range dnsGroup.Checks {
+			slog.Info("monitoring DNS resolution", "type", "dns", "group", dnsGroup.Name, "item", check.Name, "hostname", check.Hostname,
+				"record_type", check.RecordType, "expected", check.Expected)
+		}
+	}
+
+	// Only show cosmos validator section if we have cosmos validators to monitor
+	for _, validatorGroup := range config.CosmosValidators {
+		validatorGroup.warnInsecure("cosmos_validator", validatorGroup.Name)
+		for _, validator := range validatorGroup.Validators {
+			slog.Info("monitoring cosmos validator", "type", "cosmos_validator", "group", validatorGroup.Name, "item", validator.Name,
+				"valoper", validator.Valoper, "max_missed_blocks", validator.maxMissedBlocks(&validatorGroup))
+		}
+	}
+
+	// Only show peer count section if we have peer checks to monitor
+	for _, peerGroup := range config.PeerChecks {
+		peerGroup.warnInsecure("peers", peerGroup.Name)
+		for _, endpoint := range peerGroup.Endpoints {
+			slog.Info("monitoring peer count", "type", "peers", "group", peerGroup.Name, "item", endpoint.Name, "endpoint", endpoint.Endpoint,
+				"min_peers", endpoint.minPeers(&peerGroup))
+		}
+	}
+
+	// Only show sync section if we have sync checks to monitor
+	for _, syncGroup := range config.SyncChecks {
+		syncGroup.warnInsecure("sync", syncGroup.Name)
+		for _, endpoint := range syncGroup.Endpoints {
+			slog.Info("monitoring sync status", "type", "sync", "group", syncGroup.Name, "item", endpoint.Name, "endpoint", endpoint.Endpoint,
+				"grace_period", endpoint.gracePeriod(&syncGroup))
+		}
+	}
+
+	for _, silence := range config.Silences {
+		slog.Info("silence configured", "group", silence.Group, "item", silence.Item, "start", silence.Start, "end", silence.End,
+			"daily_start", silence.DailyStart, "daily_end", silence.DailyEnd, "comment", silence.Comment)
+	}
+	setSilences(config.Silences)
+
+	// Exit if there's nothing to monitor
+	if len(config.Addresses) == 0 && len(config.KaspaAddresses) == 0 && len(config.Metrics) == 0 && len(config.Health) == 0 && len(config.KaspaValidators) == 0 && len(config.HeaderChecks) == 0 && len(config.GrantChecks) == 0 && len(config.EVMAddresses) == 0 && len(config.BTCAddresses) == 0 && len(config.BlockHeight) == 0 && len(config.TCPChecks) == 0 && len(config.GRPCHealth) == 0 && len(config.CertChecks) == 0 && len(config.DNSChecks) == 0 && len(config.CosmosValidators) == 0 && len(config.PeerChecks) == 0 && len(config.SyncChecks) == 0 {
+		slog.Error("No addresses, Kaspa addresses, EVM addresses, BTC addresses, metrics, health endpoints, Kaspa validators, cosmos validators, header checks, grant checks, block heights, peer checks, sync checks, TCP checks, gRPC health checks, certificate checks, or DNS checks configured to monitor. Please add at least one to your config.")
+		os.Exit(1)
+	}
+
+	// Expose the monitored state for scraping if configured
+	if config.MetricsListen != "" {
+		go serveMetrics(config.MetricsListen, notifier)
+	}
+
+	// Post a periodic summary so operators know the agent is alive
+	if config.Heartbeat.enabled() {
+		go sendHeartbeats(config, notifier)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Reload silences on SIGHUP so maintenance windows can change without a restart
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			if err := reloadSilences(); err != nil {
+				slog.Error("error reloading silences", "error", err)
+			}
 		}
-		go monitorHealth(&config.Health[i], bot, config.Telegram.ChatID, interval, config.AlertCooldown, &wg)
+	}()
+
+	// Answer liveness and readiness probes if configured
+	var probes *probeServer
+	if config.HealthListen != "" {
+		probes = newProbeServer(config.HealthListen, monitoredGroups(config), func() []statusItem { return statusItems(config) }, notifier.history)
+		go probes.serve(ctx)
 	}
 
-	// Start monitoring Kaspa validator groups in parallel
-	for i := range config.KaspaValidators {
+	var wg sync.WaitGroup
+	globalInterval := time.Duration(config.CheckInterval) * time.Second
+
+	// Start monitoring each group in parallel
+	for _, c := range groupCheckers(config) {
 		wg.Add(1)
 		interval := globalInterval
-		if config.KaspaValidators[i].CheckInterval > 0 {
-			interval = time.Duration(config.KaspaValidators[i].CheckInterval) * time.Second
+		if c.groupInterval() > 0 {
+			interval = time.Duration(c.groupInterval()) * time.Second
 		}
-		go monitorKaspaValidators(&config.KaspaValidators[i], bot, config.Telegram.ChatID, interval, config.AlertCooldown, &wg)
+		go runMonitor(c, notifier, interval, int(config.AlertCooldown), &wg)
 	}
 
-	// Wait for all monitoring goroutines
-	wg.Wait()
+	// Every monitor has started
+	if probes != nil {
+		probes.markReady()
+	}
+
+	// Wait for all monitoring goroutines, or until the agent is stopped
+	go func() {
+		wg.Wait()
+		stop()
+	}()
+	<-ctx.Done()
+	slog.Info("shutting down")
+	if probes != nil {
+		<-probes.done
+	}
 }
@@ -0,0 +1,1118 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCheckAndNotifyConcurrent checks the same low-balance items from many
+// goroutines at once; run with -race to catch unguarded alert state.
+func TestCheckAndNotifyConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/bank/v1beta1/balances/dym1test":
+			_, _ = w.Write([]byte(`{"balances":[{"denom":"adym","amount":"5"}]}`))
+		case "/addresses/kaspa:test/balance":
+			_, _ = w.Write([]byte(`{"address":"kaspa:test","balance":5}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	addrGroup := &AddressConfig{Name: "group", RESTEndpoint: server.URL, Addresses: []AddressItem{
+		{Name: "wallet", Address: "dym1test", Direction: directionBelow, itemState: newItemState()},
+	}}
+	addrItem := &addrGroup.Addresses[0]
+	addrItem.Threshold.Denom = "adym"
+	addrItem.Threshold.Amount = "10"
+
+	kaspaGroup := &KaspaAddressConfig{Name: "group", RESTEndpoint: server.URL, Addresses: []KaspaAddressItem{
+		{Name: "wallet", Address: "kaspa:test", Threshold: "10", Direction: directionBelow, itemState: newItemState()},
+	}}
+	kaspaItem := &kaspaGroup.Addresses[0]
+
+	// A notifier without channels only prints to stdout
+	notifier := &Notifier{}
+
+	// The balances never recover, stop their recovery monitors
+	t.Cleanup(func() {
+		addrItem.recoveryMonitorMu.Lock()
+		stopRecoveryMonitor(&addrItem.isUnhealthy, &addrItem.recoveryMonitorStop)
+		addrItem.recoveryMonitorMu.Unlock()
+		kaspaItem.recoveryMonitorMu.Lock()
+		stopRecoveryMonitor(&kaspaItem.isUnhealthy, &kaspaItem.recoveryMonitorStop)
+		kaspaItem.recoveryMonitorMu.Unlock()
+		waitForRecoveryMonitors(t, 0)
+	})
+
+	// Checks of the same item overlap when its recovery monitor runs
+	addrAlert := itemAlert{itemType: "balance", group: "group", item: addrGroup.item(0), notifier: notifier, cooldown: 3600}
+	kaspaAlert := itemAlert{itemType: "kaspa_balance", group: "group", item: kaspaGroup.item(0), notifier: notifier, cooldown: 3600}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := addrAlert.check(true); err != nil {
+				t.Errorf("check: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := kaspaAlert.check(true); err != nil {
+				t.Errorf("check: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if addrItem.lastAlertTime.IsZero() {
+		t.Error("expected an alert for the cosmos address")
+	}
+	if kaspaItem.lastAlertTime.IsZero() {
+		t.Error("expected an alert for the Kaspa address")
+	}
+}
+
+// fakeClient answers requests with a function instead of the network
+type fakeClient func(req *http.Request) (*http.Response, error)
+
+func (f fakeClient) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// respond returns a fake client answering every request with status and body
+func respond(status int, body string) fakeClient {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+}
+
+// noRetries disables retries for the duration of a test
+func noRetries(t *testing.T) {
+	retries := httpRetries
+	httpRetries = 0
+	t.Cleanup(func() { httpRetries = retries })
+}
+
+func TestGetBalance(t *testing.T) {
+	noRetries(t)
+
+	tests := []struct {
+		name    string
+		client  fakeClient
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "success",
+			client: respond(http.StatusOK, `{"balances":[{"denom":"adym","amount":"1500"}]}`),
+			want:   "1500",
+		},
+		{
+			name:    "non-200 status",
+			client:  respond(http.StatusNotFound, `not found`),
+			wantErr: "HTTP 404",
+		},
+		{
+			name:    "malformed JSON",
+			client:  respond(http.StatusOK, `{"balances":[`),
+			wantErr: "error parsing response",
+		},
+		{
+			name: "request error",
+			client: func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			},
+			wantErr: "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := getBalance(context.Background(), "http://rest.test", "dym1test", "", RequestOptions{httpClient: tt.client})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(resp.Balances) != 1 || resp.Balances[0].Amount != tt.want {
+				t.Errorf("expected amount %s, got %+v", tt.want, resp.Balances)
+			}
+		})
+	}
+}
+
+func TestGetMetricValue(t *testing.T) {
+	noRetries(t)
+
+	const metrics = `# HELP peers Connected peers
+# TYPE peers gauge
+peers{chain="hub"} 12
+peers{chain="rollapp"} 3
+`
+
+	tests := []struct {
+		name    string
+		client  fakeClient
+		metric  string
+		labels  map[string]string
+		want    float64
+		wantErr string
+	}{
+		{
+			name:   "success",
+			client: respond(http.StatusOK, metrics),
+			metric: "peers",
+			labels: map[string]string{"chain": "rollapp"},
+			want:   3,
+		},
+		{
+			name:    "non-200 status",
+			client:  respond(http.StatusServiceUnavailable, metrics),
+			metric:  "peers",
+			wantErr: "HTTP 503",
+		},
+		{
+			name:    "metric not found",
+			client:  respond(http.StatusOK, metrics),
+			metric:  "height",
+			wantErr: "not found",
+		},
+		{
+			name:    "no series matches the labels",
+			client:  respond(http.StatusOK, metrics),
+			metric:  "peers",
+			labels:  map[string]string{"chain": "other"},
+			wantErr: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := getMetricValue(context.Background(), "http://metrics.test/metrics", RequestMethod{}, tt.metric, tt.labels, RequestOptions{httpClient: tt.client})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, value)
+			}
+		})
+	}
+}
+
+// TestGzipResponses checks that gzip is requested and that gzipped responses
+// are decompressed, also from a server that gzips unasked
+func TestGzipResponses(t *testing.T) {
+	noRetries(t)
+
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte("# TYPE peers gauge\npeers 12\n"))
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	value, err := getMetricValue(context.Background(), server.URL, RequestMethod{}, "peers", nil, RequestOptions{})
+	if err != nil || value != 12 {
+		t.Fatalf("expected 12, got %v, %v", value, err)
+	}
+	if acceptEncoding != "gzip" {
+		t.Errorf("expected Accept-Encoding gzip, got %q", acceptEncoding)
+	}
+
+	value, err = getMetricValue(context.Background(), server.URL, RequestMethod{}, "peers", nil, RequestOptions{Headers: map[string]string{"Accept-Encoding": "identity"}})
+	if err != nil || value != 12 {
+		t.Errorf("expected an unasked gzip response to be decompressed, got %v, %v", value, err)
+	}
+}
+
+// TestKaspaAddressRecovery checks that a Kaspa address below its threshold
+// starts a recovery monitor that clears the unhealthy state once refunded
+func TestKaspaAddressRecovery(t *testing.T) {
+	noRetries(t)
+	interval := recoveryInterval
+	recoveryInterval = 10 * time.Millisecond
+	t.Cleanup(func() { recoveryInterval = interval })
+
+	var balance atomic.Int64
+	balance.Store(5)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"address":"kaspa:test","balance":%d}`, balance.Load())
+	}))
+	defer server.Close()
+
+	kaspaGroup := &KaspaAddressConfig{Name: "group", RESTEndpoint: server.URL, Addresses: []KaspaAddressItem{
+		{Name: "wallet", Address: "kaspa:test", Threshold: "10", Direction: directionBelow, itemState: newItemState()},
+	}}
+	kaspaItem := &kaspaGroup.Addresses[0]
+
+	if _, err := checkGroupItem(kaspaGroup, 0, &Notifier{}, 3600); err != nil {
+		t.Fatalf("checkGroupItem: %v", err)
+	}
+	kaspaItem.recoveryMonitorMu.Lock()
+	unhealthy := kaspaItem.isUnhealthy
+	kaspaItem.recoveryMonitorMu.Unlock()
+	if !unhealthy {
+		t.Fatal("expected the Kaspa address to be unhealthy below its threshold")
+	}
+
+	balance.Store(20)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		kaspaItem.recoveryMonitorMu.Lock()
+		unhealthy = kaspaItem.isUnhealthy
+		kaspaItem.recoveryMonitorMu.Unlock()
+		if !unhealthy {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the Kaspa address to recover once its balance is back above the threshold")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestAddressRecovery checks that a cosmos address alerted below its
+// threshold gets a recovery message once its balance is back above it
+func TestAddressRecovery(t *testing.T) {
+	noRetries(t)
+
+	balance := 5
+	group := &AddressConfig{Name: "group", RESTEndpoint: "http://rest.test", Addresses: []AddressItem{
+		{Name: "wallet", Address: "dym1test", Direction: directionBelow, RecoveryInterval: 3600, itemState: newItemState(), drop: newItemState()},
+	}, RequestOptions: RequestOptions{httpClient: fakeClient(func(req *http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, fmt.Sprintf(`{"balances":[{"denom":"adym","amount":"%d"}]}`, balance))(req)
+	})}}
+	item := &group.Addresses[0]
+	item.Threshold.Denom = "adym"
+	item.Threshold.Amount = "10"
+	t.Cleanup(func() {
+		item.recoveryMonitorMu.Lock()
+		stopRecoveryMonitor(&item.isUnhealthy, &item.recoveryMonitorStop)
+		item.recoveryMonitorMu.Unlock()
+		waitForRecoveryMonitors(t, 0)
+	})
+
+	var messages []string
+	n := &Notifier{
+		channels: []notificationChannel{recordingChannel{channelName: "chat", sent: new([]string), messages: &messages}},
+		queue:    make(chan notification, 10),
+		deduper:  newAlertDeduper(0),
+	}
+	check := func() {
+		t.Helper()
+		if _, err := checkGroupItem(group, 0, n, 3600); err != nil {
+			t.Fatalf("checkGroupItem: %v", err)
+		}
+		for len(n.queue) > 0 {
+			job := <-n.queue
+			n.deliver(job.message, job.route, job.incident, job.scope)
+		}
+	}
+
+	check()
+	balance = 20
+	check()
+
+	if len(messages) != 2 || !strings.Contains(messages[0], "Alert") || !strings.Contains(messages[1], "`wallet` has recovered!") {
+		t.Fatalf("expected an alert and a recovery, got %q", messages)
+	}
+	item.recoveryMonitorMu.Lock()
+	defer item.recoveryMonitorMu.Unlock()
+	if item.isUnhealthy {
+		t.Error("expected the address to be healthy after recovering")
+	}
+}
+
+// TestLoadConfigUnknownKeys checks that a misspelled key fails loading
+// instead of leaving the item unconfigured
+func TestLoadConfigUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := `addresses:
+  - rest_endpoint: "http://localhost:1317"
+    addresses:
+      - address: "dym1test"
+        thresold:
+          denom: "adym"
+          amount: "10"
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := loadConfig(path, "")
+	if err == nil || !strings.Contains(err.Error(), "unknown config keys: addresses[0].addresses[0].thresold") {
+		t.Fatalf("expected the misspelled key to be reported, got %v", err)
+	}
+}
+
+// TestLoadConfigStartupMessage checks the default, custom and disabled startup message
+func TestLoadConfigStartupMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{name: "default", config: "check_interval: 60\n", expected: "🚀 Monitor started"},
+		{name: "plain", config: "use_emoji: false\n", expected: "[STARTED] Monitor started"},
+		{name: "custom", config: "startup_message: \"Agent restarted\"\n", expected: "Agent restarted"},
+		{name: "disabled", config: "startup_message: false\n", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := loadConfig(path, "")
+			if err != nil {
+				t.Fatalf("loadConfig: %v", err)
+			}
+			if config.StartupMessage != tt.expected {
+				t.Errorf("expected startup message %q, got %q", tt.expected, config.StartupMessage)
+			}
+		})
+	}
+}
+
+// TestCheckHealthPost checks that a health endpoint with method POST is sent
+// its JSON-RPC body
+func TestCheckHealthPost(t *testing.T) {
+	noRetries(t)
+
+	var method, contentType, body string
+	client := fakeClient(func(req *http.Request) (*http.Response, error) {
+		method, contentType = req.Method, req.Header.Get("Content-Type")
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"result":{"isHealthy":true}}`))}, nil
+	})
+
+	item := &HealthItem{
+		Endpoint: "http://node.test", HealthPath: defaultHealthPath, HealthyValue: defaultHealthyValue,
+		RequestMethod: RequestMethod{Method: "post", Body: `{"jsonrpc":"2.0","id":1,"method":"health"}`},
+	}
+	if err := item.RequestMethod.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	resp, err := checkHealth(context.Background(), item, RequestOptions{httpClient: client})
+	if err != nil {
+		t.Fatalf("checkHealth: %v", err)
+	}
+	if !resp.IsHealthy {
+		t.Error("expected the endpoint to be healthy")
+	}
+	if method != http.MethodPost || contentType != "application/json" || body != item.Body {
+		t.Errorf("expected a JSON POST of %s, got %s %q with %s", item.Body, method, contentType, body)
+	}
+
+	if err := (&RequestMethod{Body: "{}"}).validate(); err == nil {
+		t.Error("expected a body without method POST to be rejected")
+	}
+}
+
+// TestPingKaspaValidatorBody checks that a 200 response with the wrong body
+// is unhealthy when the validator sets expect_contains or expect_json_field
+func TestPingKaspaValidatorBody(t *testing.T) {
+	noRetries(t)
+
+	tests := []struct {
+		name    string
+		item    KaspaValidatorItem
+		body    string
+		wantErr string
+	}{
+		{name: "status only", body: `{"status":"error"}`},
+		{name: "contains", item: KaspaValidatorItem{ExpectContains: `"synced":true`}, body: `{"synced":true}`},
+		{name: "missing text", item: KaspaValidatorItem{ExpectContains: `"synced":true`}, body: `{"error":"node is syncing"}`, wantErr: `node is syncing`},
+		{name: "field value", item: KaspaValidatorItem{ExpectJSONField: "result.status=ok"}, body: `{"result":{"status":"ok"}}`},
+		{name: "wrong field value", item: KaspaValidatorItem{ExpectJSONField: "result.status=ok"}, body: `{"result":{"status":"degraded"}}`, wantErr: "result.status = degraded, expected ok"},
+		{name: "field exists", item: KaspaValidatorItem{ExpectJSONField: "result"}, body: `{"result":{}}`},
+		{name: "missing field", item: KaspaValidatorItem{ExpectJSONField: "result"}, body: `{"error":"down"}`, wantErr: `key "result" not found`},
+		{name: "not JSON", item: KaspaValidatorItem{ExpectJSONField: "result"}, body: `internal error`, wantErr: "not JSON: internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.item.Endpoint = "http://validator.test/health"
+			err := pingKaspaValidator(context.Background(), &tt.item, RequestOptions{httpClient: respond(http.StatusOK, tt.body)})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestLoadConfigDir checks that the files of a config directory are merged,
+// with lists concatenated and conflicting settings rejected
+func TestLoadConfigDir(t *testing.T) {
+	writeConfigs := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	hub := `check_interval: 60
+addresses:
+  - name: "Hub"
+    rest_endpoint: "http://hub.test:1317"
+    addresses:
+      - address: "dym1hub"
+        threshold:
+          denom: "adym"
+          amount: "10"
+`
+	rollapp := `check_interval: 60
+addresses:
+  - name: "RollApp"
+    rest_endpoint: "http://rollapp.test:1317"
+    addresses:
+      - address: "dym1rollapp"
+        threshold:
+          denom: "adym"
+          amount: "10"
+`
+
+	config, err := loadConfig("", writeConfigs(t, map[string]string{"hub.yaml": hub, "rollapp.yml": rollapp, "notes.txt": "not: config"}))
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if len(config.Addresses) != 2 || config.Addresses[0].Name != "Hub" || config.Addresses[1].Name != "RollApp" {
+		t.Errorf("expected the Hub and RollApp groups in file order, got %+v", config.Addresses)
+	}
+
+	conflicting := strings.Replace(rollapp, "check_interval: 60", "check_interval: 30", 1)
+	_, err = loadConfig("", writeConfigs(t, map[string]string{"hub.yaml": hub, "rollapp.yaml": conflicting}))
+	if err == nil || !strings.Contains(err.Error(), "conflicting config setting check_interval") {
+		t.Errorf("expected the conflicting check_interval to be rejected, got %v", err)
+	}
+}
+
+// TestLoadConfigDurations checks that cooldowns and intervals accept duration
+// strings as well as plain seconds
+func TestLoadConfigDurations(t *testing.T) {
+	load := func(t *testing.T, config string) (*Config, error) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return loadConfig(path, "")
+	}
+
+	config, err := load(t, `check_interval: "5m"
+alert_cooldown: 3600
+recovery_interval: "1m30s"
+health:
+  - name: "Hub"
+    check_interval: "30"
+    endpoints:
+      - name: "RPC"
+        endpoint: "http://localhost:26657"
+        alert_cooldown: "2h"
+`)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if config.CheckInterval != 300 || config.AlertCooldown != 3600 || config.RecoveryInterval != 90 {
+		t.Errorf("expected 300, 3600 and 90 seconds, got %d, %d and %d", config.CheckInterval, config.AlertCooldown, config.RecoveryInterval)
+	}
+	if config.Health[0].CheckInterval != 30 || config.Health[0].Endpoints[0].AlertCooldown != 7200 {
+		t.Errorf("expected 30 and 7200 seconds, got %d and %d", config.Health[0].CheckInterval, config.Health[0].Endpoints[0].AlertCooldown)
+	}
+
+	for _, value := range []string{"5 minutes", "1500ms"} {
+		if _, err := load(t, "alert_cooldown: \""+value+"\"\n"); err == nil || !strings.Contains(err.Error(), "alert_cooldown") {
+			t.Errorf("expected alert_cooldown %q to be rejected, got %v", value, err)
+		}
+	}
+}
+
+// TestStatusLastError checks that /status reports the error of an item's last
+// failed check, and keeps it once the item is checked successfully again
+func TestStatusLastError(t *testing.T) {
+	noRetries(t)
+
+	status := http.StatusServiceUnavailable
+	config := &Config{Metrics: []MetricConfig{{
+		Name:           "Sequencer",
+		RESTEndpoint:   "http://metrics.test/metrics",
+		Metrics:        []MetricItem{{Metric: "peers", Threshold: 100, Operator: operatorGTE, itemState: newItemState()}},
+		RequestOptions: RequestOptions{httpClient: fakeClient(func(req *http.Request) (*http.Response, error) { return respond(status, "peers 12\n")(req) })},
+	}}}
+
+	checkGroupItem(&config.Metrics[0], 0, &Notifier{}, 3600)
+	item := statusItems(config)[0]
+	if !strings.Contains(item.LastError, "HTTP 503") || item.LastErrorTime == nil || item.LastCheckTime != nil {
+		t.Fatalf("expected the failed check's error without a completed check, got %+v", item)
+	}
+
+	status = http.StatusOK
+	config.Metrics[0].Metrics[0].backoff = itemBackoff{}
+	checkGroupItem(&config.Metrics[0], 0, &Notifier{}, 3600)
+	item = statusItems(config)[0]
+	if item.LastValue != "12" || !strings.Contains(item.LastError, "HTTP 503") {
+		t.Errorf("expected the value of the new check and the earlier error, got %+v", item)
+	}
+}
+
+// TestGroupUnreachable checks that a group alerts once every item failed to
+// fetch from its endpoint, and recovers once a fetch succeeds
+func TestGroupUnreachable(t *testing.T) {
+	noRetries(t)
+
+	var sent []string
+	n := &Notifier{
+		channels: []notificationChannel{recordingChannel{channelName: "chat", sent: &sent}},
+		queue:    make(chan notification, 10),
+		deduper:  newAlertDeduper(0),
+	}
+	status := http.StatusServiceUnavailable
+	group := &MetricConfig{
+		Name:         "Sequencer",
+		RESTEndpoint: "http://metrics.test/metrics",
+		Metrics: []MetricItem{
+			{Metric: "peers", Threshold: 100, Operator: operatorGTE, itemState: newItemState()},
+			{Metric: "height", Threshold: 10, Operator: operatorGTE, itemState: newItemState()},
+		},
+		RequestOptions: RequestOptions{httpClient: fakeClient(func(req *http.Request) (*http.Response, error) {
+			return respond(status, "peers 12\nheight 5\n")(req)
+		})},
+	}
+
+	cycle := func() *cycleResults {
+		var results cycleResults
+		for i := range group.Metrics {
+			group.Metrics[i].backoff = itemBackoff{}
+			results.add(checkGroupItem(group, i, n, 3600))
+		}
+		return &results
+	}
+
+	var reachability groupReachability
+	reachability.update(group, cycle(), n, 3600)
+	if !reachability.unreachable || len(n.queue) != 1 {
+		t.Fatalf("expected one unreachable alert, got %d notifications", len(n.queue))
+	}
+	alert := <-n.queue
+	if !strings.Contains(alert.message, "endpoint unreachable") || !strings.Contains(alert.message, "HTTP 503") {
+		t.Errorf("expected an unreachable alert with the error, got %q", alert.message)
+	}
+
+	// A second failed cycle is within the cooldown
+	reachability.update(group, cycle(), n, 3600)
+	if len(n.queue) != 0 {
+		t.Errorf("expected the cooldown to suppress the alert, got %d notifications", len(n.queue))
+	}
+
+	status = http.StatusOK
+	reachability.update(group, cycle(), n, 3600)
+	if reachability.unreachable || len(n.queue) != 1 {
+		t.Fatalf("expected a recovery, got %d notifications", len(n.queue))
+	}
+	if recovery := <-n.queue; !strings.Contains(recovery.message, "reachable again") {
+		t.Errorf("expected a recovery message, got %q", recovery.message)
+	}
+}
+
+// TestAlertReleasesItemLock checks that a balance alert waiting for room in a
+// full queue doesn't hold the item's lock, which /status and the item's
+// recovery monitor also take
+func TestAlertReleasesItemLock(t *testing.T) {
+	noRetries(t)
+
+	var sent []string
+	notifier := &Notifier{
+		channels:       []notificationChannel{recordingChannel{channelName: "chat", sent: &sent}},
+		queue:          make(chan notification), // Always full
+		overflowPolicy: overflowBlock,
+		deduper:        newAlertDeduper(0),
+	}
+	addrGroup := &AddressConfig{
+		Name: "group", RESTEndpoint: "http://lcd.test",
+		Addresses: []AddressItem{
+			{Name: "wallet", Address: "dym1test", Direction: directionBelow, RecoveryInterval: 3600, itemState: newItemState()},
+		},
+		RequestOptions: RequestOptions{httpClient: respond(http.StatusOK, `{"balances":[{"denom":"adym","amount":"5"}]}`)},
+	}
+	addrItem := &addrGroup.Addresses[0]
+	addrItem.Threshold.Denom = "adym"
+	addrItem.Threshold.Amount = "10"
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := checkGroupItem(addrGroup, 0, notifier, 3600)
+		done <- err
+	}()
+
+	// Once the item is marked unhealthy the alert is queued next, so the lock
+	// has to be free while it waits
+	deadline := time.Now().Add(time.Second)
+	for {
+		if addrItem.recoveryMonitorMu.TryLock() {
+			unhealthy := addrItem.isUnhealthy
+			addrItem.recoveryMonitorMu.Unlock()
+			if unhealthy {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the item's lock to be free while the alert waits for the queue")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if job := <-notifier.queue; !strings.Contains(job.message, "`wallet` is unhealthy") {
+		t.Errorf("expected the balance alert, got %q", job.message)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("checkGroupItem: %v", err)
+	}
+
+	addrItem.recoveryMonitorMu.Lock()
+	stopRecoveryMonitor(&addrItem.isUnhealthy, &addrItem.recoveryMonitorStop)
+	addrItem.recoveryMonitorMu.Unlock()
+	waitForRecoveryMonitors(t, 0)
+}
+
+// TestUndeliveredAlertRecovery checks that an item only sends its recovery to
+// the channels its alert was queued for: an alert over max_alerts_per_minute
+// only reached the incident channels, and a duplicate reached none
+func TestUndeliveredAlertRecovery(t *testing.T) {
+	noRetries(t)
+
+	var sent []string
+	limiter := newAlertLimiter(1)
+	limiter.summaryDelay = time.Hour
+	n := &Notifier{
+		channels: []notificationChannel{
+			recordingChannel{channelName: "chat", sent: &sent},
+			recordingChannel{channelName: "pager", incidents: true, sent: &sent},
+		},
+		queue:   make(chan notification, 10),
+		deduper: newAlertDeduper(time.Hour),
+		limiter: limiter,
+	}
+	body := "first 20\nsecond 20\n"
+	group := &MetricConfig{
+		Name:         "Sequencer",
+		RESTEndpoint: "http://metrics.test/metrics",
+		Metrics: []MetricItem{
+			{Metric: "first", Threshold: 10, Operator: operatorGTE, RecoveryInterval: 3600, itemState: newItemState()},
+			{Metric: "second", Threshold: 10, Operator: operatorGTE, RecoveryInterval: 3600, itemState: newItemState()},
+		},
+		RequestOptions: RequestOptions{httpClient: fakeClient(func(req *http.Request) (*http.Response, error) {
+			return respond(http.StatusOK, body)(req)
+		})},
+	}
+	cycle := func() []channelScope {
+		for i := range group.Metrics {
+			group.Metrics[i].backoff = itemBackoff{}
+			if _, err := checkGroupItem(group, i, n, 0); err != nil {
+				t.Fatalf("checkGroupItem: %v", err)
+			}
+		}
+		var scopes []channelScope
+		for len(n.queue) > 0 {
+			scopes = append(scopes, (<-n.queue).scope)
+		}
+		return scopes
+	}
+
+	// The second alert is over the cap, so chat never sees it
+	if scopes := cycle(); !slices.Equal(scopes, []channelScope{scopeAll, scopeIncidents}) {
+		t.Fatalf("expected the first alert everywhere and the second on the incident channels, got %v", scopes)
+	}
+	body = "first 1\nsecond 1\n"
+	if scopes := cycle(); !slices.Equal(scopes, []channelScope{scopeAll, scopeIncidents}) {
+		t.Fatalf("expected the second recovery on the incident channels only, got %v", scopes)
+	}
+
+	// Both alerts are duplicates within the dedup window, so no recovery follows
+	limiter.perMinute = 0
+	body = "first 20\nsecond 20\n"
+	if scopes := cycle(); len(scopes) != 0 {
+		t.Fatalf("expected the duplicate alerts to be dropped, got %v", scopes)
+	}
+	body = "first 1\nsecond 1\n"
+	if scopes := cycle(); len(scopes) != 0 {
+		t.Errorf("expected no recovery for the dropped alerts, got %v", scopes)
+	}
+	waitForRecoveryMonitors(t, 0)
+}
+
+// TestUnreachableItemBacksOff counts the requests a dead endpoint gets: its
+// regular checks back off, and so does the recovery monitor polling it, until
+// the endpoint answers again
+func TestUnreachableItemBacksOff(t *testing.T) {
+	noRetries(t)
+	backoff, interval := maxCheckBackoff, recoveryInterval
+	maxCheckBackoff, recoveryInterval = 8, 10*time.Millisecond
+	t.Cleanup(func() { maxCheckBackoff, recoveryInterval = backoff, interval })
+
+	var down atomic.Bool
+	var regularHits, polledHits atomic.Int64
+	down.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/regular" {
+			regularHits.Add(1)
+		} else {
+			polledHits.Add(1)
+		}
+		if down.Load() {
+			// Drop the connection without an answer
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		fmt.Fprint(w, `{"result":{"isHealthy":false}}`)
+	}))
+	defer server.Close()
+
+	group := &HealthConfig{
+		Name: "group",
+		Endpoints: []HealthItem{
+			// Only checked by its group, its recovery monitor never comes due
+			{Name: "regular", Endpoint: server.URL + "/regular", RecoveryInterval: 3600, itemState: newItemState()},
+			{Name: "polled", Endpoint: server.URL + "/polled", itemState: newItemState()},
+		},
+	}
+	for i := range group.Endpoints {
+		group.Endpoints[i].HealthPath = "result.isHealthy"
+		group.Endpoints[i].HealthyValue = "true"
+	}
+	t.Cleanup(func() {
+		for i := range group.Endpoints {
+			item := &group.Endpoints[i]
+			item.recoveryMonitorMu.Lock()
+			stopRecoveryMonitor(&item.isUnhealthy, &item.recoveryMonitorStop)
+			item.recoveryMonitorMu.Unlock()
+		}
+		waitForRecoveryMonitors(t, 0)
+	})
+
+	// The second failure skips the next interval, the third skips three
+	for range 8 {
+		checkGroupItem(group, 0, &Notifier{}, 3600)
+	}
+	if hits := regularHits.Load(); hits != 4 {
+		t.Errorf("expected 4 of 8 regular checks to reach the dead endpoint, got %d", hits)
+	}
+
+	// Polled every 10ms for 500ms, the recovery monitor backs off to every 80ms
+	if _, err := checkGroupItem(group, 1, &Notifier{}, 3600); err == nil {
+		t.Fatal("expected the dead endpoint's check to fail")
+	}
+	time.Sleep(500 * time.Millisecond)
+	if hits := polledHits.Load(); hits > 15 {
+		t.Errorf("expected the recovery monitor to back off from the dead endpoint, got %d requests in 50 intervals", hits)
+	}
+
+	// An answer ends the backoff, the unhealthy endpoint is polled every interval
+	down.Store(false)
+	for start := polledHits.Load(); polledHits.Load() == start; {
+		time.Sleep(5 * time.Millisecond)
+	}
+	start := polledHits.Load()
+	time.Sleep(200 * time.Millisecond)
+	if hits := polledHits.Load() - start; hits < 8 {
+		t.Errorf("expected the answering endpoint to be polled every interval again, got %d requests in 20 intervals", hits)
+	}
+}
+
+// TestPageAfter checks that an item with page_after alerts chat at once,
+// pages the incident channels only once it has been unhealthy for page_after,
+// and doesn't page at all if it recovers first
+func TestPageAfter(t *testing.T) {
+	noRetries(t)
+
+	var sent []string
+	n := &Notifier{
+		channels: []notificationChannel{
+			recordingChannel{channelName: "chat", sent: &sent},
+			recordingChannel{channelName: "pager", incidents: true, sent: &sent},
+		},
+		queue:   make(chan notification, 10),
+		deduper: newAlertDeduper(0),
+	}
+	healthy := false
+	group := &HealthConfig{
+		Name: "group",
+		Endpoints: []HealthItem{{Name: "node", Endpoint: "http://node.test/health", HealthPath: defaultHealthPath, HealthyValue: defaultHealthyValue,
+			RecoveryInterval: 3600, WebhookOverride: WebhookOverride{PageAfter: 60}, itemState: newItemState()}},
+		RequestOptions: RequestOptions{httpClient: fakeClient(func(req *http.Request) (*http.Response, error) {
+			return respond(http.StatusOK, fmt.Sprintf(`{"result":{"isHealthy":%t}}`, healthy))(req)
+		})},
+	}
+	item := &group.Endpoints[0]
+	t.Cleanup(func() {
+		item.recoveryMonitorMu.Lock()
+		stopRecoveryMonitor(&item.isUnhealthy, &item.recoveryMonitorStop)
+		item.recoveryMonitorMu.Unlock()
+		waitForRecoveryMonitors(t, 0)
+	})
+
+	check := func() []channelScope {
+		t.Helper()
+		if _, err := checkGroupItem(group, 0, n, 3600); err != nil {
+			t.Fatalf("checkGroupItem: %v", err)
+		}
+		var scopes []channelScope
+		for len(n.queue) > 0 {
+			scopes = append(scopes, (<-n.queue).scope)
+		}
+		return scopes
+	}
+	unhealthyFor := func(d time.Duration) {
+		item.recoveryMonitorMu.Lock()
+		item.unhealthySince = time.Now().Add(-d)
+		item.recoveryMonitorMu.Unlock()
+	}
+
+	if scopes := check(); !slices.Equal(scopes, []channelScope{scopeChat}) {
+		t.Fatalf("expected the first alert to go to chat only, got %v", scopes)
+	}
+	if scopes := check(); len(scopes) != 0 {
+		t.Fatalf("expected nothing before page_after, got %v", scopes)
+	}
+	unhealthyFor(2 * time.Minute)
+	if scopes := check(); !slices.Equal(scopes, []channelScope{scopeIncidents}) {
+		t.Fatalf("expected a page once page_after passed, got %v", scopes)
+	}
+	if scopes := check(); len(scopes) != 0 {
+		t.Fatalf("expected a single page, got %v", scopes)
+	}
+	healthy = true
+	if scopes := check(); !slices.Equal(scopes, []channelScope{scopeAll}) {
+		t.Fatalf("expected the recovery to resolve the page, got %v", scopes)
+	}
+
+	// Recovering before page_after cancels the page
+	healthy = false
+	item.recoveryMonitorMu.Lock()
+	item.lastAlertTime = time.Time{}
+	item.recoveryMonitorMu.Unlock()
+	if scopes := check(); !slices.Equal(scopes, []channelScope{scopeChat}) {
+		t.Fatalf("expected the second breach to alert chat only, got %v", scopes)
+	}
+	healthy = true
+	if scopes := check(); !slices.Equal(scopes, []channelScope{scopeChat}) {
+		t.Fatalf("expected the recovery to skip the incident channels, got %v", scopes)
+	}
+}
+
+// TestCheckConcurrently checks that every item of a group is checked, with no
+// more than max_concurrency checks running at once
+func TestCheckConcurrently(t *testing.T) {
+	concurrency := maxConcurrency
+	maxConcurrency = 3
+	t.Cleanup(func() { maxConcurrency = concurrency })
+
+	var running, peak atomic.Int32
+	checked := make([]bool, 10)
+	checkConcurrently(len(checked), func(i int) {
+		current := running.Add(1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		checked[i] = true
+		running.Add(-1)
+	})
+
+	if slices.Contains(checked, false) {
+		t.Errorf("expected every item to be checked, got %v", checked)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent checks, got %d", peak.Load())
+	}
+	if peak.Load() < 2 {
+		t.Errorf("expected checks to run concurrently, got at most %d at once", peak.Load())
+	}
+}
+
+// TestCompareMetric checks each threshold operator on both sides of the threshold
+func TestCompareMetric(t *testing.T) {
+	tests := []struct {
+		op    string
+		below bool
+		equal bool
+		above bool
+	}{
+		{op: operatorGT, above: true},
+		{op: operatorGTE, equal: true, above: true},
+		{op: "", equal: true, above: true}, // Defaults to gte
+		{op: operatorLT, below: true},
+		{op: operatorLTE, below: true, equal: true},
+		{op: operatorEQ, equal: true},
+		{op: operatorNE, below: true, above: true},
+	}
+
+	for _, tt := range tests {
+		got := []bool{compareMetric(9, 10, tt.op), compareMetric(10, 10, tt.op), compareMetric(11, 10, tt.op)}
+		if want := []bool{tt.below, tt.equal, tt.above}; !slices.Equal(got, want) {
+			t.Errorf("operator %q: expected %v for 9, 10 and 11 against 10, got %v", tt.op, want, got)
+		}
+	}
+}
+
+// TestVersionString checks that the build information set with -ldflags is reported
+func TestVersionString(t *testing.T) {
+	version, commit, built := BuildVersion, BuildCommit, BuildTime
+	BuildVersion, BuildCommit, BuildTime = "v1.2.3", "abc1234", "2026-01-01T00:00:00Z"
+	t.Cleanup(func() { BuildVersion, BuildCommit, BuildTime = version, commit, built })
+
+	expected := "observability-agent v1.2.3 (commit abc1234, built 2026-01-01T00:00:00Z, "
+	if got := versionString(); !strings.HasPrefix(got, expected) {
+		t.Errorf("expected %q..., got %q", expected, got)
+	}
+}
+
+// TestSleepJitter checks that the initial delay stays within check_jitter of
+// the interval, and that a zero check_jitter doesn't delay
+func TestSleepJitter(t *testing.T) {
+	jitter := checkJitter
+	t.Cleanup(func() { checkJitter = jitter })
+
+	checkJitter = 0
+	start := time.Now()
+	sleepJitter(time.Hour)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected no delay without jitter, took %s", elapsed)
+	}
+
+	checkJitter = 0.5
+	start = time.Now()
+	sleepJitter(100 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected at most 50ms of jitter, took %s", elapsed)
+	}
+}
+
+// TestCheckHealthPath checks a health endpoint with a custom health path,
+// healthy value and error path
+func TestCheckHealthPath(t *testing.T) {
+	noRetries(t)
+
+	item := &HealthItem{Name: "node", Endpoint: "http://node.test/status", HealthPath: "result.sync_info.catching_up", HealthyValue: "false", ErrorPath: "result.error"}
+	tests := []struct {
+		name    string
+		body    string
+		healthy bool
+		errMsg  string
+	}{
+		{name: "healthy", body: `{"result":{"sync_info":{"catching_up":false}}}`, healthy: true},
+		{name: "unhealthy with error", body: `{"result":{"sync_info":{"catching_up":true},"error":"behind by 300 blocks"}}`, errMsg: "behind by 300 blocks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := checkHealth(context.Background(), item, RequestOptions{httpClient: respond(http.StatusOK, tt.body)})
+			if err != nil {
+				t.Fatalf("checkHealth: %v", err)
+			}
+			if resp.IsHealthy != tt.healthy || resp.Error != tt.errMsg {
+				t.Errorf("expected healthy %v with error %q, got %+v", tt.healthy, tt.errMsg, resp)
+			}
+		})
+	}
+
+	if _, err := checkHealth(context.Background(), item, RequestOptions{httpClient: respond(http.StatusOK, `{"result":{}}`)}); err == nil {
+		t.Error("expected an error when the health path is missing")
+	}
+}
+
+// TestHealthExpectStatus checks that with expect_status only the status code
+// decides, whatever the body
+func TestHealthExpectStatus(t *testing.T) {
+	noRetries(t)
+
+	minStatus, maxStatus, err := parseStatusRange("200-299")
+	if err != nil {
+		t.Fatalf("parseStatusRange: %v", err)
+	}
+	item := &HealthItem{Name: "node", Endpoint: "http://node.test/", ExpectStatus: "200-299", expectStatusMin: minStatus, expectStatusMax: maxStatus}
+
+	resp, err := checkHealth(context.Background(), item, RequestOptions{httpClient: respond(http.StatusNoContent, "")})
+	if err != nil || !resp.IsHealthy {
+		t.Errorf("expected 204 to be healthy, got %+v (%v)", resp, err)
+	}
+	if _, err := checkHealth(context.Background(), item, RequestOptions{httpClient: respond(http.StatusMovedPermanently, "<html>")}); err == nil {
+		t.Error("expected 301 outside the range to fail")
+	}
+
+	for _, status := range []string{"abc", "99", "200-600", "299-200", "200-"} {
+		if _, _, err := parseStatusRange(status); err == nil {
+			t.Errorf("expected status %q to be rejected", status)
+		}
+	}
+	if low, high, err := parseStatusRange(" 503 "); err != nil || low != 503 || high != 503 {
+		t.Errorf("expected a single status to be its own range, got %d-%d (%v)", low, high, err)
+	}
+}
+
+// TestValidateCheckIntervals checks that a negative per-group check_interval
+// is rejected for any group type, and an omitted one is left to the global interval
+func TestValidateCheckIntervals(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		section string
+	}{
+		{name: "omitted and positive", config: Config{Health: []HealthConfig{{Name: "a"}, {Name: "b", CheckInterval: 30}}}},
+		{name: "negative health", config: Config{Health: []HealthConfig{{Name: "a", CheckInterval: -1}}}, section: "health group 'a'"},
+		{name: "negative sync", config: Config{SyncChecks: []SyncConfig{{Name: "b", CheckInterval: -30}}}, section: "sync_checks group 'b'"},
+	}
+
+	for _, tt := range tests {
+		err := validateCheckIntervals(&tt.config)
+		if tt.section == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.name, err)
+		}
+		if tt.section != "" && (err == nil || !strings.Contains(err.Error(), tt.section)) {
+			t.Errorf("%s: expected an error naming %s, got %v", tt.name, tt.section, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// matrixTxnCounter makes transaction ids unique within a process, the start
+// time keeps them unique across restarts
+var (
+	matrixTxnCounter atomic.Uint64
+	matrixTxnPrefix  = time.Now().UnixNano()
+)
+
+type MatrixConfig struct {
+	Homeserver  string `mapstructure:"homeserver"`   // Homeserver URL, e.g. https://matrix.example.org
+	AccessToken string `mapstructure:"access_token"` // Access token of the bot user
+	RoomID      string `mapstructure:"room_id"`      // Room to post to, e.g. !abc123:example.org
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest alert severity to send
+}
+
+// validate checks that the homeserver URL, access token and room are set
+func (m *MatrixConfig) validate() error {
+	u, err := url.Parse(m.Homeserver)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid homeserver %q: must be an absolute http(s) URL", m.Homeserver)
+	}
+	m.Homeserver = strings.TrimSuffix(m.Homeserver, "/")
+	if m.AccessToken == "" {
+		return fmt.Errorf("access_token is required")
+	}
+	if m.RoomID == "" {
+		return fmt.Errorf("room_id is required")
+	}
+	return nil
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// matrixHTML renders a Telegram-formatted message as HTML with a bold first
+// line, the code spans kept as code and links as anchors
+func matrixHTML(message string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		parts := strings.Split(html.EscapeString(line), "`")
+		var b strings.Builder
+		for j, part := range parts {
+			// Odd parts are inside a code span, a trailing unpaired backtick is kept as is
+			switch {
+			case j%2 == 1 && j < len(parts)-1:
+				b.WriteString("<code>" + part + "</code>")
+			case j%2 == 1:
+				b.WriteString("`" + part)
+			default:
+				b.WriteString(markdownLinkPattern.ReplaceAllString(part, `<a href="$2">$1</a>`))
+			}
+		}
+		lines[i] = b.String()
+	}
+	lines[0] = "<strong>" + lines[0] + "</strong>"
+	return strings.Join(lines, "<br>")
+}
+
+// sendMatrixMessage posts a Telegram-formatted message to the room as an
+// m.notice, which clients show without the highlighting of a user message
+func (m MatrixConfig) sendMatrixMessage(message string) error {
+	payload, err := json.Marshal(matrixMessage{
+		MsgType:       "m.notice",
+		Body:          plainText(message),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: matrixHTML(message),
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	txnID := fmt.Sprintf("%d-%d", matrixTxnPrefix, matrixTxnCounter.Add(1))
+	sendURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", m.Homeserver, url.PathEscape(m.RoomID), txnID)
+
+	req, err := http.NewRequest(http.MethodPut, sendURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.AccessToken)
+
+	resp, err := notificationClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("matrix returned status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestMatrixHTML checks the bold first line, code spans, links and escaping
+func TestMatrixHTML(t *testing.T) {
+	message := "🚨 Alert: [group] `node<1>` is unhealthy!\nExplorer: [dym1abc](https://explorer.test/dym1abc)\nUnpaired `backtick"
+	expected := "<strong>🚨 Alert: [group] <code>node&lt;1&gt;</code> is unhealthy!</strong><br>" +
+		`Explorer: <a href="https://explorer.test/dym1abc">dym1abc</a><br>` +
+		"Unpaired `backtick"
+	if got := matrixHTML(message); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+// TestMatrixMessage checks that messages are sent to the room as notices
+// with unique transaction ids
+func TestMatrixMessage(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+	matrix := MatrixConfig{Homeserver: "https://matrix.test/", AccessToken: "token", RoomID: "!room:matrix.test"}
+	if err := matrix.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := matrix.sendMatrixMessage("Alert: `node`"); err != nil {
+			t.Fatalf("sendMatrixMessage: %v", err)
+		}
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*requests))
+	}
+
+	req := (*requests)[0]
+	prefix := "https://matrix.test/_matrix/client/v3/rooms/%21room:matrix.test/send/m.room.message/"
+	if !strings.HasPrefix(req.url, prefix) || req.header.Get("Authorization") != "Bearer token" {
+		t.Errorf("unexpected request to %s with %v", req.url, req.header)
+	}
+	if req.url == (*requests)[1].url {
+		t.Error("expected a new transaction id for every message")
+	}
+
+	var payload matrixMessage
+	if err := json.Unmarshal([]byte(req.body), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.MsgType != "m.notice" || payload.Body != "Alert: node" || payload.FormattedBody != "<strong>Alert: <code>node</code></strong>" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
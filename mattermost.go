@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type MattermostConfig struct {
+	WebhookURL  string `mapstructure:"webhook_url"`  // Mattermost incoming webhook URL
+	Channel     string `mapstructure:"channel"`      // Optional channel override, e.g. town-square
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest alert severity to send
+}
+
+type mattermostMessage struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// sendMattermostMessage posts a plain-text message to a Mattermost incoming webhook
+func sendMattermostMessage(mattermost MattermostConfig, text string) error {
+	payload, err := json.Marshal(mattermostMessage{
+		Text:    text,
+		Channel: mattermost.Channel,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	resp, err := notificationClient.Post(mattermost.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("mattermost returned status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestMattermostMessage checks that alerts reach Mattermost as plain text
+// with the channel override
+func TestMattermostMessage(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+	channel := mattermostChannel{config: MattermostConfig{WebhookURL: "https://mattermost.test/hooks/x", Channel: "alerts"}}
+
+	if err := channel.send("🚨 Alert: [group] `node` is unhealthy!", nil); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0].url != channel.config.WebhookURL {
+		t.Fatalf("expected one request to the webhook, got %+v", *requests)
+	}
+
+	var payload mattermostMessage
+	if err := json.Unmarshal([]byte((*requests)[0].body), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Text != "🚨 Alert: [group] node is unhealthy!" || payload.Channel != "alerts" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	captureNotifications(t, http.StatusBadRequest)
+	if err := channel.send("alert", nil); err == nil {
+		t.Error("expected an error for a rejected message")
+	}
+}
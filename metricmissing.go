@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// defaultMissingGracePeriod is how long a metric with alert_on_missing may be
+// missing before alerting by default, in seconds
+const defaultMissingGracePeriod = 600
+
+// errMetricNotFound is returned when no series on the metrics endpoint matches a metric
+var errMetricNotFound = errors.New("metric not found")
+
+// checkMissing tracks a metric with alert_on_missing while it is missing from
+// its endpoint. It is unhealthy once it has been missing for longer than its
+// grace period, and until then keeps its current health. An exporter that
+// restarted without the metric or renamed it would otherwise leave the metric
+// unmonitored without anyone noticing.
+func (c metricCheck) checkMissing() (bool, string, error) {
+	m := c.item
+	now := time.Now()
+	m.recoveryMonitorMu.Lock()
+	defer m.recoveryMonitorMu.Unlock()
+	if m.missingSince.IsZero() {
+		m.missingSince = now
+	}
+	missingFor := now.Sub(m.missingSince).Truncate(time.Second)
+	gracePeriod := time.Duration(m.MissingGracePeriod) * time.Second
+
+	detail := fmt.Sprintf("missing for %s", missingFor)
+	if missingFor < gracePeriod {
+		slog.Info("metric missing, waiting for the grace period", "type", "metric", "group", c.group.Name, "item", m.displayName(),
+			"metric", m.series(), "missing_for", missingFor, "grace_period", gracePeriod)
+		return !m.isUnhealthy, detail, nil
+	}
+	return false, detail, nil
+}
+
+// foundAgain ends the missing state of a metric that is found again
+func (m *MetricItem) foundAgain() {
+	m.recoveryMonitorMu.Lock()
+	m.missingSince = time.Time{}
+	m.recoveryMonitorMu.Unlock()
+}
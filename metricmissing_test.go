@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestMissingMetric checks that a metric with alert_on_missing is alerted
+// once it has been missing for its grace period, and recovers when it's back
+func TestMissingMetric(t *testing.T) {
+	noRetries(t)
+
+	metricConfig := &MetricConfig{Name: "group", RESTEndpoint: "http://metrics.test/metrics", Metrics: []MetricItem{{
+		Metric: "height", Threshold: 100, Operator: operatorLT, AlertOnMissing: true, MissingGracePeriod: 60,
+		RecoveryInterval: 3600, itemState: newItemState(),
+	}}}
+	metricItem := &metricConfig.Metrics[0]
+	var sent []string
+	notifier := &Notifier{
+		channels: []notificationChannel{recordingChannel{channelName: "chat", sent: &sent}},
+		queue:    make(chan notification, 10),
+		deduper:  newAlertDeduper(0),
+	}
+	t.Cleanup(func() {
+		metricItem.recoveryMonitorMu.Lock()
+		stopRecoveryMonitor(&metricItem.isUnhealthy, &metricItem.recoveryMonitorStop)
+		metricItem.recoveryMonitorMu.Unlock()
+	})
+
+	check := func(body string) error {
+		t.Helper()
+		metricConfig.RequestOptions = RequestOptions{httpClient: respond(http.StatusOK, body)}
+		_, err := checkGroupItem(metricConfig, 0, notifier, 3600)
+		return err
+	}
+	state := func() (time.Time, bool, bool) {
+		metricItem.recoveryMonitorMu.Lock()
+		defer metricItem.recoveryMonitorMu.Unlock()
+		return metricItem.missingSince, metricItem.isUnhealthy, metricItem.alerted
+	}
+
+	// Missing within the grace period isn't alerted, and isn't a failed check
+	if err := check("other 1\n"); err != nil {
+		t.Fatalf("checkGroupItem: %v", err)
+	}
+	if since, unhealthy, _ := state(); since.IsZero() || unhealthy {
+		t.Fatalf("expected the metric to be missing but not alerted, got since %v, unhealthy %v", since, unhealthy)
+	}
+
+	// Past the grace period
+	metricItem.recoveryMonitorMu.Lock()
+	metricItem.missingSince = time.Now().Add(-2 * time.Minute)
+	metricItem.recoveryMonitorMu.Unlock()
+	if err := check("other 1\n"); err != nil {
+		t.Fatalf("checkGroupItem: %v", err)
+	}
+	if _, unhealthy, alerted := state(); !unhealthy || !alerted {
+		t.Fatal("expected the missing metric to be alerted after the grace period")
+	}
+
+	if err := check("height 150\n"); err != nil {
+		t.Fatalf("checkGroupItem: %v", err)
+	}
+	if since, unhealthy, _ := state(); !since.IsZero() || unhealthy {
+		t.Fatalf("expected the metric to recover once it's back, got since %v, unhealthy %v", since, unhealthy)
+	}
+
+	// Without alert_on_missing a missing metric is a failed check
+	metricItem.AlertOnMissing = false
+	if err := check("other 1\n"); err == nil {
+		t.Fatal("expected an error for a missing metric without alert_on_missing")
+	}
+}
@@ -0,0 +1,567 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebhookOverride routes an item's alerts to a dedicated webhook and can
+// override the severity they are sent with
+type WebhookOverride struct {
+	WebhookURL  string `mapstructure:"webhook_url"`  // Optional per-item webhook for alerts and recoveries
+	WebhookOnly bool   `mapstructure:"webhook_only"` // Send only to the webhook instead of the global channels
+	Severity    string `mapstructure:"severity"`     // Optional severity instead of the item type's default
+
+	WebhookSecret string `mapstructure:"webhook_secret"` // Optional key to sign webhook payloads with HMAC-SHA256
+
+	PageAfter Seconds `mapstructure:"page_after"` // Optional: how long the item must be unhealthy before paging PagerDuty, Opsgenie and SMS
+}
+
+// validate checks that the webhook override is usable
+func (w WebhookOverride) validate() error {
+	if err := validateSeverity(w.Severity); err != nil {
+		return err
+	}
+	if w.PageAfter < 0 {
+		return fmt.Errorf("page_after must not be negative")
+	}
+
+	if w.WebhookURL == "" {
+		if w.WebhookOnly {
+			return fmt.Errorf("webhook_only requires webhook_url to be set")
+		}
+		if w.WebhookSecret != "" {
+			return fmt.Errorf("webhook_secret requires webhook_url to be set")
+		}
+		return nil
+	}
+
+	return validateWebhookURL(w.WebhookURL)
+}
+
+// alertSeverity returns the item's severity, falling back to the default of its type
+func (w WebhookOverride) alertSeverity(defaultSeverity string) string {
+	if w.Severity != "" {
+		return w.Severity
+	}
+	return defaultSeverity
+}
+
+// validateWebhookURL checks that a webhook URL is an absolute http(s) URL
+func validateWebhookURL(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook_url %q: %w", webhookURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook_url %q: must be an absolute http(s) URL", webhookURL)
+	}
+	return nil
+}
+
+// Overflow policies for the notification queue
+const (
+	overflowBlock      = "block"       // Wait for room in the queue
+	overflowDropOldest = "drop_oldest" // Discard the oldest queued notification
+	overflowDropNewest = "drop_newest" // Discard the notification being queued
+)
+
+// Notifier delivers alert and recovery messages to the configured channels
+type Notifier struct {
+	channels []notificationChannel // Global channels, in delivery order
+	source   string                // Host name reported as the PagerDuty and Opsgenie event source
+
+	deadLetterFile   string       // Append-only JSON lines file for undeliverable alerts
+	deadLetterReplay bool         // Retry dead-lettered alerts after the next successful delivery
+	deadLetterMu     sync.Mutex   // Guards the dead letter file and pending replays
+	pendingReplays   []deadLetter // Dead letters waiting to be replayed
+
+	queue          chan notification // Bounded queue drained by the delivery worker
+	overflowPolicy string            // What to do when the queue is full
+	enqueueMu      sync.Mutex        // Serializes drop_oldest evictions
+	dropped        atomic.Uint64     // Notifications discarded because the queue was full
+
+	deduper *alertDeduper // Suppresses identical alerts within the dedup window
+	limiter *alertLimiter // Caps the alerts sent per minute across all items
+
+	digest   bool               // Combine the alerts of a check cycle into one message
+	digestMu sync.Mutex         // Guards digests
+	digests  map[string]*digest // Digests of the check cycles in progress
+
+	history *alertHistory // Recent alerts and recoveries, served on /history
+}
+
+// notification is a message waiting in the queue
+type notification struct {
+	message  string
+	route    WebhookOverride
+	incident *incident    // Set for alerts and recoveries that page
+	scope    channelScope // Channels the notification is limited to
+}
+
+// deadLetter is an alert that could not be delivered to any channel
+type deadLetter struct {
+	Time        time.Time         `json:"time"`
+	Message     string            `json:"message"`
+	WebhookURL  string            `json:"webhook_url,omitempty"`
+	WebhookOnly bool              `json:"webhook_only,omitempty"`
+	Errors      map[string]string `json:"errors"`
+	EventAction string            `json:"event_action,omitempty"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Severity    string            `json:"severity,omitempty"`
+
+	webhookSecret string       // Kept in memory for replays only, never written to the file
+	scope         channelScope // Channels the alert was limited to
+}
+
+// newNotifier creates a notifier for the given channels and starts its delivery worker
+func newNotifier(channels []notificationChannel, config *Config) *Notifier {
+	n := &Notifier{
+		channels:         channels,
+		source:           agentSource(),
+		deadLetterFile:   config.DeadLetterFile,
+		deadLetterReplay: config.DeadLetterReplay,
+		queue:            make(chan notification, config.NotificationQueue.Size),
+		overflowPolicy:   config.NotificationQueue.OverflowPolicy,
+		deduper:          newAlertDeduper(time.Duration(config.DedupWindow) * time.Second),
+		limiter:          newAlertLimiter(config.MaxAlertsPerMinute),
+		digest:           config.Digest,
+		digests:          make(map[string]*digest),
+		history:          newAlertHistory(config.History),
+	}
+	go n.run()
+	return n
+}
+
+// Send queues a Telegram-formatted message for the global channels and the
+// item's webhook override, if any. It returns false when no channel would be
+// used, so callers can fall back to their stdout-only output.
+func (n *Notifier) Send(message string, route WebhookOverride) bool {
+	return n.send(notification{message: message, route: route}).chat
+}
+
+// delivery reports which channels an alert or recovery was queued for
+type delivery struct {
+	chat      bool // The global chat channels or the item's webhook
+	incidents bool // The channels that only take alerts and recoveries
+}
+
+// Alert is like Send but also triggers a PagerDuty incident with the given
+// dedup key and severity when PagerDuty is configured. Channels whose
+// min_severity is above the severity skip it. The details are the fields the
+// message was built from, for channels with structured messages. It reports
+// which channels the alert was queued for: none for a duplicate, and only the
+// incident channels for an alert over max_alerts_per_minute.
+func (n *Notifier) Alert(message string, route WebhookOverride, dedupKey, severity string, details messageData) delivery {
+	return n.alert(scopeAll, message, route, dedupKey, severity, details)
+}
+
+// AlertChat is like Alert but holds back the incident channels, for an item
+// whose page_after hasn't passed yet
+func (n *Notifier) AlertChat(message string, route WebhookOverride, dedupKey, severity string, details messageData) delivery {
+	return n.alert(scopeChat, message, route, dedupKey, severity, details)
+}
+
+// Page triggers the incident of an alert sent with AlertChat on the incident
+// channels only, once the item has been unhealthy for its page_after. The
+// chat channels already have the alert, so it bypasses the dedup window and
+// the alert cap.
+func (n *Notifier) Page(message string, route WebhookOverride, dedupKey, severity string, details messageData) bool {
+	job := notification{
+		message:  message,
+		route:    route,
+		incident: &incident{action: pagerDutyTrigger, dedupKey: dedupKey, severity: severity, details: &details},
+		scope:    scopeIncidents,
+	}
+	if !n.hasChannel(job) {
+		return false
+	}
+	n.enqueue(job)
+	return true
+}
+
+// alert sends an alert to the channels of the given scope
+func (n *Notifier) alert(scope channelScope, message string, route WebhookOverride, dedupKey, severity string, details messageData) delivery {
+	job := notification{
+		message:  message,
+		route:    route,
+		incident: &incident{action: pagerDutyTrigger, dedupKey: dedupKey, severity: severity, details: &details},
+		scope:    scope,
+	}
+	n.recordHistory("alert", message, job.incident, details)
+
+	// Alerts of items with their own webhook are sent on their own, the
+	// webhook only ever gets the item's alerts
+	d := n.activeDigest(job.incident.itemType(), details.Group)
+	if d == nil || route.WebhookURL != "" {
+		return n.send(job)
+	}
+
+	if !n.hasChannel(job) || n.deduper.duplicate(job.incident) {
+		return delivery{}
+	}
+
+	d.mu.Lock()
+	d.alerts = append(d.alerts, job)
+	d.mu.Unlock()
+
+	// Incidents stay per item so each one resolves with its recovery
+	sent := delivery{chat: n.hasGlobalChannel()}
+	if scope == scopeAll && n.hasIncidentChannel() {
+		job.scope = scopeIncidents
+		n.enqueue(job)
+		sent.incidents = true
+	}
+	return sent
+}
+
+// Resolve is like Send but also resolves the PagerDuty incident with the
+// given dedup key when PagerDuty is configured. The severity is that of the
+// alert, so the recovery reaches the same channels.
+func (n *Notifier) Resolve(message string, route WebhookOverride, dedupKey, severity string, details messageData) bool {
+	return n.resolve(scopeAll, message, route, dedupKey, severity, details)
+}
+
+// ResolveChat is like Resolve but leaves out the incident channels, for an
+// item that recovered before its page_after passed and so was never paged
+func (n *Notifier) ResolveChat(message string, route WebhookOverride, dedupKey, severity string, details messageData) bool {
+	return n.resolve(scopeChat, message, route, dedupKey, severity, details)
+}
+
+// ResolveIncidents is like Resolve but only for the incident channels, for an
+// item whose alert was held back from chat by max_alerts_per_minute
+func (n *Notifier) ResolveIncidents(message string, route WebhookOverride, dedupKey, severity string, details messageData) bool {
+	return n.resolve(scopeIncidents, message, route, dedupKey, severity, details)
+}
+
+// resolve sends a recovery to the channels of the given scope
+func (n *Notifier) resolve(scope channelScope, message string, route WebhookOverride, dedupKey, severity string, details messageData) bool {
+	job := notification{
+		message:  message,
+		route:    route,
+		incident: &incident{action: pagerDutyResolve, dedupKey: dedupKey, severity: severity, details: &details},
+		scope:    scope,
+	}
+	n.recordHistory("recovery", message, job.incident, details)
+	return n.send(job) != delivery{}
+}
+
+// recordHistory adds an alert or recovery to the history, whether or not a
+// channel is configured
+func (n *Notifier) recordHistory(event, message string, inc *incident, details messageData) {
+	n.history.add(historyEvent{
+		Time:     time.Now(),
+		Event:    event,
+		Type:     inc.itemType(),
+		Group:    details.Group,
+		Name:     details.Name,
+		Severity: inc.severity,
+		Message:  plainText(message),
+	})
+}
+
+// send queues a notification if any channel applies to it, and reports which
+// channels it was queued for
+func (n *Notifier) send(job notification) delivery {
+	if !n.hasChannel(job) || n.deduper.duplicate(job.incident) {
+		return delivery{}
+	}
+	incidents := job.scope != scopeChat && job.incident != nil && n.hasIncidentChannel()
+	if n.throttled(job) {
+		// Only the incident channels still get an alert over the cap
+		return delivery{incidents: incidents && job.scope == scopeAll}
+	}
+
+	n.enqueue(job)
+	return delivery{
+		chat:      job.scope != scopeIncidents && (job.route.WebhookURL != "" || n.hasGlobalChannel()),
+		incidents: incidents,
+	}
+}
+
+// Dropped returns how many notifications were discarded due to queue overflow
+func (n *Notifier) Dropped() uint64 {
+	return n.dropped.Load()
+}
+
+// hasChannel reports whether any channel applies to the given notification
+func (n *Notifier) hasChannel(job notification) bool {
+	if job.scope != scopeIncidents && (job.route.WebhookURL != "" || n.hasGlobalChannel()) {
+		return true
+	}
+	return job.scope != scopeChat && job.incident != nil && n.hasIncidentChannel()
+}
+
+// hasGlobalChannel reports whether any of the global channels is configured
+func (n *Notifier) hasGlobalChannel() bool {
+	return hasChatChannel(n.channels)
+}
+
+// hasIncidentChannel reports whether any channel that only takes alerts and
+// recoveries is configured
+func (n *Notifier) hasIncidentChannel() bool {
+	return hasIncidentsOnlyChannel(n.channels)
+}
+
+// enqueue adds a notification to the queue, applying the overflow policy when it's full
+func (n *Notifier) enqueue(job notification) {
+	if n.overflowPolicy == overflowBlock {
+		n.queue <- job
+		return
+	}
+
+	select {
+	case n.queue <- job:
+		return
+	default:
+	}
+
+	if n.overflowPolicy == overflowDropNewest {
+		n.drop(job)
+		return
+	}
+
+	n.enqueueMu.Lock()
+	defer n.enqueueMu.Unlock()
+	for {
+		select {
+		case n.queue <- job:
+			return
+		default:
+		}
+		select {
+		case oldest := <-n.queue:
+			n.drop(oldest)
+		default:
+		}
+	}
+}
+
+// drop counts and logs a notification discarded because the queue was full
+func (n *Notifier) drop(job notification) {
+	total := n.dropped.Add(1)
+	slog.Warn("notification queue full, dropped notification", "dropped_total", total,
+		"message", strings.SplitN(plainText(job.message), "\n", 2)[0])
+}
+
+// run delivers queued notifications one at a time
+func (n *Notifier) run() {
+	for job := range n.queue {
+		attempted, errs := n.deliver(job.message, job.route, job.incident, job.scope)
+		if attempted == 0 {
+			continue
+		}
+
+		if len(errs) == attempted {
+			n.recordDeadLetter(job, errs)
+		} else {
+			n.replayDeadLetters()
+		}
+	}
+}
+
+// deliver sends the message to every applicable channel and returns how many
+// channels were attempted along with the errors of those that failed
+func (n *Notifier) deliver(message string, route WebhookOverride, inc *incident, scope channelScope) (int, map[string]error) {
+	var attempted []string
+	errs := make(map[string]error)
+
+	global := !(route.WebhookOnly && route.WebhookURL != "")
+	chat := global && scope != scopeIncidents
+	incidents := global && scope != scopeChat
+
+	// Plain messages have no severity and pass every min_severity
+	severity := ""
+	if inc != nil {
+		severity = inc.severity
+	}
+
+	for _, channel := range n.channels {
+		if channel.incidentsOnly() && (inc == nil || !incidents) || !channel.incidentsOnly() && !chat {
+			continue
+		}
+		if !channel.accepts(severity) {
+			continue
+		}
+
+		attempted = append(attempted, channel.name())
+		if err := channel.send(message, inc); err != nil {
+			// Log the error but don't stop monitoring
+			attrs := []any{"channel", channel.name(), "error", err}
+			if channel.incidentsOnly() {
+				attrs = append(attrs, "event_action", inc.action)
+			}
+			slog.Error("failed to send notification", attrs...)
+			errs[channel.name()] = err
+		}
+	}
+
+	// The webhook is a chat channel of its own
+	if route.WebhookURL != "" && scope != scopeIncidents {
+		attempted = append(attempted, "webhook")
+		if err := sendWebhook(route.WebhookURL, route.WebhookSecret, plainText(message)); err != nil {
+			slog.Error("failed to send notification", "channel", "webhook", "url", route.WebhookURL, "error", err)
+			errs["webhook"] = err
+		}
+	}
+
+	// Count alerts that reached each channel, recoveries and plain messages aren't alerts
+	if inc != nil && inc.action == pagerDutyTrigger {
+		for _, channel := range attempted {
+			if errs[channel] == nil {
+				recordAlertSent(inc.itemType(), channel)
+			}
+		}
+	}
+
+	return len(attempted), errs
+}
+
+// recordDeadLetter persists an alert that no channel accepted so it isn't lost
+func (n *Notifier) recordDeadLetter(job notification, errs map[string]error) {
+	letter := deadLetter{
+		Time:        time.Now(),
+		Message:     job.message,
+		WebhookURL:  job.route.WebhookURL,
+		WebhookOnly: job.route.WebhookOnly,
+		Errors:      make(map[string]string, len(errs)),
+
+		webhookSecret: job.route.WebhookSecret,
+		scope:         job.scope,
+	}
+	if job.incident != nil {
+		letter.EventAction = job.incident.action
+		letter.DedupKey = job.incident.dedupKey
+		letter.Severity = job.incident.severity
+	}
+	for channel, err := range errs {
+		letter.Errors[channel] = err.Error()
+	}
+
+	n.deadLetterMu.Lock()
+	defer n.deadLetterMu.Unlock()
+
+	if n.deadLetterReplay {
+		n.pendingReplays = append(n.pendingReplays, letter)
+	}
+
+	if n.deadLetterFile == "" {
+		slog.Error("alert could not be delivered to any channel and no dead letter file is configured")
+		return
+	}
+
+	if err := appendDeadLetter(n.deadLetterFile, letter); err != nil {
+		slog.Error("error writing dead letter", "file", n.deadLetterFile, "error", err)
+		return
+	}
+	slog.Error("alert could not be delivered to any channel, written to dead letter file", "file", n.deadLetterFile)
+}
+
+// replayDeadLetters retries pending dead letters once a channel is reachable again
+func (n *Notifier) replayDeadLetters() {
+	n.deadLetterMu.Lock()
+	pending := n.pendingReplays
+	n.pendingReplays = nil
+	n.deadLetterMu.Unlock()
+
+	for _, letter := range pending {
+		route := WebhookOverride{WebhookURL: letter.WebhookURL, WebhookOnly: letter.WebhookOnly, WebhookSecret: letter.webhookSecret}
+		message := fmt.Sprintf("%s\n(delayed, originally raised at %s)", letter.Message, letter.Time.Format(time.RFC3339))
+
+		var inc *incident
+		if letter.EventAction != "" {
+			inc = &incident{action: letter.EventAction, dedupKey: letter.DedupKey, severity: letter.Severity}
+		}
+
+		attempted, errs := n.deliver(message, route, inc, letter.scope)
+		if attempted > 0 && len(errs) == attempted {
+			// Still undeliverable, keep it for the next attempt
+			n.deadLetterMu.Lock()
+			n.pendingReplays = append(n.pendingReplays, letter)
+			n.deadLetterMu.Unlock()
+			continue
+		}
+		slog.Info("replayed dead-lettered alert", "raised_at", letter.Time.Format(time.RFC3339))
+	}
+}
+
+// appendDeadLetter appends a dead letter as a single JSON line
+func appendDeadLetter(path string, letter deadLetter) error {
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("error encoding dead letter: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
+
+// markdownLinkPattern matches Telegram markdown links like [text](https://...)
+var markdownLinkPattern = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+
+// plainText strips the Telegram markdown code spans from a message and
+// writes links out as "text: url"
+func plainText(message string) string {
+	return markdownLinkPattern.ReplaceAllString(strings.ReplaceAll(message, "`", ""), "$1: $2")
+}
+
+// sendWebhook POSTs a message as {"text": "..."}, which Slack and Mattermost
+// compatible receivers accept as-is. With a secret, the raw request body is
+// signed with HMAC-SHA256 and the signature sent as "X-Signature: sha256=<hex>".
+func sendWebhook(webhookURL, secret, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set("X-Signature", webhookSignature(secret, payload))
+	}
+
+	resp, err := notificationClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook returned status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// webhookSignature returns the X-Signature header value for a request body
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
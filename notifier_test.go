@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingChannel is a notification channel that records what it was sent
+type recordingChannel struct {
+	channelName string
+	incidents   bool
+	minSeverity string
+	err         error
+	sent        *[]string
+	messages    *[]string // Optional, records the messages themselves
+}
+
+func (c recordingChannel) name() string        { return c.channelName }
+func (c recordingChannel) incidentsOnly() bool { return c.incidents }
+func (c recordingChannel) accepts(severity string) bool {
+	return meetsSeverity(severity, c.minSeverity)
+}
+func (c recordingChannel) send(message string, _ *incident) error {
+	*c.sent = append(*c.sent, c.channelName)
+	if c.messages != nil {
+		*c.messages = append(*c.messages, message)
+	}
+	return c.err
+}
+
+// TestDeliverFanOut checks which channels a notification is fanned out to
+func TestDeliverFanOut(t *testing.T) {
+	var sent []string
+	failure := errors.New("unreachable")
+	n := &Notifier{channels: []notificationChannel{
+		recordingChannel{channelName: "chat", sent: &sent},
+		recordingChannel{channelName: "critical-chat", minSeverity: severityCritical, err: failure, sent: &sent},
+		recordingChannel{channelName: "pager", incidents: true, sent: &sent},
+	}}
+
+	tests := []struct {
+		name     string
+		inc      *incident
+		scope    channelScope
+		route    WebhookOverride
+		expected []string
+		failed   int
+	}{
+		{name: "plain message", expected: []string{"chat", "critical-chat"}, failed: 1},
+		{name: "warning alert", inc: &incident{action: pagerDutyTrigger, severity: severityWarning}, expected: []string{"chat", "pager"}},
+		{name: "critical alert", inc: &incident{action: pagerDutyTrigger, severity: severityCritical}, expected: []string{"chat", "critical-chat", "pager"}, failed: 1},
+		{name: "chat scope", inc: &incident{action: pagerDutyTrigger, severity: severityWarning}, scope: scopeChat, expected: []string{"chat"}},
+		{name: "incidents scope", inc: &incident{action: pagerDutyTrigger, severity: severityWarning}, scope: scopeIncidents, expected: []string{"pager"}},
+		{name: "webhook only", inc: &incident{action: pagerDutyTrigger, severity: severityWarning}, route: WebhookOverride{WebhookURL: "http://127.0.0.1:1", WebhookOnly: true}, failed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent = nil
+			_, errs := n.deliver("message", tt.route, tt.inc, tt.scope)
+			if !reflect.DeepEqual(sent, tt.expected) {
+				t.Errorf("expected %v to be sent to, got %v", tt.expected, sent)
+			}
+			if len(errs) != tt.failed {
+				t.Errorf("expected %d failed channels, got %v", tt.failed, errs)
+			}
+		})
+	}
+}
+
+// TestAlertThrottling checks that alerts over max_alerts_per_minute only go
+// to incident channels and are summarized in one message
+func TestAlertThrottling(t *testing.T) {
+	var sent []string
+	limiter := newAlertLimiter(2)
+	limiter.summaryDelay = 10 * time.Millisecond
+	n := &Notifier{
+		channels: []notificationChannel{
+			recordingChannel{channelName: "chat", sent: &sent},
+			recordingChannel{channelName: "pager", incidents: true, sent: &sent},
+		},
+		queue:   make(chan notification, 10),
+		deduper: newAlertDeduper(0),
+		limiter: limiter,
+	}
+
+	for i := 0; i < 5; i++ {
+		n.Alert(fmt.Sprintf("alert %d", i), WebhookOverride{}, fmt.Sprintf("health/group/item-%d", i), severityCritical, messageData{})
+	}
+	// Recoveries are never throttled
+	n.Resolve("recovery", WebhookOverride{}, "health/group/item-0", severityCritical, messageData{})
+
+	var scopes []channelScope
+	for i := 0; i < 6; i++ {
+		scopes = append(scopes, (<-n.queue).scope)
+	}
+	expected := []channelScope{scopeAll, scopeAll, scopeIncidents, scopeIncidents, scopeIncidents, scopeAll}
+	if !reflect.DeepEqual(scopes, expected) {
+		t.Errorf("expected scopes %v, got %v", expected, scopes)
+	}
+
+	select {
+	case summary := <-n.queue:
+		if !strings.Contains(summary.message, "3 additional alerts suppressed") || summary.scope != scopeChat {
+			t.Errorf("expected a chat summary of 3 suppressed alerts, got %q in scope %v", summary.message, summary.scope)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a summary of the suppressed alerts")
+	}
+}
+
+// TestDigestThrottling checks that digests count against
+// max_alerts_per_minute, and that the alerts of a throttled digest are
+// summarized
+func TestDigestThrottling(t *testing.T) {
+	var sent []string
+	limiter := newAlertLimiter(1)
+	limiter.summaryDelay = 10 * time.Millisecond
+	n := &Notifier{
+		channels: []notificationChannel{recordingChannel{channelName: "chat", sent: &sent}},
+		queue:    make(chan notification, 10),
+		deduper:  newAlertDeduper(0),
+		limiter:  limiter,
+		digest:   true,
+		digests:  make(map[string]*digest),
+	}
+
+	for cycle := 0; cycle < 2; cycle++ {
+		d := n.startDigest("health", "group")
+		for i := 0; i < 2; i++ {
+			n.Alert(fmt.Sprintf("alert %d", i), WebhookOverride{}, fmt.Sprintf("health/group/item-%d-%d", cycle, i), severityCritical,
+				messageData{Group: "group"})
+		}
+		n.flushDigest(d)
+	}
+
+	if len(n.queue) != 1 {
+		t.Fatalf("expected only the first digest to be sent, got %d notifications", len(n.queue))
+	}
+	if first := <-n.queue; !strings.Contains(first.message, "2 alerts") {
+		t.Errorf("expected the first digest, got %q", first.message)
+	}
+
+	select {
+	case summary := <-n.queue:
+		if !strings.Contains(summary.message, "2 additional alerts suppressed") {
+			t.Errorf("expected a summary of the throttled digest's 2 alerts, got %q", summary.message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a summary of the throttled digest")
+	}
+}
+
+// TestMessageEmoji checks that messages, heartbeats included, start with plain
+// tags without use_emoji
+func TestMessageEmoji(t *testing.T) {
+	if got := severityEmoji(severityCritical); got != "🚨" {
+		t.Errorf("expected the critical emoji, got %q", got)
+	}
+
+	useEmoji = false
+	t.Cleanup(func() { useEmoji = true })
+	for emoji, tag := range map[string]string{severityEmoji(severityCritical): "[ALERT]", severityEmoji(severityWarning): "[WARN]", messageEmoji(emojiRecovery): "[RECOVERY]"} {
+		if emoji != tag {
+			t.Errorf("expected %q, got %q", tag, emoji)
+		}
+	}
+	if heartbeat := heartbeatMessage(map[string]*itemCounts{"health": {healthy: 1, unhealthy: 1}}); !strings.HasPrefix(heartbeat, "[DEGRADED] Heartbeat") {
+		t.Errorf("expected a heartbeat with a plain tag, got %q", heartbeat)
+	}
+}
+
+// TestNotificationsVerifyTLS checks that the global insecure_skip_verify only
+// applies to checks, and notifications still reject a bad certificate
+func TestNotificationsVerifyTLS(t *testing.T) {
+	noRetries(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	insecureSkipVerify = true
+	t.Cleanup(func() { insecureSkipVerify = false })
+
+	if _, err := httpGet(context.Background(), server.URL, RequestOptions{}); err != nil {
+		t.Fatalf("expected the check to skip verification, got %v", err)
+	}
+	if err := sendWebhook(server.URL, "", "alert"); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected the item webhook to reject the certificate, got %v", err)
+	}
+	if err := sendSlackMessage(SlackConfig{WebhookURL: server.URL}, "alert"); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected Slack to reject the certificate, got %v", err)
+	}
+}
+
+// capturedRequest is a notification request seen by captureNotifications
+type capturedRequest struct {
+	url    string
+	header http.Header
+	body   string
+}
+
+// captureNotifications answers every notification request with status
+// instead of the network, and returns the requests seen
+func captureNotifications(t *testing.T, status int) *[]capturedRequest {
+	t.Helper()
+	var requests []capturedRequest
+	client := notificationClient
+	notificationClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		requests = append(requests, capturedRequest{url: req.URL.String(), header: req.Header, body: string(body)})
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	})}
+	t.Cleanup(func() { notificationClient = client })
+	return &requests
+}
+
+// roundTripFunc adapts a function to an http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// TestWebhookOverride checks that an item's webhook gets its alerts as plain
+// text next to the global channels, or instead of them with webhook_only
+func TestWebhookOverride(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+	var sent []string
+	n := &Notifier{channels: []notificationChannel{recordingChannel{channelName: "chat", sent: &sent}}}
+	inc := &incident{action: pagerDutyTrigger, severity: severityWarning}
+
+	route := WebhookOverride{WebhookURL: "https://hooks.test/item"}
+	if _, errs := n.deliver("🚨 Alert: [group] `node` is unhealthy!", route, inc, scopeAll); len(errs) != 0 {
+		t.Fatalf("deliver: %v", errs)
+	}
+	if len(sent) != 1 || len(*requests) != 1 {
+		t.Fatalf("expected the alert on chat and the webhook, got %v and %d requests", sent, len(*requests))
+	}
+	if req := (*requests)[0]; req.url != route.WebhookURL || req.body != `{"text":"🚨 Alert: [group] node is unhealthy!"}` {
+		t.Errorf("expected the plain text alert on the item's webhook, got %+v", req)
+	}
+
+	sent = nil
+	route.WebhookOnly = true
+	n.deliver("alert", route, inc, scopeAll)
+	if len(sent) != 0 || len(*requests) != 2 {
+		t.Errorf("expected webhook_only to skip the global channels, got %v and %d requests", sent, len(*requests))
+	}
+
+	if err := (WebhookOverride{WebhookOnly: true}).validate(); err == nil {
+		t.Error("expected webhook_only without webhook_url to be rejected")
+	}
+}
+
+// TestWebhookSignature checks that a webhook with a secret gets the body's
+// HMAC-SHA256 signature, and one without a secret no signature at all
+func TestWebhookSignature(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+
+	if err := sendWebhook("https://hooks.test/signed", "s3cret", "alert"); err != nil {
+		t.Fatalf("sendWebhook: %v", err)
+	}
+	if err := sendWebhook("https://hooks.test/plain", "", "alert"); err != nil {
+		t.Fatalf("sendWebhook: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(`{"text":"alert"}`))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := (*requests)[0].header.Get("X-Signature"); got != expected {
+		t.Errorf("expected signature %q, got %q", expected, got)
+	}
+	if got := (*requests)[1].header.Get("X-Signature"); got != "" {
+		t.Errorf("expected no signature without a secret, got %q", got)
+	}
+
+	if err := (WebhookOverride{WebhookSecret: "s3cret"}).validate(); err == nil {
+		t.Error("expected webhook_secret without webhook_url to be rejected")
+	}
+}
+
+// TestDeadLetter checks that an alert no channel accepted is written to the
+// dead letter file and replayed once a channel is reachable again
+func TestDeadLetter(t *testing.T) {
+	var sent []string
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	n := &Notifier{
+		channels:         []notificationChannel{recordingChannel{channelName: "chat", err: errors.New("unreachable"), sent: &sent}},
+		queue:            make(chan notification, 1),
+		deadLetterFile:   path,
+		deadLetterReplay: true,
+	}
+
+	inc := &incident{action: pagerDutyTrigger, dedupKey: "health/group/node", severity: severityCritical}
+	n.queue <- notification{message: "alert", incident: inc}
+	close(n.queue)
+	n.run()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read dead letter file: %v", err)
+	}
+	var letter deadLetter
+	if err := json.Unmarshal(data, &letter); err != nil {
+		t.Fatalf("decode dead letter: %v", err)
+	}
+	if letter.Message != "alert" || letter.DedupKey != inc.dedupKey || letter.Errors["chat"] != "unreachable" {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+
+	// Still unreachable, the letter stays pending
+	n.replayDeadLetters()
+	if len(n.pendingReplays) != 1 {
+		t.Fatalf("expected the undeliverable letter to stay pending, got %d", len(n.pendingReplays))
+	}
+
+	var delivered []string
+	n.channels = []notificationChannel{recordingChannel{channelName: "chat", sent: &sent, messages: &delivered}}
+	n.replayDeadLetters()
+	if len(delivered) != 1 || !strings.HasPrefix(delivered[0], "alert\n(delayed, originally raised at ") {
+		t.Errorf("expected the delayed alert to be replayed, got %q", delivered)
+	}
+	if len(n.pendingReplays) != 0 {
+		t.Errorf("expected no pending replays, got %d", len(n.pendingReplays))
+	}
+}
+
+// TestQueueOverflow checks which notification each overflow policy discards
+// when the queue is full
+func TestQueueOverflow(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected []string
+	}{
+		{policy: overflowDropOldest, expected: []string{"second", "third"}},
+		{policy: overflowDropNewest, expected: []string{"first", "second"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			n := &Notifier{queue: make(chan notification, 2), overflowPolicy: tt.policy}
+			for _, message := range []string{"first", "second", "third"} {
+				n.enqueue(notification{message: message})
+			}
+			close(n.queue)
+
+			var queued []string
+			for job := range n.queue {
+				queued = append(queued, job.message)
+			}
+			if !reflect.DeepEqual(queued, tt.expected) {
+				t.Errorf("expected %v queued, got %v", tt.expected, queued)
+			}
+			if n.Dropped() != 1 {
+				t.Errorf("expected 1 dropped notification, got %d", n.Dropped())
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Opsgenie Alert API endpoints per region
+var opsgenieAPIURLs = map[string]string{
+	"us": "https://api.opsgenie.com/v2/alerts",
+	"eu": "https://api.eu.opsgenie.com/v2/alerts",
+}
+
+// Length limits of the Opsgenie Alert API
+const (
+	opsgenieMessageMaxLength     = 130
+	opsgenieDescriptionMaxLength = 15000
+)
+
+// opsgeniePriorities maps alert severities to Opsgenie priorities
+var opsgeniePriorities = map[string]string{
+	severityCritical: "P1",
+	severityWarning:  "P3",
+	severityInfo:     "P5",
+}
+
+type OpsgenieConfig struct {
+	APIKey      string `mapstructure:"api_key"`      // API key of an Opsgenie API integration
+	Region      string `mapstructure:"region"`       // Optional: us (default) or eu
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest severity to create alerts for
+}
+
+// validate checks the region and applies its default
+func (o *OpsgenieConfig) validate() error {
+	if o.Region == "" {
+		o.Region = "us"
+	}
+	if _, ok := opsgenieAPIURLs[o.Region]; !ok {
+		return fmt.Errorf("invalid region %q: must be us or eu", o.Region)
+	}
+	return nil
+}
+
+type opsgenieAlert struct {
+	Message     string `json:"message"`
+	Alias       string `json:"alias"`
+	Description string `json:"description,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+type opsgenieClose struct {
+	Source string `json:"source,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// sendOpsgenieEvent creates an Opsgenie alert for a trigger and closes it for
+// a resolve. The incident's dedup key is used as the alias, so Opsgenie
+// dedupes repeated alerts of an item and the recovery closes the right one.
+// The message is plain text, its first line becomes the alert message.
+func (o OpsgenieConfig) sendOpsgenieEvent(inc incident, message, source string) error {
+	apiURL := opsgenieAPIURLs[o.Region]
+
+	var body any
+	if inc.action == pagerDutyTrigger {
+		summary := strings.SplitN(message, "\n", 2)[0]
+		if runes := []rune(summary); len(runes) > opsgenieMessageMaxLength {
+			summary = string(runes[:opsgenieMessageMaxLength])
+		}
+		description := message
+		if runes := []rune(description); len(runes) > opsgenieDescriptionMaxLength {
+			description = string(runes[:opsgenieDescriptionMaxLength])
+		}
+		body = opsgenieAlert{
+			Message:     summary,
+			Alias:       inc.dedupKey,
+			Description: description,
+			Priority:    opsgeniePriorities[inc.severity],
+			Source:      source,
+		}
+	} else {
+		apiURL = fmt.Sprintf("%s/%s/close?identifierType=alias", apiURL, url.PathEscape(inc.dedupKey))
+		body = opsgenieClose{Source: source, Note: strings.SplitN(message, "\n", 2)[0]}
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.APIKey)
+
+	resp, err := notificationClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Opsgenie answers 202 Accepted and processes requests asynchronously
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("opsgenie returned status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
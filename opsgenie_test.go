@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestOpsgenieEvent checks that a trigger creates an alert aliased by the
+// dedup key and that a resolve closes the alert with that alias
+func TestOpsgenieEvent(t *testing.T) {
+	requests := captureNotifications(t, http.StatusAccepted)
+	opsgenie := OpsgenieConfig{APIKey: "key", Region: "eu"}
+	key := dedupKey("health", "group", "node")
+
+	message := strings.Repeat("x", 200) + "\ndetails"
+	if err := opsgenie.sendOpsgenieEvent(incident{action: pagerDutyTrigger, dedupKey: key, severity: severityCritical}, message, "agent-host"); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	if err := opsgenie.sendOpsgenieEvent(incident{action: pagerDutyResolve, dedupKey: key}, "recovered\ndetails", "agent-host"); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*requests))
+	}
+
+	created, closed := (*requests)[0], (*requests)[1]
+	if created.url != "https://api.eu.opsgenie.com/v2/alerts" || created.header.Get("Authorization") != "GenieKey key" {
+		t.Errorf("unexpected create request to %s with %v", created.url, created.header)
+	}
+	var alert opsgenieAlert
+	if err := json.Unmarshal([]byte(created.body), &alert); err != nil {
+		t.Fatalf("decode alert: %v", err)
+	}
+	if len(alert.Message) != opsgenieMessageMaxLength || alert.Alias != key || alert.Priority != "P1" || alert.Description != message {
+		t.Errorf("unexpected alert %+v", alert)
+	}
+
+	if closed.url != "https://api.eu.opsgenie.com/v2/alerts/health%2Fgroup%2Fnode/close?identifierType=alias" {
+		t.Errorf("unexpected close URL %s", closed.url)
+	}
+	var closeBody opsgenieClose
+	if err := json.Unmarshal([]byte(closed.body), &closeBody); err != nil {
+		t.Fatalf("decode close: %v", err)
+	}
+	if closeBody.Note != "recovered" || closeBody.Source != "agent-host" {
+		t.Errorf("unexpected close %+v", closeBody)
+	}
+
+	if err := (&OpsgenieConfig{Region: "ap"}).validate(); err == nil {
+		t.Error("expected an unknown region to be rejected")
+	}
+}
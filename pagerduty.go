@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySummaryMaxLength is the longest summary PagerDuty accepts
+const pagerDutySummaryMaxLength = 1024
+
+// PagerDuty event actions
+const (
+	pagerDutyTrigger = "trigger"
+	pagerDutyResolve = "resolve"
+)
+
+type PagerDutyConfig struct {
+	RoutingKey  string `mapstructure:"routing_key"`  // Integration key of an Events API v2 service
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest severity to page for
+}
+
+// incident identifies the PagerDuty incident an alert or recovery belongs to
+type incident struct {
+	action   string // trigger or resolve
+	dedupKey string // Stable key so triggers and resolves hit the same incident
+	severity string // Filters channels by min_severity, sent to PagerDuty with triggers
+
+	details *messageData // Fields of the item, nil for dead letters and test alerts
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary  string `json:"summary"`
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+}
+
+// dedupKey builds a stable PagerDuty dedup key for a monitored item from its
+// type, group and identifying fields
+func dedupKey(itemType, group string, parts ...string) string {
+	return strings.Join(append([]string{itemType, group}, parts...), "/")
+}
+
+// itemType returns the item type the incident's dedup key starts with
+func (i incident) itemType() string {
+	return strings.SplitN(i.dedupKey, "/", 2)[0]
+}
+
+// sendPagerDutyEvent sends a trigger or resolve event to the PagerDuty Events API v2.
+// The summary is only sent with triggers, resolves just close the incident.
+func sendPagerDutyEvent(routingKey string, inc incident, summary, source string) error {
+	event := pagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: inc.action,
+		DedupKey:    inc.dedupKey,
+	}
+	if inc.action == pagerDutyTrigger {
+		if runes := []rune(summary); len(runes) > pagerDutySummaryMaxLength {
+			summary = string(runes[:pagerDutySummaryMaxLength])
+		}
+		event.Payload = &pagerDutyPayload{
+			Summary:  summary,
+			Source:   source,
+			Severity: inc.severity,
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	resp, err := notificationClient.Post(pagerDutyEventsURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// PagerDuty answers 202 Accepted for queued events
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pagerduty returned status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
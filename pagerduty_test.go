@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestPagerDutyEvent checks that triggers carry a truncated summary and
+// resolves only close the incident with the same dedup key
+func TestPagerDutyEvent(t *testing.T) {
+	requests := captureNotifications(t, http.StatusAccepted)
+	key := dedupKey("health", "group", "node")
+
+	trigger := incident{action: pagerDutyTrigger, dedupKey: key, severity: severityCritical}
+	if err := sendPagerDutyEvent("routing-key", trigger, strings.Repeat("x", 2000), "agent-host"); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	if err := sendPagerDutyEvent("routing-key", incident{action: pagerDutyResolve, dedupKey: key}, "recovered", "agent-host"); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(*requests) != 2 || (*requests)[0].url != pagerDutyEventsURL {
+		t.Fatalf("expected two events to the Events API, got %+v", *requests)
+	}
+
+	var events [2]pagerDutyEvent
+	for i, req := range *requests {
+		if err := json.Unmarshal([]byte(req.body), &events[i]); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+	}
+
+	if events[0].RoutingKey != "routing-key" || events[0].EventAction != pagerDutyTrigger || events[0].DedupKey != "health/group/node" {
+		t.Errorf("unexpected trigger %+v", events[0])
+	}
+	if p := events[0].Payload; p == nil || len(p.Summary) != pagerDutySummaryMaxLength || p.Source != "agent-host" || p.Severity != severityCritical {
+		t.Errorf("expected a truncated critical payload, got %+v", p)
+	}
+	if events[1].EventAction != pagerDutyResolve || events[1].DedupKey != key || events[1].Payload != nil {
+		t.Errorf("expected a resolve without payload, got %+v", events[1])
+	}
+	if inc := (incident{dedupKey: key}); inc.itemType() != "health" {
+		t.Errorf("expected item type health, got %q", inc.itemType())
+	}
+}
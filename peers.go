@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type PeerCountItem struct {
+	Name             string  `mapstructure:"name"`
+	Endpoint         string  `mapstructure:"endpoint"`          // Tendermint RPC URL, e.g. http://node:26657
+	MinPeers         int     `mapstructure:"min_peers"`         // Optional per-endpoint override of the group min_peers
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-endpoint cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-endpoint recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type PeerCountConfig struct {
+	Name          string          `mapstructure:"name"`
+	CheckInterval Seconds         `mapstructure:"check_interval"` // Optional per-group check interval
+	MinPeers      int             `mapstructure:"min_peers"`      // Alert when a node has fewer peers
+	Endpoints     []PeerCountItem `mapstructure:"endpoints"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+type NetInfoResponse struct {
+	Result struct {
+		NPeers json.RawMessage `json:"n_peers"`
+	} `json:"result"`
+}
+
+// validatePeerChecks validates the peer count groups and prepares their items
+func validatePeerChecks(config *Config) error {
+	for i, peerGroup := range config.PeerChecks {
+		if peerGroup.Name == "" {
+			config.PeerChecks[i].Name = fmt.Sprintf("Peer Check Group %d", i+1) // Set default name if not provided
+			peerGroup.Name = config.PeerChecks[i].Name
+		}
+		if err := peerGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("peer check group '%s': %w", config.PeerChecks[i].Name, err)
+		}
+		if peerGroup.MinPeers < 0 {
+			return fmt.Errorf("min_peers must not be negative for peer check group '%s'", peerGroup.Name)
+		}
+
+		for j, endpoint := range peerGroup.Endpoints {
+			item := &config.PeerChecks[i].Endpoints[j]
+			if endpoint.Endpoint == "" {
+				return fmt.Errorf("endpoint is required for peer check item #%d in group '%s'", j+1, peerGroup.Name)
+			}
+			if err := validateEndpoint(endpoint.Endpoint); err != nil {
+				return fmt.Errorf("peer check item #%d in group '%s': %w", j+1, peerGroup.Name, err)
+			}
+			if endpoint.MinPeers < 0 {
+				return fmt.Errorf("min_peers must not be negative for peer check endpoint '%s' in group '%s'", endpoint.Endpoint, peerGroup.Name)
+			}
+			if item.minPeers(&peerGroup) == 0 {
+				return fmt.Errorf("min_peers is required for peer check endpoint '%s' in group '%s'", endpoint.Endpoint, peerGroup.Name)
+			}
+			if endpoint.Name == "" {
+				item.Name = fmt.Sprintf("Node %d", j+1) // Set default name if not provided
+			}
+			if err := endpoint.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("peer check endpoint '%s' in group '%s': %w", endpoint.Endpoint, peerGroup.Name, err)
+			}
+			if endpoint.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for peer check endpoint '%s' in group '%s'", endpoint.Endpoint, peerGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// minPeers returns the item's min_peers, falling back to the group setting
+func (p *PeerCountItem) minPeers(peerConfig *PeerCountConfig) int {
+	if p.MinPeers > 0 {
+		return p.MinPeers
+	}
+	return peerConfig.MinPeers
+}
+
+// netInfoURL returns the /net_info URL of a Tendermint RPC endpoint, which
+// may already point at it
+func netInfoURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), "/net_info") {
+		return endpoint, nil
+	}
+	return joinEndpoint(endpoint, "/net_info")
+}
+
+// getPeerCount fetches the number of peers a node is connected to
+func getPeerCount(ctx context.Context, endpoint string, opts RequestOptions) (int, error) {
+	infoURL, err := netInfoURL(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := httpGet(ctx, infoURL, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("API returned %w", unexpectedStatus(resp))
+	}
+
+	var netInfo NetInfoResponse
+	if err := json.Unmarshal(resp.Body, &netInfo); err != nil {
+		return 0, fmt.Errorf("error parsing response: %w", err)
+	}
+	if len(netInfo.Result.NPeers) == 0 {
+		return 0, fmt.Errorf("result.n_peers not found in response")
+	}
+
+	// Tendermint returns the count as a quoted number, accept a bare one too
+	raw := string(netInfo.Result.NPeers)
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	peers, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || peers < 0 {
+		return 0, fmt.Errorf("invalid n_peers %s", string(netInfo.Result.NPeers))
+	}
+	return peers, nil
+}
+
+// peerCountCheck checks a node's peer count against its group's min_peers
+type peerCountCheck struct {
+	group *PeerCountConfig
+	item  *PeerCountItem
+}
+
+// Check fetches the peer count and reports the node unhealthy below min_peers
+func (c peerCountCheck) Check(ctx context.Context) (bool, string, error) {
+	peers, err := getPeerCount(ctx, c.item.Endpoint, c.group.RequestOptions)
+	if err != nil {
+		return false, "", fmt.Errorf("error checking %s: %w", c.item.Name, err)
+	}
+	return peers >= c.item.minPeers(c.group), strconv.Itoa(peers), nil
+}
+
+// checkGroup implementation for peer count groups, run by runMonitor
+
+func (c *PeerCountConfig) checkType() string      { return "peers" }
+func (c *PeerCountConfig) groupName() string      { return c.Name }
+func (c *PeerCountConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *PeerCountConfig) itemCount() int         { return len(c.Endpoints) }
+
+func (c *PeerCountConfig) item(i int) monitoredItem {
+	item := &c.Endpoints[i]
+	return monitoredItem{
+		Checker: peerCountCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Endpoint,
+		threshold: strconv.Itoa(item.minPeers(c)), severity: severityWarning,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
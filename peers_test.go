@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestPeerCountCheck checks that a node is unhealthy below min_peers and that
+// quoted and bare n_peers are both read
+func TestPeerCountCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		healthy  bool
+		peers    string
+		hasError bool
+	}{
+		{name: "enough peers", body: `{"result":{"n_peers":"12"}}`, healthy: true, peers: "12"},
+		{name: "bare number", body: `{"result":{"n_peers":5}}`, healthy: true, peers: "5"},
+		{name: "too few peers", body: `{"result":{"n_peers":"4"}}`, peers: "4"},
+		{name: "missing n_peers", body: `{"result":{}}`, hasError: true},
+		{name: "invalid n_peers", body: `{"result":{"n_peers":"-1"}}`, hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var url string
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				url = req.URL.String()
+				return respond(http.StatusOK, tt.body)(req)
+			})
+			group := &PeerCountConfig{MinPeers: 5, RequestOptions: RequestOptions{httpClient: client}}
+			item := &PeerCountItem{Name: "node", Endpoint: "http://node.test:26657/"}
+
+			healthy, peers, err := peerCountCheck{group: group, item: item}.Check(context.Background())
+			if url != "http://node.test:26657/net_info" {
+				t.Errorf("expected a request to /net_info, got %s", url)
+			}
+			if (err != nil) != tt.hasError || healthy != tt.healthy || peers != tt.peers {
+				t.Errorf("expected healthy %v with %q and error %v, got %v with %q (%v)", tt.healthy, tt.peers, tt.hasError, healthy, peers, err)
+			}
+		})
+	}
+}
+
+// TestValidatePeerChecks checks that min_peers is required on the group or
+// the endpoint, with the endpoint's taking precedence
+func TestValidatePeerChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    PeerCountConfig
+		valid    bool
+		minPeers int
+	}{
+		{name: "group min_peers", group: PeerCountConfig{MinPeers: 5, Endpoints: []PeerCountItem{{Endpoint: "http://node.test:26657"}}}, valid: true, minPeers: 5},
+		{name: "endpoint override", group: PeerCountConfig{MinPeers: 5, Endpoints: []PeerCountItem{{Endpoint: "http://node.test:26657", MinPeers: 8}}}, valid: true, minPeers: 8},
+		{name: "no min_peers", group: PeerCountConfig{Endpoints: []PeerCountItem{{Endpoint: "http://node.test:26657"}}}},
+		{name: "negative min_peers", group: PeerCountConfig{MinPeers: -1, Endpoints: []PeerCountItem{{Endpoint: "http://node.test:26657"}}}},
+		{name: "no endpoint", group: PeerCountConfig{MinPeers: 5, Endpoints: []PeerCountItem{{}}}},
+	}
+
+	for _, tt := range tests {
+		config := &Config{PeerChecks: []PeerCountConfig{tt.group}}
+		err := validatePeerChecks(config)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %v, got %v", tt.name, tt.valid, err)
+			continue
+		}
+		if tt.valid {
+			group := &config.PeerChecks[0]
+			if got := group.Endpoints[0].minPeers(group); got != tt.minPeers {
+				t.Errorf("%s: expected min_peers %d, got %d", tt.name, tt.minPeers, got)
+			}
+		}
+	}
+}
+
+// TestNetInfoURL checks that /net_info is joined onto IPv6 hosts and base
+// paths, and left alone on an endpoint that already points at it
+func TestNetInfoURL(t *testing.T) {
+	for endpoint, expected := range map[string]string{
+		"http://[2001:db8::1]:26657":          "http://[2001:db8::1]:26657/net_info",
+		"https://rpc.test/dymension/":         "https://rpc.test/dymension/net_info",
+		"http://node.test:26657/net_info":     "http://node.test:26657/net_info",
+		"http://node.test:26657/net_info?x=1": "http://node.test:26657/net_info?x=1",
+	} {
+		if got, err := netInfoURL(endpoint); got != expected || err != nil {
+			t.Errorf("%s: expected %s, got %s (%v)", endpoint, expected, got, err)
+		}
+	}
+}
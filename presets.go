@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// withPreset returns the preset with the fields set on the threshold itself
+// on top, so an item can reference a preset and override part of it
+func (t BalanceThreshold) withPreset(preset BalanceThreshold) BalanceThreshold {
+	if t.Denom != "" {
+		preset.Denom = t.Denom
+	}
+	// Either amount replaces both of the preset's, one is in base units and
+	// the other in the display denom
+	if t.Amount != "" || t.DisplayAmount != "" {
+		preset.Amount = t.Amount
+		preset.DisplayAmount = t.DisplayAmount
+	}
+	if t.Decimals != 0 {
+		preset.Decimals = t.Decimals
+	}
+	if t.DisplayDenom != "" {
+		preset.DisplayDenom = t.DisplayDenom
+	}
+	return preset
+}
+
+// applyThresholdPresets resolves the threshold_preset of every address
+// against the presets section, before the thresholds are validated
+func applyThresholdPresets(config *Config) error {
+	for i, addrGroup := range config.Addresses {
+		for j, addr := range addrGroup.Addresses {
+			if addr.ThresholdPreset == "" {
+				continue
+			}
+
+			// Viper lowercases map keys, so preset names match case-insensitively
+			preset, ok := config.Presets[strings.ToLower(addr.ThresholdPreset)]
+			if !ok {
+				return fmt.Errorf("address '%s' in group '%s': unknown threshold_preset '%s'", addr.Address, addrGroup.Name, addr.ThresholdPreset)
+			}
+			config.Addresses[i].Addresses[j].Threshold = addr.Threshold.withPreset(preset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyThresholdPresets(t *testing.T) {
+	config := &Config{
+		Presets: map[string]BalanceThreshold{
+			"low-atom": {Denom: "uatom", Amount: "1000000", Decimals: 6, DisplayDenom: "ATOM"},
+		},
+		Addresses: []AddressConfig{{
+			Name: "group",
+			Addresses: []AddressItem{
+				{Address: "cosmos1a", ThresholdPreset: "Low-Atom"},
+				{Address: "cosmos1b", ThresholdPreset: "low-atom", Threshold: BalanceThreshold{Amount: "5000000"}},
+				{Address: "cosmos1c", Threshold: BalanceThreshold{Denom: "uosmo", Amount: "1"}},
+			},
+		}},
+	}
+
+	if err := applyThresholdPresets(config); err != nil {
+		t.Fatalf("applyThresholdPresets: %v", err)
+	}
+
+	expected := []BalanceThreshold{
+		{Denom: "uatom", Amount: "1000000", Decimals: 6, DisplayDenom: "ATOM"},
+		{Denom: "uatom", Amount: "5000000", Decimals: 6, DisplayDenom: "ATOM"},
+		{Denom: "uosmo", Amount: "1"},
+	}
+	for i, addr := range config.Addresses[0].Addresses {
+		if addr.Threshold != expected[i] {
+			t.Errorf("address %s: expected threshold %+v, got %+v", addr.Address, expected[i], addr.Threshold)
+		}
+	}
+}
+
+func TestApplyThresholdPresetsUnknown(t *testing.T) {
+	config := &Config{
+		Addresses: []AddressConfig{{
+			Name:      "group",
+			Addresses: []AddressItem{{Address: "cosmos1a", ThresholdPreset: "missing"}},
+		}},
+	}
+
+	err := applyThresholdPresets(config)
+	if err == nil || !strings.Contains(err.Error(), "unknown threshold_preset 'missing'") {
+		t.Fatalf("expected an unknown preset error, got %v", err)
+	}
+}
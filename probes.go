@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// probeServer answers liveness and readiness probes for the agent itself
+type probeServer struct {
+	ready   atomic.Bool
+	groups  map[string]int      // Monitored groups per config section, reported by /readyz
+	status  func() []statusItem // Live state of every item, reported by /status
+	history *alertHistory       // Recent alerts and recoveries, reported by /history
+	server  *http.Server
+	done    chan struct{} // Closed once the server has stopped
+}
+
+type readinessResponse struct {
+	Ready  bool           `json:"ready"`
+	Groups map[string]int `json:"groups,omitempty"`
+}
+
+type statusResponse struct {
+	Items []statusItem `json:"items"`
+}
+
+type historyResponse struct {
+	Events []historyEvent `json:"events"`
+}
+
+// monitoredGroups counts the configured groups of each monitor type
+func monitoredGroups(config *Config) map[string]int {
+	groups := map[string]int{
+		"metrics":           len(config.Metrics),
+		"addresses":         len(config.Addresses),
+		"kaspa_addresses":   len(config.KaspaAddresses),
+		"evm_addresses":     len(config.EVMAddresses),
+		"btc_addresses":     len(config.BTCAddresses),
+		"health":            len(config.Health),
+		"kaspa_validators":  len(config.KaspaValidators),
+		"header_checks":     len(config.HeaderChecks),
+		"grant_checks":      len(config.GrantChecks),
+		"block_height":      len(config.BlockHeight),
+		"tcp_checks":        len(config.TCPChecks),
+		"grpc_health":       len(config.GRPCHealth),
+		"cert_checks":       len(config.CertChecks),
+		"dns_checks":        len(config.DNSChecks),
+		"cosmos_validators": len(config.CosmosValidators),
+		"peer_checks":       len(config.PeerChecks),
+		"sync_checks":       len(config.SyncChecks),
+	}
+	for name, count := range groups {
+		if count == 0 {
+			delete(groups, name)
+		}
+	}
+	return groups
+}
+
+func newProbeServer(listen string, groups map[string]int, status func() []statusItem, history *alertHistory) *probeServer {
+	p := &probeServer{groups: groups, status: status, history: history, done: make(chan struct{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		// The process is up if it can answer
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		resp := readinessResponse{Ready: p.ready.Load()}
+		status := http.StatusServiceUnavailable
+		if resp.Ready {
+			resp.Groups = p.groups
+			status = http.StatusOK
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		resp := statusResponse{Items: p.status()}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		resp := historyResponse{Events: p.history.recent()}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	p.server = &http.Server{Addr: listen, Handler: mux}
+	return p
+}
+
+// serve answers probes until ctx is cancelled
+func (p *probeServer) serve(ctx context.Context) {
+	defer close(p.done)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := p.server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("error shutting down health probes", "error", err)
+		}
+	}()
+
+	slog.Info("serving health probes", "address", p.server.Addr, "paths", "/healthz,/readyz,/status,/history")
+	if err := p.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("error serving health probes", "address", p.server.Addr, "error", err)
+		return
+	}
+	<-stopped
+}
+
+// markReady makes /readyz succeed once every monitor has started
+func (p *probeServer) markReady() {
+	p.ready.Store(true)
+}
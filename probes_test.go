@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestProbes checks that /healthz always succeeds and /readyz only once the
+// agent is marked ready, listing its monitored groups
+func TestProbes(t *testing.T) {
+	groups := monitoredGroups(&Config{Health: []HealthConfig{{}, {}}, TCPChecks: []TCPCheckConfig{{}}})
+	p := newProbeServer("127.0.0.1:0", groups, nil, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := get("/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("expected /healthz to succeed, got %d", rec.Code)
+	}
+	if rec := get("/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected /readyz to fail before ready, got %d", rec.Code)
+	}
+
+	p.markReady()
+	rec := get("/readyz")
+	var resp readinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode /readyz: %v", err)
+	}
+	if rec.Code != http.StatusOK || !resp.Ready {
+		t.Errorf("expected /readyz to succeed once ready, got %d", rec.Code)
+	}
+	if len(resp.Groups) != 2 || resp.Groups["health"] != 2 || resp.Groups["tcp_checks"] != 1 {
+		t.Errorf("expected only the configured group counts, got %v", resp.Groups)
+	}
+}
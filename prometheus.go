@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of exported metric families
+const (
+	metricKindGauge   = "gauge"
+	metricKindCounter = "counter"
+	metricKindSummary = "summary"
+)
+
+// metricDesc describes an exported metric family
+type metricDesc struct {
+	kind string
+	help string
+}
+
+// metricRegistry holds the latest value of every exported series. Label values
+// only ever come from the config (group, item, address, denom, metric names)
+// or from fixed sets (item types, channels, results), so the number of series
+// is bounded by the number of configured items.
+type metricRegistry struct {
+	mu     sync.Mutex
+	descs  map[string]metricDesc
+	values map[string]map[string]float64 // sample name -> rendered labels -> value
+}
+
+// stateMetrics exposes the monitored state and the agent's own activity for external Prometheus scraping
+var stateMetrics = newMetricRegistry(map[string]metricDesc{
+	"alertagent_balance":                {metricKindGauge, "Latest balance of a monitored address in base units."},
+	"alertagent_balance_threshold":      {metricKindGauge, "Configured balance threshold of a monitored address in base units."},
+	"alertagent_metric_value":           {metricKindGauge, "Latest value of a monitored Prometheus metric."},
+	"alertagent_item_alerting":          {metricKindGauge, "Whether a monitored item is currently breaching its alert condition (1) or not (0)."},
+	"alertagent_alerts_sent_total":      {metricKindCounter, "Alerts delivered, by item type and channel."},
+	"alertagent_checks_total":           {metricKindCounter, "Checks run, by item type and result (ok or error)."},
+	"alertagent_check_duration_seconds": {metricKindSummary, "Time spent running checks, by item type."},
+})
+
+func newMetricRegistry(descs map[string]metricDesc) *metricRegistry {
+	return &metricRegistry{
+		descs:  descs,
+		values: make(map[string]map[string]float64),
+	}
+}
+
+// series returns the values of a sample name, creating it if needed. The caller must hold mu.
+func (r *metricRegistry) series(name string) map[string]float64 {
+	series, ok := r.values[name]
+	if !ok {
+		series = make(map[string]float64)
+		r.values[name] = series
+	}
+	return series
+}
+
+// Set records a gauge value. Labels are given as alternating name/value pairs.
+func (r *metricRegistry) Set(name string, value float64, labels ...string) {
+	rendered := renderLabels(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.series(name)[rendered] = value
+}
+
+// SetBool records 1 for true and 0 for false
+func (r *metricRegistry) SetBool(name string, value bool, labels ...string) {
+	if value {
+		r.Set(name, 1, labels...)
+	} else {
+		r.Set(name, 0, labels...)
+	}
+}
+
+// Add increments a counter
+func (r *metricRegistry) Add(name string, delta float64, labels ...string) {
+	rendered := renderLabels(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.series(name)[rendered] += delta
+}
+
+// Observe records one observation of a summary as its _sum and _count samples
+func (r *metricRegistry) Observe(name string, value float64, labels ...string) {
+	rendered := renderLabels(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.series(name + "_sum")[rendered] += value
+	r.series(name + "_count")[rendered]++
+}
+
+// writeTo renders all metric families in the Prometheus text exposition format
+func (r *metricRegistry) writeTo(b *strings.Builder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.descs))
+	for name := range r.descs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		desc := r.descs[name]
+		samples := []string{name}
+		if desc.kind == metricKindSummary {
+			samples = []string{name + "_sum", name + "_count"}
+		}
+		if len(r.values[samples[0]]) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(b, "# HELP %s %s\n", name, desc.help)
+		fmt.Fprintf(b, "# TYPE %s %s\n", name, desc.kind)
+
+		for _, sample := range samples {
+			series := r.values[sample]
+			labelSets := make([]string, 0, len(series))
+			for labels := range series {
+				labelSets = append(labelSets, labels)
+			}
+			sort.Strings(labelSets)
+
+			for _, labels := range labelSets {
+				fmt.Fprintf(b, "%s%s %g\n", sample, labels, series[labels])
+			}
+		}
+	}
+}
+
+// renderLabels formats name/value pairs as {name="value",...}
+func renderLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// escapeLabelValue escapes a label value per the exposition format
+func escapeLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+// bigToFloat converts a base-unit amount for export. Very large amounts lose
+// precision, which is fine for dashboards but not for threshold comparisons.
+func bigToFloat(x *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return f
+}
+
+// serveMetrics exposes the monitored state on /metrics
+func serveMetrics(listen string, notifier *Notifier) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		stateMetrics.writeTo(&b)
+
+		b.WriteString("# HELP alertagent_notifications_dropped_total Notifications discarded because the notification queue was full.\n")
+		b.WriteString("# TYPE alertagent_notifications_dropped_total counter\n")
+		fmt.Fprintf(&b, "alertagent_notifications_dropped_total %d\n", notifier.Dropped())
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(b.String()))
+	})
+
+	slog.Info("serving Prometheus metrics", "address", listen, "path", "/metrics")
+	if err := http.ListenAndServe(listen, mux); err != nil {
+		slog.Error("error serving Prometheus metrics", "address", listen, "error", err)
+	}
+}
+
+// recordBalance exports the latest balance of an address and its threshold
+func recordBalance(balanceType, group, name, address, denom string, current, threshold *big.Int) {
+	labels := []string{"type", balanceType, "group", group, "name", name, "address", address, "denom", denom}
+	stateMetrics.Set("alertagent_balance", bigToFloat(current), labels...)
+	stateMetrics.Set("alertagent_balance_threshold", bigToFloat(threshold), labels...)
+}
+
+// recordMetricValue exports the latest value of a monitored metric
+func recordMetricValue(group, name, metric string, value float64) {
+	stateMetrics.Set("alertagent_metric_value", value, "group", group, "name", name, "metric", metric)
+}
+
+// recordAlerting exports whether an item is currently breaching its alert condition
+func recordAlerting(itemType, group, name string, alerting bool) {
+	stateMetrics.SetBool("alertagent_item_alerting", alerting, "type", itemType, "group", group, "name", name)
+}
+
+// recordCheck counts a finished check and how long it took since start
+func recordCheck(itemType string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	stateMetrics.Add("alertagent_checks_total", 1, "type", itemType, "result", result)
+	stateMetrics.Observe("alertagent_check_duration_seconds", time.Since(start).Seconds(), "type", itemType)
+}
+
+// timedCheck runs a check and records it under itemType
+func timedCheck(itemType string, check func() error) error {
+	start := time.Now()
+	err := check()
+	recordCheck(itemType, start, err)
+	return err
+}
+
+// recordAlertSent counts an alert delivered to a channel
+func recordAlertSent(itemType, channel string) {
+	stateMetrics.Add("alertagent_alerts_sent_total", 1, "type", itemType, "channel", channel)
+}
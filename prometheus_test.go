@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestMetricRegistry checks the exposition output of gauges, counters and
+// summaries, with escaped label values and unused families left out
+func TestMetricRegistry(t *testing.T) {
+	registry := newMetricRegistry(map[string]metricDesc{
+		"test_alerting": {metricKindGauge, "Whether an item is alerting."},
+		"test_total":    {metricKindCounter, "Checks run."},
+		"test_seconds":  {metricKindSummary, "Check duration."},
+		"test_unused":   {metricKindGauge, "Never set."},
+	})
+
+	registry.SetBool("test_alerting", true, "group", "b", "name", `node "1"`)
+	registry.SetBool("test_alerting", false, "group", "a", "name", "node")
+	registry.Add("test_total", 1, "result", "ok")
+	registry.Add("test_total", 2, "result", "ok")
+	registry.Observe("test_seconds", 0.5)
+	registry.Observe("test_seconds", 1.5)
+
+	var b strings.Builder
+	registry.writeTo(&b)
+
+	expected := `# HELP test_alerting Whether an item is alerting.
+# TYPE test_alerting gauge
+test_alerting{group="a",name="node"} 0
+test_alerting{group="b",name="node \"1\""} 1
+# HELP test_seconds Check duration.
+# TYPE test_seconds summary
+test_seconds_sum 2
+test_seconds_count 2
+# HELP test_total Checks run.
+# TYPE test_total counter
+test_total{result="ok"} 3
+`
+	if b.String() != expected {
+		t.Errorf("unexpected exposition output:\n%s", b.String())
+	}
+}
+
+// TestAlertsSentMetric checks that alerts are counted per channel they
+// reached, and that recoveries and failed deliveries aren't
+func TestAlertsSentMetric(t *testing.T) {
+	var sent []string
+	n := &Notifier{channels: []notificationChannel{
+		recordingChannel{channelName: "chat", sent: &sent},
+		recordingChannel{channelName: "broken", err: errors.New("unreachable"), sent: &sent},
+	}}
+
+	alert := &incident{action: pagerDutyTrigger, dedupKey: "metrics_test/group/node", severity: severityCritical}
+	n.deliver("alert", WebhookOverride{}, alert, scopeAll)
+	n.deliver("alert", WebhookOverride{}, alert, scopeAll)
+	n.deliver("recovery", WebhookOverride{}, &incident{action: pagerDutyResolve, dedupKey: alert.dedupKey, severity: severityCritical}, scopeAll)
+
+	stateMetrics.mu.Lock()
+	defer stateMetrics.mu.Unlock()
+	series := stateMetrics.values["alertagent_alerts_sent_total"]
+	if got := series[`{type="metrics_test",channel="chat"}`]; got != 2 {
+		t.Errorf("expected 2 alerts sent to chat, got %g", got)
+	}
+	if got, ok := series[`{type="metrics_test",channel="broken"}`]; ok {
+		t.Errorf("expected no alerts counted for the failing channel, got %g", got)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// promQueryResponse is the response of the Prometheus HTTP API's instant
+// query endpoint, /api/v1/query
+type promQueryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// queryPrometheus runs a PromQL instant query against a Prometheus server and
+// returns its value: the value of a scalar result, or of the first series of
+// a vector. A query matching no series returns errMetricNotFound, so it
+// alerts like a missing metric with alert_on_missing.
+func queryPrometheus(ctx context.Context, baseURL, query string, opts RequestOptions) (float64, error) {
+	queryURL, err := joinEndpoint(baseURL, "/api/v1/query?query="+url.QueryEscape(query))
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := httpGet(ctx, queryURL, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prometheus answers bad queries with a 400 and the reason in the body
+	var promResp promQueryResponse
+	if err := json.Unmarshal(resp.Body, &promResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("prometheus returned %w", unexpectedStatus(resp))
+		}
+		return 0, fmt.Errorf("error parsing query response: %w", err)
+	}
+	if promResp.Status != "success" {
+		return 0, fmt.Errorf("query failed: %s: %s", promResp.ErrorType, promResp.Error)
+	}
+
+	var sample []interface{}
+	switch promResp.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(promResp.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("error parsing query result: %w", err)
+		}
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(promResp.Data.Result, &vector); err != nil {
+			return 0, fmt.Errorf("error parsing query result: %w", err)
+		}
+		if len(vector) == 0 {
+			return 0, fmt.Errorf("%w: query %s returned no series", errMetricNotFound, query)
+		}
+		sample = vector[0].Value
+	default:
+		return 0, fmt.Errorf("query returned a %s, expected a scalar or an instant vector", promResp.Data.ResultType)
+	}
+
+	// A sample is [timestamp, "value"], the value a string so NaN and ±Inf fit
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("invalid sample %v in query result", sample)
+	}
+	raw, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid sample value %v in query result", sample[1])
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sample value %q in query result", raw)
+	}
+	return value, nil
+}
+
+// fetchValue reads the item's current value, with its PromQL query if it has
+// one and from the group's metrics endpoint otherwise
+func (m *MetricItem) fetchValue(ctx context.Context, group *MetricConfig, opts RequestOptions) (float64, error) {
+	if m.Query != "" {
+		return queryPrometheus(ctx, group.RESTEndpoint, m.Query, opts)
+	}
+	return getMetricValue(ctx, group.RESTEndpoint, m.RequestMethod, m.Metric, m.Labels, opts)
+}
+
+// validateQuery checks that the item reads either a metric or a PromQL query.
+// A query is always sent as a GET, so it can't have a method or body.
+func (m *MetricItem) validateQuery() error {
+	if m.Query == "" {
+		if m.Metric == "" {
+			return fmt.Errorf("metric or query is required")
+		}
+		return nil
+	}
+	if m.Metric != "" || len(m.Labels) > 0 {
+		return fmt.Errorf("query can't be combined with metric or labels")
+	}
+	if m.Method != "GET" || m.Body != "" {
+		return fmt.Errorf("query can't be combined with method or body")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestQueryPrometheus(t *testing.T) {
+	noRetries(t)
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected float64
+		err      string
+	}{
+		{
+			name:     "vector",
+			status:   http.StatusOK,
+			body:     `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"sequencer"},"value":[1700000000.123,"0.25"]}]}}`,
+			expected: 0.25,
+		},
+		{
+			name:     "scalar",
+			status:   http.StatusOK,
+			body:     `{"status":"success","data":{"resultType":"scalar","result":[1700000000.123,"42"]}}`,
+			expected: 42,
+		},
+		{
+			name:   "no series",
+			status: http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			err:    "metric not found",
+		},
+		{
+			name:   "bad query",
+			status: http.StatusBadRequest,
+			body:   `{"status":"error","errorType":"bad_data","error":"parse error: unexpected end of input"}`,
+			err:    "bad_data: parse error",
+		},
+		{
+			name:   "range vector",
+			status: http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			err:    "expected a scalar or an instant vector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			client := fakeClient(func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != "/prometheus/api/v1/query" {
+					t.Errorf("unexpected request to %s", req.URL.Path)
+				}
+				query = req.URL.Query().Get("query")
+				return respond(tt.status, tt.body)(req)
+			})
+
+			value, err := queryPrometheus(context.Background(), "http://prometheus.test/prometheus", `sum(rate(rollapp_da_failures_total{job="sequencer"}[5m]))`, RequestOptions{httpClient: client})
+			if query != `sum(rate(rollapp_da_failures_total{job="sequencer"}[5m]))` {
+				t.Errorf("expected the query to be sent, got %q", query)
+			}
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				if tt.name == "no series" && !errors.Is(err, errMetricNotFound) {
+					t.Errorf("expected errMetricNotFound, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("queryPrometheus: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pushoverMessagesURL is the Pushover message API endpoint
+const pushoverMessagesURL = "https://api.pushover.net/1/messages.json"
+
+// Pushover limits and the emergency priority settings. Emergency messages are
+// repeated every pushoverRetry seconds until acknowledged, for at most
+// pushoverExpire seconds.
+const (
+	pushoverTitleMaxLength   = 250
+	pushoverMessageMaxLength = 1024
+	pushoverEmergency        = 2
+	pushoverRetry            = 60
+	pushoverExpire           = 3600
+)
+
+type PushoverConfig struct {
+	Token       string `mapstructure:"token"`        // Application API token
+	User        string `mapstructure:"user"`         // User or group key to notify
+	Priority    int    `mapstructure:"priority"`     // Optional priority from -2 to 2 for everything but critical alerts (default: 0)
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest alert severity to send
+}
+
+// validate checks that the user key and priority are usable
+func (p PushoverConfig) validate() error {
+	if p.User == "" {
+		return fmt.Errorf("user is required")
+	}
+	if p.Priority < -2 || p.Priority > pushoverEmergency {
+		return fmt.Errorf("priority must be between -2 and 2")
+	}
+	return nil
+}
+
+// sendPushover sends a plain-text message as a Pushover notification, with
+// its first line as the title. Critical alerts are sent with emergency
+// priority, which repeats the notification until it's acknowledged.
+func (p PushoverConfig) sendPushover(message string, inc *incident) error {
+	title, body, _ := strings.Cut(message, "\n")
+	if body == "" {
+		body = title
+	}
+	if runes := []rune(title); len(runes) > pushoverTitleMaxLength {
+		title = string(runes[:pushoverTitleMaxLength])
+	}
+	if runes := []rune(body); len(runes) > pushoverMessageMaxLength {
+		body = string(runes[:pushoverMessageMaxLength])
+	}
+
+	priority := p.Priority
+	if inc != nil && inc.action == pagerDutyTrigger && inc.severity == severityCritical {
+		priority = pushoverEmergency
+	}
+
+	form := url.Values{
+		"token":    {p.Token},
+		"user":     {p.User},
+		"title":    {title},
+		"message":  {body},
+		"priority": {strconv.Itoa(priority)},
+	}
+	if priority == pushoverEmergency {
+		form.Set("retry", strconv.Itoa(pushoverRetry))
+		form.Set("expire", strconv.Itoa(pushoverExpire))
+	}
+
+	resp, err := notificationClient.PostForm(pushoverMessagesURL, form)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pushover returned status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
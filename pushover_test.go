@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// TestPushoverMessage checks the title and body split and that only critical
+// alerts are sent with emergency priority
+func TestPushoverMessage(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+	pushover := PushoverConfig{Token: "app", User: "user", Priority: -1}
+
+	tests := []struct {
+		name     string
+		message  string
+		inc      *incident
+		title    string
+		body     string
+		priority string
+	}{
+		{name: "critical alert", message: "🚨 Alert: node down\nHealth: unhealthy", inc: &incident{action: pagerDutyTrigger, severity: severityCritical},
+			title: "🚨 Alert: node down", body: "Health: unhealthy", priority: "2"},
+		{name: "warning alert", message: "⚠️ Alert: low balance\nBalance: 5", inc: &incident{action: pagerDutyTrigger, severity: severityWarning},
+			title: "⚠️ Alert: low balance", body: "Balance: 5", priority: "-1"},
+		{name: "critical recovery", message: "✅ Recovery: node up", inc: &incident{action: pagerDutyResolve, severity: severityCritical},
+			title: "✅ Recovery: node up", body: "✅ Recovery: node up", priority: "-1"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := pushover.sendPushover(tt.message, tt.inc); err != nil {
+				t.Fatalf("sendPushover: %v", err)
+			}
+			req := (*requests)[i]
+			form, err := url.ParseQuery(req.body)
+			if err != nil {
+				t.Fatalf("parse form: %v", err)
+			}
+			if req.url != pushoverMessagesURL || form.Get("token") != "app" || form.Get("user") != "user" {
+				t.Errorf("unexpected request to %s with %v", req.url, form)
+			}
+			if form.Get("title") != tt.title || form.Get("message") != tt.body || form.Get("priority") != tt.priority {
+				t.Errorf("expected %q / %q at priority %s, got %q / %q at %s", tt.title, tt.body, tt.priority, form.Get("title"), form.Get("message"), form.Get("priority"))
+			}
+			if emergency := form.Get("retry") != "" && form.Get("expire") != ""; emergency != (tt.priority == "2") {
+				t.Errorf("expected retry and expire only with emergency priority, got %v", form)
+			}
+		})
+	}
+
+	if err := (PushoverConfig{User: "user", Priority: 3}).validate(); err == nil {
+		t.Error("expected a priority above 2 to be rejected")
+	}
+}
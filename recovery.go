@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recoveryTimeout bounds each recovery check, HTTP retries included, set from
+// the recovery_timeout config setting at startup
+var recoveryTimeout = 10 * time.Second
+
+// runningRecoveryMonitors counts the recovery monitor goroutines, to check in
+// tests that an item never has more than one and that none leak
+var runningRecoveryMonitors atomic.Int64
+
+// startRecoveryMonitor marks an item unhealthy and starts its recovery monitor,
+// unless one is already running. The caller holds the item's mutex mu, so the
+// unhealthy flag, the stop channel and the goroutine always change together.
+// The monitor is handed its own stop channel, so it never watches the channel
+// of a monitor started after it. If the monitor exits without the item being
+// marked healthy, e.g. because it can't check the item at all, the item is
+// reset so the next failed check starts a new one.
+func startRecoveryMonitor(mu *sync.Mutex, isUnhealthy *bool, stop *chan bool, monitor func(stop <-chan bool)) {
+	if *isUnhealthy {
+		return
+	}
+
+	ch := make(chan bool)
+	*isUnhealthy = true
+	*stop = ch
+	runningRecoveryMonitors.Add(1)
+
+	go func() {
+		defer runningRecoveryMonitors.Add(-1)
+		monitor(ch)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if *stop == ch {
+			*isUnhealthy = false
+			*stop = nil
+		}
+	}()
+}
+
+// stopRecoveryMonitor marks an item healthy and stops its recovery monitor, if
+// one is running. The caller holds the item's mutex. Monitors call it when
+// they see the item recover, and check loops when they see it first.
+func stopRecoveryMonitor(isUnhealthy *bool, stop *chan bool) {
+	*isUnhealthy = false
+	if *stop != nil {
+		close(*stop)
+		*stop = nil
+	}
+}
+
+// recoveryTicks returns the ticks of a recovery monitor checking every
+// interval, closed once stop is closed. A tick that comes due while the
+// monitor is still running the previous check is skipped, so checks of a slow
+// endpoint never run back to back to catch up. done stops the ticks of a
+// monitor that returns without stop being closed.
+func recoveryTicks(interval time.Duration, stop <-chan bool) (ticks <-chan time.Time, done func()) {
+	ch := make(chan time.Time)
+	quit := make(chan struct{})
+
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case tick := <-ticker.C:
+				select {
+				case ch <- tick:
+				default:
+					// The previous check is still running
+				}
+			case <-stop:
+				return
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return ch, func() { close(quit) }
+}
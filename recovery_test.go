@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForRecoveryMonitors waits until exactly want recovery monitors are running
+func waitForRecoveryMonitors(t *testing.T, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runningRecoveryMonitors.Load() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d running recovery monitors, got %d", want, runningRecoveryMonitors.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestRecoveryMonitorFlapping flaps a health endpoint that its recovery
+// monitor sees recover, and checks that the item never has more than one
+// monitor and that none is left running once it's healthy
+func TestRecoveryMonitorFlapping(t *testing.T) {
+	noRetries(t)
+	interval := recoveryInterval
+	recoveryInterval = 10 * time.Millisecond
+	t.Cleanup(func() { recoveryInterval = interval })
+
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"result":{"isHealthy":%t}}`, healthy.Load())
+	}))
+	defer server.Close()
+
+	healthConfig := &HealthConfig{Name: "group", Endpoints: []HealthItem{{Name: "node", Endpoint: server.URL,
+		HealthPath: defaultHealthPath, HealthyValue: defaultHealthyValue, ErrorPath: defaultHealthErrorPath, itemState: newItemState()}}}
+	healthItem := &healthConfig.Endpoints[0]
+	notifier := &Notifier{}
+
+	waitForRecoveryMonitors(t, 0)
+	for flap := 0; flap < 3; flap++ {
+		healthy.Store(false)
+		for i := 0; i < 3; i++ {
+			if _, err := checkGroupItem(healthConfig, 0, notifier, 0); err != nil {
+				t.Fatalf("checkGroupItem: %v", err)
+			}
+			if running := runningRecoveryMonitors.Load(); running != 1 {
+				t.Fatalf("flap %d: expected 1 running recovery monitor while down, got %d", flap, running)
+			}
+		}
+
+		healthy.Store(true)
+		waitForRecoveryMonitors(t, 0)
+		healthItem.recoveryMonitorMu.Lock()
+		if healthItem.isUnhealthy || healthItem.recoveryMonitorStop != nil {
+			t.Errorf("flap %d: expected the item to be healthy without a stop channel after recovering", flap)
+		}
+		healthItem.recoveryMonitorMu.Unlock()
+	}
+}
+
+// TestRecoveryMonitorStoppedByCheck flaps a block height that the check loop
+// sees advance before its recovery monitor does, and checks that the check
+// loop stops the monitor instead of leaving it running next to a new one
+func TestRecoveryMonitorStoppedByCheck(t *testing.T) {
+	noRetries(t)
+
+	var height atomic.Int64
+	height.Store(100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"block":{"header":{"height":"%d"}}}`, height.Load())
+	}))
+	defer server.Close()
+
+	// The recovery monitor never gets to check, only the check loop sees the height advance
+	heightConfig := &BlockHeightConfig{Name: "group", MaxStallChecks: 1, Endpoints: []BlockHeightItem{{Name: "node", Endpoint: server.URL,
+		HeightPath: defaultHeightPath, RecoveryInterval: 3600, itemState: newItemState()}}}
+	notifier := &Notifier{}
+
+	check := func() {
+		t.Helper()
+		if _, err := checkGroupItem(heightConfig, 0, notifier, 0); err != nil {
+			t.Fatalf("checkGroupItem: %v", err)
+		}
+	}
+
+	waitForRecoveryMonitors(t, 0)
+	check()
+	for flap := 0; flap < 3; flap++ {
+		// Stalled
+		check()
+		check()
+		if running := runningRecoveryMonitors.Load(); running != 1 {
+			t.Fatalf("flap %d: expected 1 running recovery monitor while stalled, got %d", flap, running)
+		}
+
+		// Advanced
+		height.Add(1)
+		check()
+		waitForRecoveryMonitors(t, 0)
+	}
+}
+
+// TestRecoveryTicksSkipBusy checks that ticks coming due while a recovery
+// check is still running are skipped instead of firing back to back
+func TestRecoveryTicksSkipBusy(t *testing.T) {
+	const interval = 40 * time.Millisecond
+	stop := make(chan bool)
+	ticks, done := recoveryTicks(interval, stop)
+	defer done()
+
+	<-ticks
+	// A slow check spanning several ticks
+	time.Sleep(100 * time.Millisecond)
+
+	// A queued tick would have fired during the check, a fresh one after it
+	start := time.Now()
+	if tick := <-ticks; tick.Before(start.Add(-interval / 4)) {
+		t.Errorf("expected the ticks missed during the check to be skipped, got one from %s before it ended", start.Sub(tick))
+	}
+
+	close(stop)
+	for range ticks {
+	}
+}
+
+// TestRecoveryTimeout checks that a recovery check is bounded by
+// recovery_timeout rather than the HTTP timeout
+func TestRecoveryTimeout(t *testing.T) {
+	noRetries(t)
+	interval := recoveryInterval
+	recoveryInterval = 10 * time.Millisecond
+	timeout := recoveryTimeout
+	recoveryTimeout = 20 * time.Millisecond
+	t.Cleanup(func() {
+		recoveryInterval = interval
+		recoveryTimeout = timeout
+	})
+
+	hung := fakeClient(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	healthConfig := &HealthConfig{Name: "group", Endpoints: []HealthItem{{Name: "node", Endpoint: "http://node.test/health",
+		HealthPath: defaultHealthPath, HealthyValue: defaultHealthyValue, itemState: newItemState()}},
+		RequestOptions: RequestOptions{httpClient: hung}}
+
+	item := healthConfig.item(0)
+	checked := make(chan time.Duration, 1)
+	item.Checker = checkFunc(func(ctx context.Context) (bool, string, error) {
+		start := time.Now()
+		healthy, detail, err := healthConfig.item(0).Check(ctx)
+		if err != nil {
+			select {
+			case checked <- time.Since(start):
+			default:
+			}
+		}
+		return healthy, detail, err
+	})
+
+	stop := make(chan bool)
+	monitor := itemAlert{itemType: "health", group: "group", item: item, notifier: &Notifier{}}
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		monitor.monitorRecovery(stop)
+	}()
+	defer func() {
+		close(stop)
+		<-exited
+	}()
+
+	select {
+	case elapsed := <-checked:
+		if elapsed > time.Second {
+			t.Errorf("expected the check to give up after the recovery timeout, took %s", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the hung recovery check to time out")
+	}
+}
+
+// TestRecoveryPollInterval checks that an item's recovery_interval overrides
+// the global one
+func TestRecoveryPollInterval(t *testing.T) {
+	interval := recoveryInterval
+	recoveryInterval = 5 * time.Second
+	t.Cleanup(func() { recoveryInterval = interval })
+
+	if got := recoveryPollInterval(0); got != 5*time.Second {
+		t.Errorf("expected the global 5s without an override, got %s", got)
+	}
+	if got := recoveryPollInterval(30); got != 30*time.Second {
+		t.Errorf("expected the item's 30s, got %s", got)
+	}
+}
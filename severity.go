@@ -0,0 +1,94 @@
+package main
+
+import "fmt"
+
+// Alert severities from least to most severe, also sent to PagerDuty as-is
+const (
+	severityInfo     = "info"     // Nothing to act on yet
+	severityWarning  = "warning"  // Metric, balance, grant and certificate issues
+	severityCritical = "critical" // Health, validator, header, block height and TCP failures
+)
+
+// severityRanks orders the severities for min_severity filtering
+var severityRanks = map[string]int{
+	severityInfo:     1,
+	severityWarning:  2,
+	severityCritical: 3,
+}
+
+// severityEmojis prefixes alert messages so the severity shows at a glance
+var severityEmojis = map[string]string{
+	severityInfo:     emojiInfo,
+	severityWarning:  emojiWarning,
+	severityCritical: emojiCritical,
+}
+
+// Emojis that messages start with
+const (
+	emojiInfo     = "ℹ️"
+	emojiWarning  = "⚠️"
+	emojiCritical = "🚨"
+	emojiRecovery = "✅"
+	emojiTest     = "🧪"
+	emojiStartup  = "🚀"
+	emojiHealthy  = "💚"
+	emojiDegraded = "💛"
+)
+
+// useEmoji starts messages with emoji, set from the use_emoji config setting
+// at startup. Without it they start with the plain tags of emojiTags, for
+// terminals and log pipelines that mangle emoji.
+var useEmoji = true
+
+// emojiTags are the plain text stand-ins of the message emojis
+var emojiTags = map[string]string{
+	emojiInfo:     "[INFO]",
+	emojiWarning:  "[WARN]",
+	emojiCritical: "[ALERT]",
+	emojiRecovery: "[RECOVERY]",
+	emojiTest:     "[TEST]",
+	emojiStartup:  "[STARTED]",
+	emojiHealthy:  "[OK]",
+	emojiDegraded: "[DEGRADED]",
+}
+
+// messageEmoji returns the emoji a message starts with, or its plain tag
+// when use_emoji is off
+func messageEmoji(emoji string) string {
+	return emojiOrTag(emoji, useEmoji)
+}
+
+// emojiOrTag returns the emoji, or its plain tag without emoji, for messages
+// built before use_emoji is applied
+func emojiOrTag(emoji string, withEmoji bool) string {
+	if withEmoji {
+		return emoji
+	}
+	return emojiTags[emoji]
+}
+
+// validateSeverity checks that a configured severity is known. Empty means unset.
+func validateSeverity(severity string) error {
+	if severity == "" {
+		return nil
+	}
+	if _, ok := severityRanks[severity]; !ok {
+		return fmt.Errorf("invalid severity %q: must be info, warning, or critical", severity)
+	}
+	return nil
+}
+
+// severityEmoji returns the emoji alerts of the given severity start with
+func severityEmoji(severity string) string {
+	return messageEmoji(severityEmojis[severity])
+}
+
+// meetsSeverity reports whether a notification of the given severity passes a
+// channel's min_severity. Messages without a severity, such as the startup
+// message, go to every channel.
+func meetsSeverity(severity, minSeverity string) bool {
+	if severity == "" || minSeverity == "" {
+		return true
+	}
+	return severityRanks[severity] >= severityRanks[minSeverity]
+}
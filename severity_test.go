@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+// TestMeetsSeverity checks min_severity filtering, with unset severities
+// passing every channel
+func TestMeetsSeverity(t *testing.T) {
+	tests := []struct {
+		severity    string
+		minSeverity string
+		expected    bool
+	}{
+		{severity: severityWarning, minSeverity: "", expected: true},
+		{severity: "", minSeverity: severityCritical, expected: true},
+		{severity: severityInfo, minSeverity: severityWarning},
+		{severity: severityWarning, minSeverity: severityWarning, expected: true},
+		{severity: severityCritical, minSeverity: severityWarning, expected: true},
+		{severity: severityWarning, minSeverity: severityCritical},
+	}
+
+	for _, tt := range tests {
+		if got := meetsSeverity(tt.severity, tt.minSeverity); got != tt.expected {
+			t.Errorf("severity %q with min_severity %q: expected %v, got %v", tt.severity, tt.minSeverity, tt.expected, got)
+		}
+	}
+}
+
+// TestSeverityEmoji checks the emoji each severity starts its alerts with
+func TestSeverityEmoji(t *testing.T) {
+	for severity, emoji := range map[string]string{severityInfo: "ℹ️", severityWarning: "⚠️", severityCritical: "🚨"} {
+		if got := severityEmoji(severity); got != emoji {
+			t.Errorf("severity %q: expected %s, got %s", severity, emoji, got)
+		}
+	}
+
+	if err := validateSeverity("urgent"); err == nil {
+		t.Error("expected an unknown severity to be rejected")
+	}
+	if err := validateSeverity(""); err != nil {
+		t.Errorf("expected an unset severity to be accepted, got %v", err)
+	}
+}
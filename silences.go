@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// dailyTimeLayout is the format of daily silence bounds, e.g. "02:30"
+const dailyTimeLayout = "15:04"
+
+// Silence suppresses notifications for matching items during a time window,
+// either once between start and end or every day between daily_start and daily_end
+type Silence struct {
+	Group      string `mapstructure:"group"`       // Group name or glob pattern, empty matches every group
+	Item       string `mapstructure:"item"`        // Item name or glob pattern, empty matches every item
+	Start      string `mapstructure:"start"`       // RFC3339 start of a one-off window
+	End        string `mapstructure:"end"`         // RFC3339 end of a one-off window
+	DailyStart string `mapstructure:"daily_start"` // UTC start of a recurring daily window, e.g. "02:00"
+	DailyEnd   string `mapstructure:"daily_end"`   // UTC end of a recurring daily window, may be before daily_start to span midnight
+	Comment    string `mapstructure:"comment"`     // Optional reason shown in the logs
+
+	start, end           time.Time
+	dailyStart, dailyEnd time.Duration // Offsets since midnight UTC
+}
+
+// silences holds the configured silences, replaced as a whole when the config is reloaded
+var silences struct {
+	mu    sync.RWMutex
+	items []Silence
+}
+
+// validateSilences parses the time windows of the configured silences
+func validateSilences(config *Config) error {
+	for i := range config.Silences {
+		silence := &config.Silences[i]
+		if _, err := path.Match(silence.Group, ""); err != nil {
+			return fmt.Errorf("invalid group pattern '%s' for silence #%d: %w", silence.Group, i+1, err)
+		}
+		if _, err := path.Match(silence.Item, ""); err != nil {
+			return fmt.Errorf("invalid item pattern '%s' for silence #%d: %w", silence.Item, i+1, err)
+		}
+
+		oneOff := silence.Start != "" || silence.End != ""
+		daily := silence.DailyStart != "" || silence.DailyEnd != ""
+		if oneOff == daily {
+			return fmt.Errorf("silence #%d needs either start and end or daily_start and daily_end", i+1)
+		}
+
+		if oneOff {
+			var err error
+			if silence.start, err = time.Parse(time.RFC3339, silence.Start); err != nil {
+				return fmt.Errorf("invalid start for silence #%d: %w", i+1, err)
+			}
+			if silence.end, err = time.Parse(time.RFC3339, silence.End); err != nil {
+				return fmt.Errorf("invalid end for silence #%d: %w", i+1, err)
+			}
+			if !silence.end.After(silence.start) {
+				return fmt.Errorf("end must be after start for silence #%d", i+1)
+			}
+			continue
+		}
+
+		start, err := time.Parse(dailyTimeLayout, silence.DailyStart)
+		if err != nil {
+			return fmt.Errorf("invalid daily_start for silence #%d, expected HH:MM: %w", i+1, err)
+		}
+		end, err := time.Parse(dailyTimeLayout, silence.DailyEnd)
+		if err != nil {
+			return fmt.Errorf("invalid daily_end for silence #%d, expected HH:MM: %w", i+1, err)
+		}
+		silence.dailyStart = time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+		silence.dailyEnd = time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute
+		if silence.dailyStart == silence.dailyEnd {
+			return fmt.Errorf("daily_start and daily_end must differ for silence #%d", i+1)
+		}
+	}
+
+	return nil
+}
+
+// setSilences replaces the active silences
+func setSilences(items []Silence) {
+	silences.mu.Lock()
+	defer silences.mu.Unlock()
+	silences.items = items
+}
+
+// reloadSilences re-reads the silences from the config files so maintenance
+// windows can be added or lifted without restarting the agent
+func reloadSilences() error {
+	if err := readConfig(configSource.path, configSource.dir); err != nil {
+		return err
+	}
+	if err := expandEnvVars(); err != nil {
+		return fmt.Errorf("error expanding environment variables in config: %w", err)
+	}
+
+	var config Config
+	if err := viper.UnmarshalKey("silences", &config.Silences); err != nil {
+		return fmt.Errorf("error unmarshaling silences: %w", err)
+	}
+	if err := validateSilences(&config); err != nil {
+		return err
+	}
+
+	setSilences(config.Silences)
+	slog.Info("reloaded silences", "count", len(config.Silences))
+	return nil
+}
+
+// matches reports whether the silence applies to the item at the given time
+func (s *Silence) matches(group, item string, now time.Time) bool {
+	if !matchesPattern(s.Group, group) || !matchesPattern(s.Item, item) {
+		return false
+	}
+
+	if !s.start.IsZero() {
+		return !now.Before(s.start) && now.Before(s.end)
+	}
+
+	now = now.UTC()
+	offset := now.Sub(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if s.dailyStart < s.dailyEnd {
+		return offset >= s.dailyStart && offset < s.dailyEnd
+	}
+	// The window spans midnight
+	return offset >= s.dailyStart || offset < s.dailyEnd
+}
+
+// matchesPattern matches a name against a glob pattern, an empty pattern matches everything
+func matchesPattern(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// activeSilence returns the first silence currently matching the item, or nil
+func activeSilence(group, item string) *Silence {
+	silences.mu.RLock()
+	defer silences.mu.RUnlock()
+
+	now := time.Now()
+	for i := range silences.items {
+		if silences.items[i].matches(group, item, now) {
+			silence := silences.items[i]
+			return &silence
+		}
+	}
+	return nil
+}
+
+// silenced reports whether notifications for the item are currently silenced,
+// logging the skipped notification if so
+func silenced(itemType, group, item string) bool {
+	silence := activeSilence(group, item)
+	if silence == nil {
+		return false
+	}
+
+	slog.Info("notification silenced", "type", itemType, "group", group, "item", item, "silence", silence.Comment)
+	return true
+}
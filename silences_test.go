@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSilenceMatches checks group and item patterns and one-off and daily
+// windows, including one spanning midnight
+func TestSilenceMatches(t *testing.T) {
+	config := &Config{Silences: []Silence{
+		{Group: "hub-*", Start: "2026-03-01T10:00:00Z", End: "2026-03-01T12:00:00Z"},
+		{Item: "archive", DailyStart: "23:00", DailyEnd: "01:00"},
+	}}
+	if err := validateSilences(config); err != nil {
+		t.Fatalf("validateSilences: %v", err)
+	}
+	oneOff, daily := config.Silences[0], config.Silences[1]
+
+	tests := []struct {
+		name    string
+		silence Silence
+		group   string
+		item    string
+		at      string
+		matches bool
+	}{
+		{name: "one-off within window", silence: oneOff, group: "hub-mainnet", item: "node", at: "2026-03-01T11:00:00Z", matches: true},
+		{name: "one-off at end", silence: oneOff, group: "hub-mainnet", item: "node", at: "2026-03-01T12:00:00Z"},
+		{name: "one-off other group", silence: oneOff, group: "rollapp", item: "node", at: "2026-03-01T11:00:00Z"},
+		{name: "daily before midnight", silence: daily, group: "any", item: "archive", at: "2026-03-01T23:30:00Z", matches: true},
+		{name: "daily after midnight", silence: daily, group: "any", item: "archive", at: "2026-03-02T00:30:00Z", matches: true},
+		{name: "daily outside window", silence: daily, group: "any", item: "archive", at: "2026-03-02T01:00:00Z"},
+		{name: "daily other item", silence: daily, group: "any", item: "node", at: "2026-03-01T23:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, _ := time.Parse(time.RFC3339, tt.at)
+			if got := tt.silence.matches(tt.group, tt.item, at); got != tt.matches {
+				t.Errorf("expected matches %v, got %v", tt.matches, got)
+			}
+		})
+	}
+}
+
+// TestValidateSilences checks that a silence needs exactly one valid window
+func TestValidateSilences(t *testing.T) {
+	invalid := []Silence{
+		{},
+		{Start: "2026-03-01T10:00:00Z", End: "2026-03-01T12:00:00Z", DailyStart: "01:00", DailyEnd: "02:00"},
+		{Start: "2026-03-01T12:00:00Z", End: "2026-03-01T10:00:00Z"},
+		{DailyStart: "25:00", DailyEnd: "02:00"},
+		{DailyStart: "02:00", DailyEnd: "02:00"},
+		{Group: "[", DailyStart: "01:00", DailyEnd: "02:00"},
+	}
+	for i, silence := range invalid {
+		if err := validateSilences(&Config{Silences: []Silence{silence}}); err == nil {
+			t.Errorf("expected silence #%d %+v to be rejected", i+1, silence)
+		}
+	}
+}
+
+// TestSilenced checks that active silences are applied to items
+func TestSilenced(t *testing.T) {
+	t.Cleanup(func() { setSilences(nil) })
+
+	now := time.Now()
+	setSilences([]Silence{{Group: "hub", Item: "node", start: now.Add(-time.Minute), end: now.Add(time.Hour)}})
+	if !silenced("health", "hub", "node") {
+		t.Error("expected the item to be silenced")
+	}
+	if silenced("health", "hub", "other") {
+		t.Error("expected other items not to be silenced")
+	}
+}
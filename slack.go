@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type SlackConfig struct {
+	WebhookURL  string `mapstructure:"webhook_url"`  // Slack incoming webhook URL
+	Channel     string `mapstructure:"channel"`      // Optional channel override, e.g. #alerts
+	Username    string `mapstructure:"username"`     // Optional username override
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest alert severity to send
+}
+
+type slackMessage struct {
+	Text     string       `json:"text"` // Fallback for notifications
+	Blocks   []slackBlock `json:"blocks"`
+	Channel  string       `json:"channel,omitempty"`
+	Username string       `json:"username,omitempty"`
+}
+
+type slackBlock struct {
+	Type string         `json:"type"`
+	Text slackBlockText `json:"text"`
+}
+
+type slackBlockText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackEscaper escapes the characters Slack mrkdwn treats as control characters
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMarkdown converts a Telegram-formatted message to Slack mrkdwn, which
+// shares its code spans but writes links as <url|text>
+func slackMarkdown(message string) string {
+	return markdownLinkPattern.ReplaceAllString(slackEscaper.Replace(message), "<$2|$1>")
+}
+
+// sendSlackMessage posts a Telegram-formatted message to a Slack incoming
+// webhook as a Block Kit mrkdwn section, with a plain-text notification fallback
+func sendSlackMessage(slack SlackConfig, message string) error {
+	payload, err := json.Marshal(slackMessage{
+		Text: plainText(message),
+		Blocks: []slackBlock{{
+			Type: "section",
+			Text: slackBlockText{Type: "mrkdwn", Text: slackMarkdown(message)},
+		}},
+		Channel:  slack.Channel,
+		Username: slack.Username,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	resp, err := notificationClient.Post(slack.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("slack returned status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
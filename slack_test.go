@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestSlackMessage checks the Block Kit payload of a Slack message, with
+// links converted to Slack's syntax and control characters escaped
+func TestSlackMessage(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+	slack := SlackConfig{WebhookURL: "https://hooks.slack.test/services/x", Channel: "#alerts", Username: "alert-agent"}
+
+	message := "🚨 [group] `node` <1 DYM [explorer](https://explorer.test/dym1abc)"
+	if err := sendSlackMessage(slack, message); err != nil {
+		t.Fatalf("sendSlackMessage: %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0].url != slack.WebhookURL {
+		t.Fatalf("expected one request to the webhook, got %+v", *requests)
+	}
+
+	var payload slackMessage
+	if err := json.Unmarshal([]byte((*requests)[0].body), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Channel != "#alerts" || payload.Username != "alert-agent" {
+		t.Errorf("expected the channel and username overrides, got %+v", payload)
+	}
+	if len(payload.Blocks) != 1 || payload.Blocks[0].Text.Type != "mrkdwn" {
+		t.Fatalf("expected one mrkdwn section, got %+v", payload.Blocks)
+	}
+	if expected := "🚨 [group] `node` &lt;1 DYM <https://explorer.test/dym1abc|explorer>"; payload.Blocks[0].Text.Text != expected {
+		t.Errorf("expected mrkdwn %q, got %q", expected, payload.Blocks[0].Text.Text)
+	}
+	if payload.Text != plainText(message) {
+		t.Errorf("expected the plain text fallback, got %q", payload.Text)
+	}
+}
+
+// TestSlackMessageError checks that a rejected Slack message is reported
+func TestSlackMessageError(t *testing.T) {
+	captureNotifications(t, http.StatusNotFound)
+	if err := sendSlackMessage(SlackConfig{WebhookURL: "https://hooks.slack.test/services/x"}, "alert"); err == nil {
+		t.Error("expected an error for a rejected message")
+	}
+}
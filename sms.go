@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// twilioMessagesURL is the Twilio Messages API endpoint, formatted with the account SID
+const twilioMessagesURL = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+
+// smsMaxLength keeps messages within a single SMS segment
+const smsMaxLength = 160
+
+// smsStatuses is the word an SMS uses for an alert of each item type,
+// everything else is DOWN
+var smsStatuses = map[string]string{
+	"balance":       "LOW",
+	"kaspa_balance": "LOW",
+	"evm_balance":   "LOW",
+	"btc_balance":   "LOW",
+	"metric":        "ALERT",
+	"grant":         "EXPIRING",
+	"cert":          "EXPIRING",
+}
+
+type SMSConfig struct {
+	AccountSID string   `mapstructure:"account_sid"` // Twilio account SID
+	AuthToken  string   `mapstructure:"auth_token"`  // Twilio auth token
+	From       string   `mapstructure:"from"`        // Twilio phone number to send from, e.g. +15005550006
+	To         []string `mapstructure:"to"`          // Phone numbers to text
+}
+
+// validate checks that the sender and recipients are set
+func (s SMSConfig) validate() error {
+	if s.AuthToken == "" {
+		return fmt.Errorf("auth_token is required")
+	}
+	if s.From == "" {
+		return fmt.Errorf("from is required")
+	}
+	if len(s.To) == 0 {
+		return fmt.Errorf("at least one to number is required")
+	}
+	return nil
+}
+
+// smsBody builds a short SMS for an alert or recovery, e.g. "[Hub] Main RPC DOWN"
+func smsBody(message string, inc *incident) string {
+	body := strings.SplitN(message, "\n", 2)[0]
+	if inc.details != nil {
+		status := "RECOVERED"
+		if inc.action == pagerDutyTrigger {
+			status = smsStatuses[inc.itemType()]
+			if status == "" {
+				status = "DOWN"
+			}
+		}
+		body = fmt.Sprintf("[%s] %s %s", inc.details.Group, inc.details.Name, status)
+	}
+
+	if runes := []rune(body); len(runes) > smsMaxLength {
+		body = string(runes[:smsMaxLength])
+	}
+	return body
+}
+
+// sendSMS texts every configured number through Twilio. Numbers Twilio
+// rejects are logged and skipped; it only fails when no number was texted.
+func (s SMSConfig) sendSMS(body string) error {
+	apiURL := fmt.Sprintf(twilioMessagesURL, url.PathEscape(s.AccountSID))
+
+	sent := 0
+	for _, to := range s.To {
+		if err := s.sendTwilioMessage(apiURL, to, body); err != nil {
+			slog.Warn("SMS recipient failed", "recipient", to, "error", err)
+			continue
+		}
+		sent++
+	}
+	if sent == 0 {
+		return fmt.Errorf("no SMS could be sent to any recipient")
+	}
+	return nil
+}
+
+// sendTwilioMessage sends a single SMS
+func (s SMSConfig) sendTwilioMessage(apiURL, to, body string) error {
+	form := url.Values{
+		"From": {s.From},
+		"To":   {to},
+		"Body": {body},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.AccountSID, s.AuthToken)
+
+	resp, err := notificationClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Twilio answers 201 Created for queued messages
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("twilio returned status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestSMSBody checks the short status texts of alerts and recoveries
+func TestSMSBody(t *testing.T) {
+	details := &messageData{Group: "Hub", Name: "Main RPC"}
+	tests := []struct {
+		name     string
+		message  string
+		inc      *incident
+		expected string
+	}{
+		{name: "health alert", message: "🚨 Alert\nlong details", inc: &incident{action: pagerDutyTrigger, dedupKey: "health/Hub/rpc", details: details}, expected: "[Hub] Main RPC DOWN"},
+		{name: "balance alert", message: "🚨 Alert", inc: &incident{action: pagerDutyTrigger, dedupKey: "balance/Hub/dym1", details: details}, expected: "[Hub] Main RPC LOW"},
+		{name: "recovery", message: "✅ Recovery", inc: &incident{action: pagerDutyResolve, dedupKey: "health/Hub/rpc", details: details}, expected: "[Hub] Main RPC RECOVERED"},
+		{name: "without details", message: "TEST Alert\nNo action is needed", inc: &incident{action: pagerDutyTrigger}, expected: "TEST Alert"},
+		{name: "too long", message: strings.Repeat("x", 200), inc: &incident{action: pagerDutyTrigger}, expected: strings.Repeat("x", smsMaxLength)},
+	}
+
+	for _, tt := range tests {
+		if got := smsBody(tt.message, tt.inc); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestSendSMS checks that every number is texted through Twilio with basic auth
+func TestSendSMS(t *testing.T) {
+	requests := captureNotifications(t, http.StatusCreated)
+	sms := SMSConfig{AccountSID: "AC123", AuthToken: "token", From: "+15005550006", To: []string{"+15551230001", "+15551230002"}}
+
+	if err := sms.sendSMS("[Hub] Main RPC DOWN"); err != nil {
+		t.Fatalf("sendSMS: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected a text per number, got %d", len(*requests))
+	}
+	for i, req := range *requests {
+		form, _ := url.ParseQuery(req.body)
+		if req.url != "https://api.twilio.com/2010-04-01/Accounts/AC123/Messages.json" || form.Get("To") != sms.To[i] || form.Get("Body") != "[Hub] Main RPC DOWN" {
+			t.Errorf("unexpected request to %s with %v", req.url, form)
+		}
+		if user, pass, ok := (&http.Request{Header: req.header}).BasicAuth(); !ok || user != "AC123" || pass != "token" {
+			t.Errorf("expected basic auth with the account SID, got %q", req.header.Get("Authorization"))
+		}
+	}
+
+	captureNotifications(t, http.StatusBadRequest)
+	if err := sms.sendSMS("alert"); err == nil {
+		t.Error("expected an error when no number could be texted")
+	}
+}
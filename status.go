@@ -0,0 +1,117 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// itemStatus is the result of an item's last check, reported by /status. It
+// is guarded by the item's recoveryMonitorMu.
+type itemStatus struct {
+	lastCheckTime time.Time // When the item's last check completed, failed fetches don't count
+	lastValue     string    // Value seen by that check, e.g. a balance or block height
+	lastErrorTime time.Time // When the item's last check failed, kept after it succeeds again
+	lastError     string    // Error of that check, e.g. an unreachable endpoint
+}
+
+// set stores a check result, the caller holds the item's mutex
+func (s *itemStatus) set(value string) {
+	s.lastCheckTime = time.Now()
+	s.lastValue = value
+}
+
+// record stores a check result under the item's mutex
+func (s *itemStatus) record(mu *sync.Mutex, value string) {
+	mu.Lock()
+	defer mu.Unlock()
+	s.set(value)
+}
+
+// recordError stores the error of a failed check under the item's mutex
+func (s *itemStatus) recordError(mu *sync.Mutex, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	s.lastErrorTime = time.Now()
+	s.lastError = err.Error()
+}
+
+// healthValue describes the result of a pass or fail check
+func healthValue(healthy bool) string {
+	if healthy {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+// statusItem is one monitored item in the /status response
+type statusItem struct {
+	Type          string     `json:"type"`
+	Group         string     `json:"group"`
+	Name          string     `json:"name"`
+	LastCheckTime *time.Time `json:"last_check_time,omitempty"`
+	LastValue     string     `json:"last_value,omitempty"`
+	Threshold     string     `json:"threshold,omitempty"`
+	Unhealthy     bool       `json:"unhealthy"`
+	LastAlertTime *time.Time `json:"last_alert_time,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+}
+
+// snapshotItem reads an item's live state under its mutex
+func snapshotItem(itemType, group string, m monitoredItem) statusItem {
+	state := m.state
+	state.recoveryMonitorMu.Lock()
+	defer state.recoveryMonitorMu.Unlock()
+
+	item := statusItem{
+		Type:      itemType,
+		Group:     group,
+		Name:      m.name,
+		LastValue: state.status.lastValue,
+		Threshold: m.threshold,
+		Unhealthy: state.isUnhealthy,
+		LastError: state.status.lastError,
+	}
+	if !state.status.lastCheckTime.IsZero() {
+		checked := state.status.lastCheckTime
+		item.LastCheckTime = &checked
+	}
+	if !state.lastAlertTime.IsZero() {
+		alerted := state.lastAlertTime
+		item.LastAlertTime = &alerted
+	}
+	if !state.status.lastErrorTime.IsZero() {
+		failed := state.status.lastErrorTime
+		item.LastErrorTime = &failed
+	}
+	return item
+}
+
+// statusItems returns the live state of every configured item
+func statusItems(config *Config) []statusItem {
+	var items []statusItem
+	for _, g := range groupCheckers(config) {
+		for i := range g.itemCount() {
+			items = append(items, snapshotItem(g.checkType(), g.groupName(), g.item(i)))
+		}
+	}
+	return items
+}
+
+// statusSummary counts the healthy and unhealthy items of each item type
+func statusSummary(items []statusItem) map[string]*itemCounts {
+	counts := make(map[string]*itemCounts)
+	for _, item := range items {
+		c := counts[item.Type]
+		if c == nil {
+			c = &itemCounts{}
+			counts[item.Type] = c
+		}
+		if item.Unhealthy {
+			c.unhealthy++
+		} else {
+			c.healthy++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+)
+
+// TestStatus checks that /status reports each item's last check result and
+// whether it's unhealthy, and that the summary counts them per item type
+func TestStatus(t *testing.T) {
+	noRetries(t)
+	group := &HealthConfig{
+		Name: "Hub",
+		Endpoints: []HealthItem{
+			{Name: "ok", Endpoint: "http://ok.test", HealthPath: "result.isHealthy", HealthyValue: "true", itemState: newItemState()},
+			{Name: "down", Endpoint: "http://down.test", HealthPath: "result.isHealthy", HealthyValue: "true", itemState: newItemState()},
+		},
+		RequestOptions: RequestOptions{httpClient: fakeClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "down.test" {
+				return nil, syscall.ECONNREFUSED
+			}
+			return respond(http.StatusOK, `{"result":{"isHealthy":true}}`)(req)
+		})},
+	}
+	t.Cleanup(func() {
+		for i := range group.Endpoints {
+			item := &group.Endpoints[i]
+			item.recoveryMonitorMu.Lock()
+			stopRecoveryMonitor(&item.isUnhealthy, &item.recoveryMonitorStop)
+			item.recoveryMonitorMu.Unlock()
+		}
+		waitForRecoveryMonitors(t, 0)
+	})
+	for i := range group.Endpoints {
+		checkGroupItem(group, i, &Notifier{}, 3600)
+	}
+
+	config := &Config{Health: []HealthConfig{*group}}
+	p := newProbeServer("127.0.0.1:0", nil, func() []statusItem { return statusItems(config) }, nil)
+	rec := httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	var resp statusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode /status: %v", err)
+	}
+	if rec.Code != http.StatusOK || len(resp.Items) != 2 {
+		t.Fatalf("expected both items, got %d with %+v", rec.Code, resp.Items)
+	}
+
+	ok, down := resp.Items[0], resp.Items[1]
+	if ok.Type != "health" || ok.Group != "Hub" || ok.Name != "ok" || ok.LastValue != "healthy" || ok.LastCheckTime == nil || ok.Unhealthy {
+		t.Errorf("expected the healthy item's last check, got %+v", ok)
+	}
+	if down.Name != "down" || !down.Unhealthy || down.LastAlertTime == nil {
+		t.Errorf("expected the unreachable item to be unhealthy and alerted, got %+v", down)
+	}
+
+	counts := statusSummary([]statusItem{{Type: "health"}, {Type: "health", Unhealthy: true}, {Type: "balance"}})
+	if len(counts) != 2 || *counts["health"] != (itemCounts{healthy: 1, unhealthy: 1}) || *counts["balance"] != (itemCounts{healthy: 1}) {
+		t.Errorf("expected the counts per item type, got %v", counts)
+	}
+}
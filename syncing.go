@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultSyncGracePeriod is how long a node may catch up before alerting by default, in seconds
+const defaultSyncGracePeriod = 600
+
+// syncingPaths are where the cosmos /cosmos/base/tendermint/v1beta1/syncing
+// and Tendermint /status responses report whether the node is catching up
+var syncingPaths = []string{"syncing", "result.sync_info.catching_up"}
+
+type SyncItem struct {
+	Name             string  `mapstructure:"name"`
+	Endpoint         string  `mapstructure:"endpoint"`          // cosmos syncing or Tendermint /status URL
+	GracePeriod      int     `mapstructure:"grace_period"`      // Optional per-endpoint override of the group grace_period
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-endpoint cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-endpoint recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	syncingSince time.Time // When the node was first seen catching up, zero while synced
+	itemState              // Internal tracking, not from config
+}
+
+type SyncConfig struct {
+	Name          string     `mapstructure:"name"`
+	CheckInterval Seconds    `mapstructure:"check_interval"` // Optional per-group check interval
+	GracePeriod   int        `mapstructure:"grace_period"`   // Seconds a node may catch up before alerting (default: 600)
+	Endpoints     []SyncItem `mapstructure:"endpoints"`
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+// validateSyncChecks validates the sync check groups and prepares their items
+func validateSyncChecks(config *Config) error {
+	for i, syncGroup := range config.SyncChecks {
+		if syncGroup.Name == "" {
+			config.SyncChecks[i].Name = fmt.Sprintf("Sync Check Group %d", i+1) // Set default name if not provided
+			syncGroup.Name = config.SyncChecks[i].Name
+		}
+		if err := syncGroup.RequestOptions.validate(); err != nil {
+			return fmt.Errorf("sync check group '%s': %w", config.SyncChecks[i].Name, err)
+		}
+		if syncGroup.GracePeriod < 0 {
+			return fmt.Errorf("grace_period must not be negative for sync check group '%s'", syncGroup.Name)
+		}
+		if syncGroup.GracePeriod == 0 {
+			config.SyncChecks[i].GracePeriod = defaultSyncGracePeriod
+		}
+
+		for j, endpoint := range syncGroup.Endpoints {
+			item := &config.SyncChecks[i].Endpoints[j]
+			if endpoint.Endpoint == "" {
+				return fmt.Errorf("endpoint is required for sync check item #%d in group '%s'", j+1, syncGroup.Name)
+			}
+			if err := validateEndpoint(endpoint.Endpoint); err != nil {
+				return fmt.Errorf("sync check item #%d in group '%s': %w", j+1, syncGroup.Name, err)
+			}
+			if endpoint.GracePeriod < 0 {
+				return fmt.Errorf("grace_period must not be negative for sync check endpoint '%s' in group '%s'", endpoint.Endpoint, syncGroup.Name)
+			}
+			if endpoint.Name == "" {
+				item.Name = fmt.Sprintf("Node %d", j+1) // Set default name if not provided
+			}
+			if err := endpoint.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("sync check endpoint '%s' in group '%s': %w", endpoint.Endpoint, syncGroup.Name, err)
+			}
+			if endpoint.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for sync check endpoint '%s' in group '%s'", endpoint.Endpoint, syncGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// gracePeriod returns how long the item may catch up, falling back to the group setting
+func (s *SyncItem) gracePeriod(syncConfig *SyncConfig) time.Duration {
+	if s.GracePeriod > 0 {
+		return time.Duration(s.GracePeriod) * time.Second
+	}
+	return time.Duration(syncConfig.GracePeriod) * time.Second
+}
+
+// getSyncing fetches whether the node behind the endpoint is catching up
+func getSyncing(ctx context.Context, endpoint string, opts RequestOptions) (bool, error) {
+	resp, err := httpGet(ctx, endpoint, opts)
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("API returned %w", unexpectedStatus(resp))
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(resp.Body, &data); err != nil {
+		return false, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	for _, path := range syncingPaths {
+		value, err := lookupJSONPath(data, path)
+		if err != nil {
+			continue
+		}
+		syncing, ok := value.(bool)
+		if !ok {
+			return false, fmt.Errorf("invalid %s value %v", path, value)
+		}
+		return syncing, nil
+	}
+	return false, fmt.Errorf("response has neither syncing nor result.sync_info.catching_up")
+}
+
+// syncCheck checks whether a node is catching up against its group's grace period
+type syncCheck struct {
+	group *SyncConfig
+	item  *SyncItem
+}
+
+// Check fetches whether the node is catching up and reports it unhealthy once
+// it has been catching up for longer than its grace period
+func (c syncCheck) Check(ctx context.Context) (bool, string, error) {
+	syncing, err := getSyncing(ctx, c.item.Endpoint, c.group.RequestOptions)
+	if err != nil {
+		return false, "", fmt.Errorf("error getting sync status of %s: %w", c.item.Name, err)
+	}
+
+	item := c.item
+	item.recoveryMonitorMu.Lock()
+	defer item.recoveryMonitorMu.Unlock()
+
+	if !syncing {
+		item.syncingSince = time.Time{}
+		return true, "synced", nil
+	}
+	if item.syncingSince.IsZero() {
+		item.syncingSince = time.Now()
+	}
+	catchingUpFor := time.Since(item.syncingSince).Round(time.Second)
+	return catchingUpFor < item.gracePeriod(c.group), "catching up for " + catchingUpFor.String(), nil
+}
+
+// checkGroup implementation for sync check groups, run by runMonitor
+
+func (c *SyncConfig) checkType() string      { return "sync" }
+func (c *SyncConfig) groupName() string      { return c.Name }
+func (c *SyncConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *SyncConfig) itemCount() int         { return len(c.Endpoints) }
+
+func (c *SyncConfig) item(i int) monitoredItem {
+	item := &c.Endpoints[i]
+	return monitoredItem{
+		Checker: syncCheck{group: c, item: item}, state: &item.itemState, name: item.Name, target: item.Endpoint,
+		threshold: "grace period " + item.gracePeriod(c).String(), severity: severityCritical,
+		cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSyncCheck checks that a node catching up stays healthy during its grace
+// period, turns unhealthy after it and resets once synced
+func TestSyncCheck(t *testing.T) {
+	syncing := true
+	client := fakeClient(func(req *http.Request) (*http.Response, error) {
+		if syncing {
+			return respond(http.StatusOK, `{"result":{"sync_info":{"catching_up":true}}}`)(req)
+		}
+		return respond(http.StatusOK, `{"syncing":false}`)(req)
+	})
+	group := &SyncConfig{GracePeriod: 600, RequestOptions: RequestOptions{httpClient: client}}
+	item := &SyncItem{Name: "node", Endpoint: "http://node.test:26657/status", itemState: newItemState()}
+	check := syncCheck{group: group, item: item}
+
+	if healthy, _, err := check.Check(context.Background()); !healthy || err != nil {
+		t.Fatalf("expected a node that just started catching up to be healthy, got %v (%v)", healthy, err)
+	}
+
+	item.syncingSince = time.Now().Add(-11 * time.Minute)
+	if healthy, status, err := check.Check(context.Background()); healthy || err != nil || status != "catching up for 11m0s" {
+		t.Errorf("expected a node catching up past its grace period to be unhealthy, got %v with %q (%v)", healthy, status, err)
+	}
+
+	syncing = false
+	if healthy, status, err := check.Check(context.Background()); !healthy || err != nil || status != "synced" {
+		t.Errorf("expected a synced node to be healthy, got %v with %q (%v)", healthy, status, err)
+	}
+	if !item.syncingSince.IsZero() {
+		t.Error("expected the catching up time to reset once synced")
+	}
+}
+
+// TestGetSyncing checks the response shapes and that an unknown one is an error
+func TestGetSyncing(t *testing.T) {
+	tests := []struct {
+		body     string
+		syncing  bool
+		hasError bool
+	}{
+		{body: `{"syncing":true}`, syncing: true},
+		{body: `{"result":{"sync_info":{"catching_up":false}}}`},
+		{body: `{"syncing":"yes"}`, hasError: true},
+		{body: `{"height":"100"}`, hasError: true},
+	}
+
+	for _, tt := range tests {
+		syncing, err := getSyncing(context.Background(), "http://node.test", RequestOptions{httpClient: respond(http.StatusOK, tt.body)})
+		if syncing != tt.syncing || (err != nil) != tt.hasError {
+			t.Errorf("%s: expected syncing %v with error %v, got %v (%v)", tt.body, tt.syncing, tt.hasError, syncing, err)
+		}
+	}
+}
+
+// TestValidateSyncChecks checks that grace_period defaults to 600 seconds
+// and an endpoint's own grace_period takes precedence
+func TestValidateSyncChecks(t *testing.T) {
+	config := &Config{SyncChecks: []SyncConfig{{Endpoints: []SyncItem{{Endpoint: "http://a.test"}, {Endpoint: "http://b.test", GracePeriod: 60}}}}}
+	if err := validateSyncChecks(config); err != nil {
+		t.Fatalf("validateSyncChecks: %v", err)
+	}
+	group := &config.SyncChecks[0]
+	if got := group.Endpoints[0].gracePeriod(group); got != 10*time.Minute {
+		t.Errorf("expected the default grace period of 10m, got %s", got)
+	}
+	if got := group.Endpoints[1].gracePeriod(group); got != time.Minute {
+		t.Errorf("expected the endpoint's grace period of 1m, got %s", got)
+	}
+
+	config = &Config{SyncChecks: []SyncConfig{{Endpoints: []SyncItem{{}}}}}
+	if err := validateSyncChecks(config); err == nil {
+		t.Error("expected an endpoint to be required")
+	}
+}
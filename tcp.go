@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// tcpDialTimeout bounds every TCP reachability check
+const tcpDialTimeout = 10 * time.Second
+
+type TCPCheckItem struct {
+	Name             string  `mapstructure:"name"`
+	Address          string  `mapstructure:"address"`           // host:port to connect to
+	AlertCooldown    Seconds `mapstructure:"alert_cooldown"`    // Optional per-check cooldown
+	RecoveryInterval Seconds `mapstructure:"recovery_interval"` // Optional per-check recovery check interval in seconds
+
+	WebhookOverride `mapstructure:",squash"`
+
+	itemState // Internal tracking, not from config
+}
+
+type TCPCheckConfig struct {
+	Name          string         `mapstructure:"name"`
+	CheckInterval Seconds        `mapstructure:"check_interval"` // Optional per-group check interval
+	Checks        []TCPCheckItem `mapstructure:"checks"`
+}
+
+// validateTCPChecks validates the TCP check groups and prepares their items
+func validateTCPChecks(config *Config) error {
+	for i, tcpGroup := range config.TCPChecks {
+		if tcpGroup.Name == "" {
+			config.TCPChecks[i].Name = fmt.Sprintf("TCP Check Group %d", i+1) // Set default name if not provided
+			tcpGroup.Name = config.TCPChecks[i].Name
+		}
+
+		for j, check := range tcpGroup.Checks {
+			item := &config.TCPChecks[i].Checks[j]
+			if _, _, err := net.SplitHostPort(check.Address); err != nil {
+				return fmt.Errorf("invalid address '%s' for TCP check #%d in group '%s': %s", check.Address, j+1, tcpGroup.Name, hostPortFormat)
+			}
+			if check.Name == "" {
+				item.Name = check.Address // Default to the address if no name is provided
+			}
+			if err := check.WebhookOverride.validate(); err != nil {
+				return fmt.Errorf("TCP check '%s' in group '%s': %w", check.Address, tcpGroup.Name, err)
+			}
+			if check.RecoveryInterval < 0 {
+				return fmt.Errorf("recovery_interval must not be negative for TCP check '%s' in group '%s'", check.Address, tcpGroup.Name)
+			}
+			item.itemState = newItemState()
+		}
+	}
+
+	return nil
+}
+
+// checkTCP reports whether addr accepts TCP connections within timeout
+func checkTCP(ctx context.Context, addr string, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return classifyRequestError(err)
+	}
+	return conn.Close()
+}
+
+// Check connects to the check's address
+func (t *TCPCheckItem) Check(ctx context.Context) (bool, string, error) {
+	err := checkTCP(ctx, t.Address, tcpDialTimeout)
+	return err == nil, healthValue(err == nil), err
+}
+
+// checkGroup implementation for TCP check groups, run by runMonitor
+
+func (c *TCPCheckConfig) checkType() string      { return "tcp" }
+func (c *TCPCheckConfig) groupName() string      { return c.Name }
+func (c *TCPCheckConfig) groupInterval() Seconds { return c.CheckInterval }
+func (c *TCPCheckConfig) itemCount() int         { return len(c.Checks) }
+
+func (c *TCPCheckConfig) item(i int) monitoredItem {
+	item := &c.Checks[i]
+	return monitoredItem{
+		Checker: item, state: &item.itemState, name: item.Name, target: item.Address,
+		severity: severityCritical, cooldown: item.AlertCooldown, recovery: item.RecoveryInterval, override: item.WebhookOverride,
+	}
+}
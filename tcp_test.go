@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// TestTCPCheck checks that an open port is healthy and a closed one isn't
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+
+	open := &TCPCheckItem{Name: "p2p", Address: addr}
+	if healthy, _, err := open.Check(context.Background()); !healthy || err != nil {
+		t.Errorf("expected the open port to be healthy, got %v (%v)", healthy, err)
+	}
+
+	listener.Close()
+	if healthy, _, err := open.Check(context.Background()); healthy || err == nil {
+		t.Errorf("expected the closed port to be unhealthy with an error, got %v (%v)", healthy, err)
+	}
+}
+
+// TestValidateTCPChecks checks that addresses need a port, IPv6 hosts in brackets
+func TestValidateTCPChecks(t *testing.T) {
+	for address, valid := range map[string]bool{"node.test:26656": true, "[::1]:26656": true, "node.test": false, "::1:26656": false} {
+		config := &Config{TCPChecks: []TCPCheckConfig{{Checks: []TCPCheckItem{{Address: address}}}}}
+		if err := validateTCPChecks(config); (err == nil) != valid {
+			t.Errorf("address %q: expected valid %v, got %v", address, valid, err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Teams card colors, green for recoveries and by severity for alerts
+const teamsRecoveryColor = "2EB886"
+
+var teamsSeverityColors = map[string]string{
+	severityCritical: "D70000",
+	severityWarning:  "FF8C00",
+	severityInfo:     "0078D7",
+}
+
+type TeamsConfig struct {
+	WebhookURL  string `mapstructure:"webhook_url"`  // Microsoft Teams incoming webhook URL
+	MinSeverity string `mapstructure:"min_severity"` // Optional lowest alert severity to send
+}
+
+type teamsMessageCard struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	ThemeColor string         `json:"themeColor,omitempty"`
+	Summary    string         `json:"summary"`
+	Title      string         `json:"title"`
+	Sections   []teamsSection `json:"sections,omitempty"`
+}
+
+type teamsSection struct {
+	Text  string      `json:"text,omitempty"`
+	Facts []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// newTeamsCard builds a MessageCard for a plain-text message. Teams renders
+// the markdown of message text poorly, so alerts and recoveries list the
+// item's fields as facts instead of repeating the message body.
+func newTeamsCard(message string, inc *incident) teamsMessageCard {
+	title, body, _ := strings.Cut(message, "\n")
+	card := teamsMessageCard{
+		Type:    "MessageCard",
+		Context: "http://schema.org/extensions",
+		Summary: title,
+		Title:   title,
+	}
+
+	if inc != nil {
+		if inc.action == pagerDutyResolve {
+			card.ThemeColor = teamsRecoveryColor
+		} else {
+			card.ThemeColor = teamsSeverityColors[inc.severity]
+		}
+	}
+
+	if inc == nil || inc.details == nil {
+		if body != "" {
+			card.Sections = []teamsSection{{Text: body}}
+		}
+		return card
+	}
+
+	details := inc.details
+	var facts []teamsFact
+	for _, fact := range []teamsFact{
+		{"Type", inc.itemType()},
+		{"Group", details.Group},
+		{"Item", details.Name},
+		{"Address", details.Address},
+		{"Current", details.Current},
+		{"Threshold", details.Threshold},
+		{"Error", details.Error},
+	} {
+		if fact.Value != "" {
+			facts = append(facts, fact)
+		}
+	}
+	card.Sections = []teamsSection{{Facts: facts}}
+	return card
+}
+
+// sendTeamsMessage posts a plain-text message to a Microsoft Teams incoming webhook as a MessageCard
+func sendTeamsMessage(webhookURL, message string, inc *incident) error {
+	payload, err := json.Marshal(newTeamsCard(message, inc))
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %w", err)
+	}
+
+	resp, err := notificationClient.Post(webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("teams returned status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
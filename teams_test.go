@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestTeamsCard checks the card colors and that alerts list the item's
+// fields as facts while plain messages keep their body
+func TestTeamsCard(t *testing.T) {
+	details := &messageData{Group: "hub", Name: "relayer", Address: "dym1abc", Current: "5 DYM", Threshold: "below 10 DYM"}
+	alert := &incident{action: pagerDutyTrigger, dedupKey: "balance/hub/dym1abc", severity: severityWarning, details: details}
+
+	card := newTeamsCard("⚠️ Alert: [hub] relayer is low\nBalance: 5 DYM", alert)
+	if card.Title != "⚠️ Alert: [hub] relayer is low" || card.ThemeColor != "FF8C00" {
+		t.Errorf("unexpected alert card title %q and color %s", card.Title, card.ThemeColor)
+	}
+	expected := []teamsFact{{"Type", "balance"}, {"Group", "hub"}, {"Item", "relayer"}, {"Address", "dym1abc"}, {"Current", "5 DYM"}, {"Threshold", "below 10 DYM"}}
+	if len(card.Sections) != 1 || !reflect.DeepEqual(card.Sections[0].Facts, expected) {
+		t.Errorf("expected the item's fields as facts, got %+v", card.Sections)
+	}
+
+	recovery := newTeamsCard("✅ Recovery", &incident{action: pagerDutyResolve, severity: severityWarning})
+	if recovery.ThemeColor != teamsRecoveryColor {
+		t.Errorf("expected the recovery color, got %s", recovery.ThemeColor)
+	}
+
+	plain := newTeamsCard("Daily summary\nAll 12 items healthy", nil)
+	if plain.ThemeColor != "" || len(plain.Sections) != 1 || plain.Sections[0].Text != "All 12 items healthy" {
+		t.Errorf("expected a plain message to keep its body, got %+v", plain)
+	}
+}
+
+// TestTeamsMessage checks that the card is posted to the webhook
+func TestTeamsMessage(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+	if err := sendTeamsMessage("https://teams.test/webhook", "Monitor started", nil); err != nil {
+		t.Fatalf("sendTeamsMessage: %v", err)
+	}
+
+	var card teamsMessageCard
+	if len(*requests) != 1 || json.Unmarshal([]byte((*requests)[0].body), &card) != nil || card.Type != "MessageCard" || card.Title != "Monitor started" {
+		t.Errorf("expected a MessageCard posted to the webhook, got %+v", *requests)
+	}
+}
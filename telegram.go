@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	telegramSendInterval = time.Second // Telegram allows about one message per second to the same chat
+	telegramMaxRetries   = 3           // Retries of a rate-limited send before giving up
+
+	defaultTelegramSendTimeout = 30 // Seconds a send may take, rate-limit waits included
+)
+
+// Telegram parse modes, markdownv2 and the legacy markdown format the code
+// spans and links of messages
+const (
+	telegramParseMarkdownV2 = "markdownv2"
+	telegramParseMarkdown   = "markdown"
+	telegramParsePlain      = "plain"
+)
+
+// validateTelegramParseMode checks the telegram parse_mode setting, empty means markdownv2
+func validateTelegramParseMode(parseMode string) error {
+	switch parseMode {
+	case "", telegramParseMarkdownV2, telegramParseMarkdown, telegramParsePlain:
+		return nil
+	}
+	return fmt.Errorf("invalid telegram parse_mode %q: must be %s, %s or %s", parseMode, telegramParseMarkdownV2, telegramParseMarkdown, telegramParsePlain)
+}
+
+// markdownV2Escaper escapes the characters MarkdownV2 reserves outside of code and links
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// Inside code spans and link URLs MarkdownV2 only reserves \ and the closing character
+var (
+	markdownV2CodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownV2URLEscaper  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+// escapeMarkdownV2 escapes text so MarkdownV2 shows it literally
+func escapeMarkdownV2(text string) string {
+	return markdownV2Escaper.Replace(text)
+}
+
+// telegramMarkdownV2 converts a Telegram-formatted message, text with `code`
+// spans and [text](url) links, to MarkdownV2. Only the code spans and links
+// are kept as formatting, every other character is escaped, so names and
+// values can't break parsing whatever they contain.
+func telegramMarkdownV2(message string) string {
+	var b strings.Builder
+	parts := strings.Split(message, "`")
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			b.WriteString("`" + markdownV2CodeEscaper.Replace(part) + "`")
+		case i%2 == 1:
+			// An unmatched backtick is literal
+			b.WriteString("\\`" + markdownV2Text(part))
+		default:
+			b.WriteString(markdownV2Text(part))
+		}
+	}
+	return b.String()
+}
+
+// markdownV2Text escapes text outside code spans, keeping its links
+func markdownV2Text(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range markdownLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(escapeMarkdownV2(text[last:m[0]]))
+		linkText, linkURL := text[m[2]:m[3]], text[m[4]:m[5]]
+		b.WriteString("[" + escapeMarkdownV2(linkText) + "](" + markdownV2URLEscaper.Replace(linkURL) + ")")
+		last = m[1]
+	}
+	b.WriteString(escapeMarkdownV2(text[last:]))
+	return b.String()
+}
+
+// telegramSender sends messages to one chat, spacing them out to stay under
+// Telegram's rate limits and retrying when a send is rate limited anyway
+type telegramSender struct {
+	bot         *tgbotapi.BotAPI
+	chatID      int64
+	parseMode   string        // markdownv2, markdown or plain
+	sendTimeout time.Duration // Bounds a send, so a slow Telegram can't hold up the alerts queued behind it
+
+	mu   sync.Mutex // Serializes sends so the spacing holds
+	last time.Time  // When the last message was sent
+}
+
+func newTelegramSender(bot *tgbotapi.BotAPI, chatID int64, parseMode string, sendTimeout time.Duration) *telegramSender {
+	return &telegramSender{bot: bot, chatID: chatID, parseMode: parseMode, sendTimeout: sendTimeout}
+}
+
+// send delivers a message in the sender's parse mode. A message Telegram
+// can't parse, e.g. a legacy Markdown one whose name contains an underscore,
+// is sent again as plain text rather than lost. The whole send, fallback
+// included, gives up after the send timeout.
+func (t *telegramSender) send(message string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.sendTimeout)
+	defer cancel()
+
+	var err error
+	switch t.parseMode {
+	case telegramParsePlain:
+		return t.sendMessage(ctx, plainText(message), "")
+	case telegramParseMarkdown:
+		err = t.sendMessage(ctx, message, tgbotapi.ModeMarkdown)
+	default:
+		err = t.sendMessage(ctx, telegramMarkdownV2(message), tgbotapi.ModeMarkdownV2)
+	}
+	if !isTelegramParseError(err) {
+		return err
+	}
+	slog.Warn("Telegram could not parse the message as Markdown, sending it as plain text", "error", err)
+	return t.sendMessage(ctx, plainText(message), "")
+}
+
+// checkChat checks that the bot can reach its chat without sending a message
+func (t *telegramSender) checkChat() error {
+	_, err := t.bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: t.chatID}})
+	return err
+}
+
+// isTelegramParseError reports whether Telegram rejected a message because
+// its formatting couldn't be parsed
+func isTelegramParseError(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && tgErr.Code == 400 && strings.Contains(tgErr.Message, "can't parse entities")
+}
+
+// sendMessage sends a message with the given parse mode, waiting for the send
+// interval and honoring retry_after on 429 responses, until ctx is done. The
+// caller holds mu.
+func (t *telegramSender) sendMessage(ctx context.Context, text, parseMode string) error {
+	msg := tgbotapi.NewMessage(t.chatID, text)
+	msg.ParseMode = parseMode
+
+	for attempt := 0; ; attempt++ {
+		if wait := telegramSendInterval - time.Since(t.last); wait > 0 {
+			if err := t.wait(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		err := t.sendRequest(ctx, msg)
+		t.last = time.Now()
+
+		var tgErr *tgbotapi.Error
+		if err == nil || !errors.As(err, &tgErr) || tgErr.RetryAfter <= 0 || attempt == telegramMaxRetries {
+			return err
+		}
+
+		retryAfter := time.Duration(tgErr.RetryAfter) * time.Second
+		slog.Warn("Telegram rate limit hit, retrying", "retry_after", retryAfter, "attempt", attempt+1, "max_retries", telegramMaxRetries)
+		if err := t.wait(ctx, retryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+// sendRequest sends a message, returning once ctx is done. The bot API takes
+// no context, so a request that outlives ctx finishes in the background,
+// bounded by the HTTP client's timeout.
+func (t *telegramSender) sendRequest(ctx context.Context, msg tgbotapi.MessageConfig) error {
+	result := make(chan error, 1)
+	go func() {
+		_, err := t.bot.Send(msg)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return t.timedOut()
+	}
+}
+
+// wait sleeps for d, or returns the timeout error once ctx is done first
+func (t *telegramSender) wait(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return t.timedOut()
+	}
+}
+
+// timedOut logs and returns the error of a send that ran past the send timeout
+func (t *telegramSender) timedOut() error {
+	slog.Warn("Telegram send timed out, moving on", "send_timeout", t.sendTimeout)
+	return fmt.Errorf("telegram send timed out after %s", t.sendTimeout)
+}
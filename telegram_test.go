@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeTelegram is a Telegram Bot API server that rejects legacy Markdown
+// messages with unbalanced underscores like the real one, and records what
+// was sent
+type fakeTelegram struct {
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+// sentMessage is a sent message's text and parse mode
+type sentMessage struct {
+	text      string
+	parseMode string
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/getMe"):
+		fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"bot"}}`)
+	case strings.HasSuffix(r.URL.Path, "/getChat"):
+		fmt.Fprint(w, `{"ok":true,"result":{"id":1,"type":"private"}}`)
+	case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+		text, parseMode := r.FormValue("text"), r.FormValue("parse_mode")
+		f.mu.Lock()
+		f.sent = append(f.sent, sentMessage{text: text, parseMode: parseMode})
+		f.mu.Unlock()
+
+		// Underscores in code spans are literal, check the text before the first one
+		beforeCode, _, _ := strings.Cut(text, "`")
+		if parseMode == tgbotapi.ModeMarkdown && strings.Count(beforeCode, "_")%2 == 1 {
+			fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities: Can't find end of the entity starting at byte offset 10"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeTelegramSender(t *testing.T, parseMode string) (*telegramSender, *fakeTelegram) {
+	t.Helper()
+	fake := &fakeTelegram{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", server.URL+"/bot%s/%s", server.Client())
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+	return newTelegramSender(bot, 1, parseMode, time.Minute), fake
+}
+
+// TestTelegramMarkdownFallback checks that an alert whose group name breaks
+// Markdown parsing is sent again as plain text instead of being lost
+func TestTelegramMarkdownFallback(t *testing.T) {
+	sender, fake := newFakeTelegramSender(t, telegramParseMarkdown)
+
+	message := "🚨 Alert: [hot_wallets] `relayer_1*[x]` balance is below threshold!"
+	if err := sender.send(message); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	expected := []sentMessage{
+		{text: message, parseMode: tgbotapi.ModeMarkdown},
+		{text: plainText(message), parseMode: ""},
+	}
+	if len(fake.sent) != len(expected) {
+		t.Fatalf("expected %d sends, got %+v", len(expected), fake.sent)
+	}
+	for i := range expected {
+		if fake.sent[i] != expected[i] {
+			t.Errorf("send %d: expected %+v, got %+v", i, expected[i], fake.sent[i])
+		}
+	}
+}
+
+// TestTelegramPlainParseMode checks that the plain parse mode sends messages
+// as plain text right away
+func TestTelegramPlainParseMode(t *testing.T) {
+	sender, fake := newFakeTelegramSender(t, telegramParsePlain)
+
+	if err := sender.send("Alert: [hot_wallets] `relayer_1`"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(fake.sent) != 1 || fake.sent[0].parseMode != "" || fake.sent[0].text != "Alert: [hot_wallets] relayer_1" {
+		t.Fatalf("expected one plain text send, got %+v", fake.sent)
+	}
+}
+
+// TestTelegramMarkdownV2 checks that messages are sent as MarkdownV2 by default
+func TestTelegramMarkdownV2(t *testing.T) {
+	sender, fake := newFakeTelegramSender(t, "")
+
+	if err := sender.send("Alert: [hot_wallets] `relayer_1`"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	expected := sentMessage{text: "Alert: \\[hot\\_wallets\\] `relayer_1`", parseMode: tgbotapi.ModeMarkdownV2}
+	if len(fake.sent) != 1 || fake.sent[0] != expected {
+		t.Fatalf("expected %+v, got %+v", expected, fake.sent)
+	}
+}
+
+func TestTelegramMarkdownV2Escaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{name: "plain text", message: "Monitor started", expected: "Monitor started"},
+		{name: "every reserved character", message: "_*[]()~>#+-=|{}.!\\", expected: "\\_\\*\\[\\]\\(\\)\\~\\>\\#\\+\\-\\=\\|\\{\\}\\.\\!\\\\"},
+		{name: "code span kept", message: "Address: `dym1_a*b.c`", expected: "Address: `dym1_a*b.c`"},
+		{name: "backslash in code", message: "`a\\b`", expected: "`a\\\\b`"},
+		{name: "unmatched backtick", message: "a `b_c", expected: "a \\`b\\_c"},
+		{name: "link kept", message: "[View on explorer](https://example.com/a_b?c=d.e)!", expected: "[View on explorer](https://example.com/a_b?c=d.e)\\!"},
+		{name: "link text escaped", message: "[a.b](https://example.com)", expected: "[a\\.b](https://example.com)"},
+		{name: "brackets without link", message: "[group] 1.5 ATOM (150 uatom)!", expected: "\\[group\\] 1\\.5 ATOM \\(150 uatom\\)\\!"},
+		{name: "alert", message: "🚨 Alert: [hot-wallets] `relayer_1` balance is below threshold!\nCurrent balance: 1,234.5 DYM",
+			expected: "🚨 Alert: \\[hot\\-wallets\\] `relayer_1` balance is below threshold\\!\nCurrent balance: 1,234\\.5 DYM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := telegramMarkdownV2(tt.message); got != tt.expected {
+				t.Errorf("telegramMarkdownV2(%q):\nexpected %q\ngot      %q", tt.message, tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSilentStartupTest checks that with the startup message disabled the
+// Telegram chat is still checked, without posting to it
+func TestSilentStartupTest(t *testing.T) {
+	sender, fake := newFakeTelegramSender(t, telegramParseMarkdownV2)
+	channel := telegramChannel{sender: sender}
+
+	if err := testChannel(channel, ""); err != nil {
+		t.Fatalf("testChannel: %v", err)
+	}
+	if len(fake.sent) != 0 {
+		t.Fatalf("expected no message, got %+v", fake.sent)
+	}
+
+	if err := testChannel(channel, "Agent restarted"); err != nil {
+		t.Fatalf("testChannel: %v", err)
+	}
+	if len(fake.sent) != 1 || fake.sent[0].text != "Agent restarted" {
+		t.Fatalf("expected the startup message, got %+v", fake.sent)
+	}
+}
+
+// TestTelegramSendTimeout checks that a send to a Telegram that doesn't answer
+// gives up after the send timeout instead of holding up the next alerts
+func TestTelegramSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"bot"}}`)
+			return
+		}
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", server.URL+"/bot%s/%s", server.Client())
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+	sender := newTelegramSender(bot, 1, telegramParsePlain, 50*time.Millisecond)
+
+	start := time.Now()
+	err = sender.send("Alert: node down")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the send to give up after the timeout, took %s", elapsed)
+	}
+}
+
+// TestTelegramRateLimitRetry checks that a rate-limited send is retried after
+// the retry_after Telegram asks for
+func TestTelegramRateLimitRetry(t *testing.T) {
+	var mu sync.Mutex
+	var attempts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"bot"}}`)
+			return
+		}
+		mu.Lock()
+		attempts = append(attempts, time.Now())
+		first := len(attempts) == 1
+		mu.Unlock()
+		if first {
+			fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", server.URL+"/bot%s/%s", server.Client())
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+	sender := newTelegramSender(bot, 1, telegramParsePlain, time.Minute)
+
+	if err := sender.send("Alert: node down"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 2 {
+		t.Fatalf("expected the rate-limited send to be retried once, got %d attempts", len(attempts))
+	}
+	if gap := attempts[1].Sub(attempts[0]); gap < time.Second {
+		t.Errorf("expected the retry to wait for retry_after, waited %s", gap)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/template"
+)
+
+// Notification events a message template can be set for
+const (
+	eventAlert    = "alert"
+	eventRecovery = "recovery"
+)
+
+// templateItemTypes lists the item types whose messages can be overridden
+var templateItemTypes = []string{
+	"balance", "kaspa_balance", "evm_balance", "btc_balance", "metric", "health", "kaspa_validator",
+	"header", "grant", "block_height", "tcp", "grpc_health", "cert", "dns",
+	"cosmos_validator", "peers", "sync",
+}
+
+// MessageTemplate overrides the alert and recovery messages of an item type
+// with Go text/template strings
+type MessageTemplate struct {
+	Alert    string `mapstructure:"alert"`
+	Recovery string `mapstructure:"recovery"`
+}
+
+// messageData is what message templates can reference
+type messageData struct {
+	Type      string // Item type, e.g. balance
+	Group     string // Group name
+	Name      string // Item name
+	Address   string // Address, endpoint, or host:port the item checks
+	Current   string // Current value, e.g. the balance, metric value, or header value
+	Threshold string // Configured threshold or expectation
+	Error     string // Error or problem description, if any
+	Message   string // The built-in message
+
+	ErrorClass string // Class of a failed request, e.g. timeout, connection refused, DNS error, or HTTP 503
+}
+
+// messageTemplates holds the parsed templates keyed by "<type>/<event>"
+var messageTemplates = map[string]*template.Template{}
+
+// validateTemplates parses the configured message templates
+func validateTemplates(config *Config) error {
+	parsed := make(map[string]*template.Template)
+	for itemType, tmpl := range config.Templates {
+		if !isTemplateItemType(itemType) {
+			return fmt.Errorf("unknown item type '%s' in templates: must be one of %s", itemType, strings.Join(templateItemTypes, ", "))
+		}
+
+		for event, text := range map[string]string{eventAlert: tmpl.Alert, eventRecovery: tmpl.Recovery} {
+			if text == "" {
+				continue
+			}
+			key := itemType + "/" + event
+			t, err := template.New(key).Parse(text)
+			if err != nil {
+				return fmt.Errorf("invalid %s template for %s: %w", event, itemType, err)
+			}
+			// Catch references to fields that don't exist before the first alert
+			if err := t.Execute(io.Discard, messageData{}); err != nil {
+				return fmt.Errorf("invalid %s template for %s: %w", event, itemType, err)
+			}
+			parsed[key] = t
+		}
+	}
+
+	messageTemplates = parsed
+	return nil
+}
+
+// isTemplateItemType reports whether itemType is a known item type
+func isTemplateItemType(itemType string) bool {
+	for _, t := range templateItemTypes {
+		if t == itemType {
+			return true
+		}
+	}
+	return false
+}
+
+// renderMessage renders the configured template for the item type and event,
+// falling back to the built-in message in data.Message
+func renderMessage(itemType, event string, data messageData) string {
+	t, ok := messageTemplates[itemType+"/"+event]
+	if !ok {
+		return data.Message
+	}
+
+	data.Type = itemType
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		slog.Error("error rendering message template, using the built-in message", "type", itemType, "event", event, "error", err)
+		return data.Message
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"text/template"
+)
+
+// TestRenderMessage checks that configured templates replace the built-in
+// message of their item type and event only
+func TestRenderMessage(t *testing.T) {
+	t.Cleanup(func() { messageTemplates = map[string]*template.Template{} })
+
+	config := &Config{Templates: map[string]MessageTemplate{
+		"balance": {Alert: "{{.Type}} {{.Group}}/{{.Name}} at {{.Current}} ({{.Threshold}})"},
+	}}
+	if err := validateTemplates(config); err != nil {
+		t.Fatalf("validateTemplates: %v", err)
+	}
+
+	data := messageData{Group: "hub", Name: "relayer", Current: "5 DYM", Threshold: "below 10 DYM", Message: "built-in"}
+	if got := renderMessage("balance", eventAlert, data); got != "balance hub/relayer at 5 DYM (below 10 DYM)" {
+		t.Errorf("unexpected rendered alert %q", got)
+	}
+	if got := renderMessage("balance", eventRecovery, data); got != "built-in" {
+		t.Errorf("expected the built-in recovery without a recovery template, got %q", got)
+	}
+	if got := renderMessage("health", eventAlert, data); got != "built-in" {
+		t.Errorf("expected the built-in message for other item types, got %q", got)
+	}
+}
+
+// TestValidateTemplates checks that unknown item types, syntax errors and
+// unknown fields are rejected at startup
+func TestValidateTemplates(t *testing.T) {
+	t.Cleanup(func() { messageTemplates = map[string]*template.Template{} })
+
+	invalid := map[string]MessageTemplate{
+		"balances": {Alert: "{{.Name}}"},
+		"balance":  {Alert: "{{.Name"},
+		"health":   {Recovery: "{{.Endpoint}}"},
+	}
+	for itemType, tmpl := range invalid {
+		if err := validateTemplates(&Config{Templates: map[string]MessageTemplate{itemType: tmpl}}); err == nil {
+			t.Errorf("expected the %s template %+v to be rejected", itemType, tmpl)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"log/slog"
+	"sort"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	testAlertMessage    = "TEST Alert: this is a test alert from the observability agent\nNo action is needed, it only verifies that notifications arrive"
+	testRecoveryMessage = "TEST Recovery: the test alert from the observability agent has recovered\nNo action is needed"
+)
+
+// sendTestAlert delivers a sample alert and recovery to every configured
+// channel, including the item webhook overrides, and reports whether every
+// send succeeded
+func sendTestAlert(config *Config) bool {
+	ok := true
+
+	var telegram *telegramSender
+	if config.Telegram.BotToken != "" {
+		bot, err := tgbotapi.NewBotAPI(config.Telegram.BotToken)
+		if err != nil {
+			slog.Error("failed to initialize Telegram bot", "error", err)
+			ok = false
+		} else {
+			telegram = newTelegramSender(bot, config.Telegram.ChatID, config.Telegram.ParseMode, time.Duration(config.Telegram.SendTimeout)*time.Second)
+		}
+	}
+
+	notifier := newNotifier(configuredChannels(telegram, config), config)
+	routes := append([]WebhookOverride{{}}, itemWebhooks(config)...)
+
+	// Deliver directly instead of through the queue so failures are known before
+	// exiting, as critical so no channel's min_severity filters it out
+	inc := incident{dedupKey: dedupKey("test", notifier.source), severity: severityCritical}
+	sent := 0
+	for _, route := range routes {
+		if route.WebhookURL == "" && !notifier.hasGlobalChannel() && !notifier.hasIncidentChannel() {
+			continue
+		}
+
+		for _, step := range []struct {
+			action  string
+			message string
+		}{
+			{pagerDutyTrigger, messageEmoji(emojiTest) + " " + testAlertMessage},
+			{pagerDutyResolve, messageEmoji(emojiRecovery) + " " + testRecoveryMessage},
+		} {
+			inc.action = step.action
+			attempted, errs := notifier.deliver(step.message, route, &inc, scopeAll)
+			sent += attempted
+			if len(errs) > 0 {
+				ok = false
+			}
+			slog.Info("sent test notification", "event_action", step.action, "webhook_url", route.WebhookURL,
+				"channels", attempted, "failed", len(errs))
+		}
+	}
+
+	if sent == 0 {
+		slog.Error("no notification channel is configured")
+		return false
+	}
+	return ok
+}
+
+// itemWebhooks returns the distinct webhook overrides set on any item as
+// webhook-only routes, along with their signing secrets
+func itemWebhooks(config *Config) []WebhookOverride {
+	seen := make(map[string]string)
+	add := func(route WebhookOverride) {
+		if route.WebhookURL != "" && seen[route.WebhookURL] == "" {
+			seen[route.WebhookURL] = route.WebhookSecret
+		}
+	}
+
+	for _, group := range config.Addresses {
+		for _, item := range group.Addresses {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.KaspaAddresses {
+		for _, item := range group.Addresses {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.EVMAddresses {
+		for _, item := range group.Addresses {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.BTCAddresses {
+		for _, item := range group.Addresses {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.Metrics {
+		for _, item := range group.Metrics {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.Health {
+		for _, item := range group.Endpoints {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.KaspaValidators {
+		for _, item := range group.Validators {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.HeaderChecks {
+		for _, item := range group.Checks {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.GrantChecks {
+		for _, item := range group.Grants {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.BlockHeight {
+		for _, item := range group.Endpoints {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.TCPChecks {
+		for _, item := range group.Checks {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.GRPCHealth {
+		for _, item := range group.Checks {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.CertChecks {
+		for _, item := range group.Checks {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.DNSChecks {
+		for _, item := range group.Checks {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.CosmosValidators {
+		for _, item := range group.Validators {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.PeerChecks {
+		for _, item := range group.Endpoints {
+			add(item.WebhookOverride)
+		}
+	}
+	for _, group := range config.SyncChecks {
+		for _, item := range group.Endpoints {
+			add(item.WebhookOverride)
+		}
+	}
+
+	webhooks := make([]WebhookOverride, 0, len(seen))
+	for webhookURL, secret := range seen {
+		webhooks = append(webhooks, WebhookOverride{WebhookURL: webhookURL, WebhookOnly: true, WebhookSecret: secret})
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].WebhookURL < webhooks[j].WebhookURL })
+	return webhooks
+}
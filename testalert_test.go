@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestSendTestAlert checks that the test alert and recovery reach the global
+// channels and, once each, every distinct item webhook
+func TestSendTestAlert(t *testing.T) {
+	requests := captureNotifications(t, http.StatusOK)
+
+	config := &Config{
+		Slack: SlackConfig{WebhookURL: "https://hooks.slack.test/services/x"},
+		Health: []HealthConfig{{Endpoints: []HealthItem{
+			{Name: "a", WebhookOverride: WebhookOverride{WebhookURL: "https://hooks.test/team"}},
+			{Name: "b", WebhookOverride: WebhookOverride{WebhookURL: "https://hooks.test/team"}},
+		}}},
+	}
+	if !sendTestAlert(config) {
+		t.Fatal("expected the test alert to succeed")
+	}
+
+	counts := make(map[string]int)
+	for _, req := range *requests {
+		counts[req.url]++
+	}
+	if counts[config.Slack.WebhookURL] != 2 || counts["https://hooks.test/team"] != 2 || len(counts) != 2 {
+		t.Errorf("expected an alert and a recovery on Slack and the item webhook, got %v", counts)
+	}
+	if !strings.Contains((*requests)[0].body, "TEST Alert") {
+		t.Errorf("expected the test alert first, got %s", (*requests)[0].body)
+	}
+}
+
+// TestSendTestAlertNoChannel checks that the test alert fails without any channel
+func TestSendTestAlertNoChannel(t *testing.T) {
+	if sendTestAlert(&Config{}) {
+		t.Error("expected the test alert to fail without a channel")
+	}
+}
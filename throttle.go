@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultMaxAlertsPerMinute caps the alert messages of a widespread outage
+const defaultMaxAlertsPerMinute = 30
+
+// alertLimiter is a token bucket capping alert messages across all items: up
+// to perMinute alerts at once, refilled at perMinute a minute. Alerts over the
+// cap are counted instead of sent, and reported together in one summary a
+// minute after the first of them.
+type alertLimiter struct {
+	perMinute    int           // Alerts per minute, 0 disables the cap
+	summaryDelay time.Duration // How long suppressed alerts are collected before the summary
+
+	mu         sync.Mutex
+	tokens     float64
+	refilled   time.Time
+	suppressed int // Alerts suppressed since the last summary
+}
+
+func newAlertLimiter(perMinute int) *alertLimiter {
+	return &alertLimiter{perMinute: perMinute, summaryDelay: time.Minute, tokens: float64(perMinute), refilled: time.Now()}
+}
+
+// allow takes a token for a message carrying the given number of alerts.
+// Without one the alerts are counted as suppressed, and first reports whether
+// they are the first since the last summary, i.e. whether the summary needs
+// scheduling.
+func (l *alertLimiter) allow(alerts int) (allowed, first bool) {
+	if l == nil || l.perMinute <= 0 {
+		return true, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.refilled).Minutes()*float64(l.perMinute), float64(l.perMinute))
+	l.refilled = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, false
+	}
+
+	first = l.suppressed == 0
+	l.suppressed += alerts
+	return false, first
+}
+
+// takeSuppressed returns and resets the count of suppressed alerts
+func (l *alertLimiter) takeSuppressed() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.suppressed
+	l.suppressed = 0
+	return count
+}
+
+// throttled reports whether an alert is over the max_alerts_per_minute cap.
+// Its chat message is then left out of the channels, but incident channels
+// still get it so every item keeps its own incident to resolve.
+func (n *Notifier) throttled(job notification) bool {
+	if job.incident == nil || job.incident.action != pagerDutyTrigger {
+		return false
+	}
+	if !n.overLimit(job, 1) {
+		return false
+	}
+
+	if job.scope == scopeAll && n.hasIncidentChannel() {
+		job.scope = scopeIncidents
+		n.enqueue(job)
+	}
+	return true
+}
+
+// overLimit takes a token for a chat message carrying the given number of
+// alerts, and reports whether it is over the max_alerts_per_minute cap. The
+// alerts of a message over the cap are reported in the next summary instead.
+func (n *Notifier) overLimit(job notification, alerts int) bool {
+	allowed, first := n.limiter.allow(alerts)
+	if allowed {
+		return false
+	}
+
+	slog.Warn("alert suppressed by max_alerts_per_minute", "max_alerts_per_minute", n.limiter.perMinute,
+		"dedup_key", job.incident.dedupKey, "severity", job.incident.severity, "alerts", alerts)
+	if first {
+		time.AfterFunc(n.limiter.summaryDelay, n.sendSuppressedSummary)
+	}
+	return true
+}
+
+// sendSuppressedSummary sends one message in place of the alerts suppressed
+// since the last summary
+func (n *Notifier) sendSuppressedSummary() {
+	count := n.limiter.takeSuppressed()
+	if count == 0 {
+		return
+	}
+	message := fmt.Sprintf("%s %d additional alerts suppressed\nMore than %d alerts per minute were raised, see the agent logs for the suppressed alerts.",
+		severityEmoji(severityWarning), count, n.limiter.perMinute)
+	if n.hasGlobalChannel() {
+		n.enqueue(notification{message: message, scope: scopeChat})
+	}
+}
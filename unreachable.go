@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// endpointGroup is a group whose items are all fetched from one endpoint, so
+// every item failing to fetch means the endpoint itself is down
+type endpointGroup interface {
+	checkGroup
+	// groupEndpoint is the endpoint shared by the group's items
+	groupEndpoint() string
+}
+
+func (c *AddressConfig) groupEndpoint() string         { return c.RESTEndpoint }
+func (c *KaspaAddressConfig) groupEndpoint() string    { return c.RESTEndpoint }
+func (c *MetricConfig) groupEndpoint() string          { return c.RESTEndpoint }
+func (c *EVMAddressConfig) groupEndpoint() string      { return c.RPCEndpoint }
+func (c *BTCAddressConfig) groupEndpoint() string      { return c.RESTEndpoint }
+func (c *CosmosValidatorConfig) groupEndpoint() string { return c.RESTEndpoint }
+func (c *GrantConfig) groupEndpoint() string           { return c.RESTEndpoint }
+
+// cycleResults counts the checks of a check cycle that ran and those whose
+// request failed or got an error status. Its items are checked concurrently.
+type cycleResults struct {
+	mu      sync.Mutex
+	checked int
+	failed  int
+	lastErr error // Error of the last failed request
+}
+
+// add records the result of one item's check. Errors about the response,
+// such as an address without balances, show the endpoint answered.
+func (r *cycleResults) add(checked bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if checked {
+		r.checked++
+	}
+	if err != nil && errorClass(err) != "" {
+		r.failed++
+		r.lastErr = err
+	}
+}
+
+// groupReachability tracks whether a group's endpoint is unreachable. It is
+// only used by the group's goroutine, whose check cycles never overlap.
+type groupReachability struct {
+	unreachable   bool      // Whether the unreachable alert fired, so a successful fetch is a recovery
+	lastAlertTime time.Time // Last unreachable alert, for the cooldown
+}
+
+// update alerts once every item checked in a cycle failed, since the items
+// alone only log their failed fetches and a dead node would go unnoticed,
+// and sends a recovery once a fetch succeeds again. Cycles in which every
+// item is backing off don't change the state.
+func (r *groupReachability) update(g endpointGroup, results *cycleResults, notifier *Notifier, cooldown int) {
+	if results.checked == 0 {
+		return
+	}
+	itemType, group, endpoint := g.checkType(), g.groupName(), g.groupEndpoint()
+	incidentKey := dedupKey(itemType, group, "unreachable")
+
+	if results.failed < results.checked {
+		if !r.unreachable {
+			return
+		}
+		r.unreachable = false
+
+		message := fmt.Sprintf("%s Recovery: [%s] endpoint is reachable again\nEndpoint: `%s`",
+			messageEmoji(emojiRecovery), group, endpoint)
+		details := messageData{Group: group, Address: endpoint, Message: message}
+		message = renderMessage(itemType, eventRecovery, details)
+
+		if !silenced(itemType, group, "") {
+			notifier.Resolve(message, WebhookOverride{}, incidentKey, severityCritical, details)
+		}
+		slog.Info("endpoint reachable again", "type", itemType, "group", group, "endpoint", endpoint)
+		return
+	}
+
+	// Check if we're still in cooldown period
+	if !shouldAlert(r.lastAlertTime, cooldown) {
+		slog.Info("alert suppressed by cooldown", "type", itemType, "group", group, "endpoint", endpoint,
+			"remaining", cooldownRemaining(r.lastAlertTime, cooldown))
+		return
+	}
+
+	// Don't notify while the group is silenced, the alert fires once the silence ends
+	if silenced(itemType, group, "") {
+		return
+	}
+
+	message := fmt.Sprintf("%s Alert: [%s] endpoint unreachable\nEndpoint: `%s`\nAll %d checked items failed to fetch\nError: %v",
+		severityEmoji(severityCritical), group, endpoint, results.checked, results.lastErr)
+	details := messageData{Group: group, Address: endpoint, Error: results.lastErr.Error(), Message: message}
+	message = renderMessage(itemType, eventAlert, details)
+
+	notifier.Alert(message, WebhookOverride{}, incidentKey, severityCritical, details)
+	slog.Warn("endpoint unreachable", "type", itemType, "group", group, "endpoint", endpoint,
+		"failed", results.failed, "error", results.lastErr)
+
+	r.unreachable = true
+	r.lastAlertTime = time.Now()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// validateEndpoints probes every configured item once and prints an OK/FAIL
+// report. An item that answers is OK even if it is unhealthy, only a probe
+// that couldn't check the item fails. It returns false if any probe failed.
+func validateEndpoints(config *Config) bool {
+	total, failed := 0, 0
+	for _, g := range groupCheckers(config) {
+		for i := range g.itemCount() {
+			item := g.item(i)
+			total++
+			if _, _, err := item.Check(context.Background()); err != nil {
+				failed++
+				fmt.Printf("FAIL  %-15s [%s] %s: %v\n", g.checkType(), g.groupName(), item.name, err)
+				continue
+			}
+			fmt.Printf("OK    %-15s [%s] %s\n", g.checkType(), g.groupName(), item.name)
+		}
+	}
+
+	fmt.Printf("\n%d items checked, %d failed\n", total, failed)
+	return failed == 0
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestValidateEndpoints checks that an unhealthy item passes validation and
+// only an item that couldn't be checked fails it
+func TestValidateEndpoints(t *testing.T) {
+	noRetries(t)
+
+	healthConfig := func(status int) []HealthConfig {
+		return []HealthConfig{{Name: "group", RequestOptions: RequestOptions{httpClient: respond(status, `{"result":{"isHealthy":false}}`)}, Endpoints: []HealthItem{{
+			Name: "node", Endpoint: "http://node.test/health", HealthPath: defaultHealthPath, HealthyValue: defaultHealthyValue,
+			itemState: newItemState(), slow: newItemState(),
+		}}}}
+	}
+
+	if !validateEndpoints(&Config{Health: healthConfig(http.StatusOK)}) {
+		t.Error("expected an unhealthy but reachable item to pass validation")
+	}
+	if validateEndpoints(&Config{Health: healthConfig(http.StatusBadGateway)}) {
+		t.Error("expected an item that couldn't be checked to fail validation")
+	}
+}
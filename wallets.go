@@ -0,0 +1,69 @@
+package main
+
+import "fmt"
+
+// WalletChain is one chain account controlled by a logical wallet
+type WalletChain struct {
+	Chain           string           `mapstructure:"chain"`          // Chain name used to tag alerts
+	RESTEndpoint    string           `mapstructure:"rest_endpoint"`  // Cosmos REST endpoint for this chain
+	Address         string           `mapstructure:"address"`        // Account address on this chain
+	AlertCooldown   Seconds          `mapstructure:"alert_cooldown"` // Optional per-chain cooldown
+	Threshold       BalanceThreshold `mapstructure:"threshold"`
+	ThresholdPreset string           `mapstructure:"threshold_preset"` // Optional name of a preset the threshold is based on
+
+	RequestOptions `mapstructure:",squash"`
+}
+
+// WalletConfig groups the accounts one operator key controls across chains
+type WalletConfig struct {
+	Name          string        `mapstructure:"name"`
+	CheckInterval Seconds       `mapstructure:"check_interval"` // Optional check interval for every chain
+	AlertCooldown Seconds       `mapstructure:"alert_cooldown"` // Optional cooldown for every chain
+	Chains        []WalletChain `mapstructure:"chains"`
+
+	WebhookOverride `mapstructure:",squash"`
+}
+
+// expandWallets turns each wallet chain into an address group named after the
+// wallet, with the chain as the item name, so alerts read "[wallet] chain"
+// and reuse the regular balance checks
+func expandWallets(config *Config) error {
+	for i, wallet := range config.Wallets {
+		if wallet.Name == "" {
+			return fmt.Errorf("name is required for wallet #%d", i+1)
+		}
+
+		for j, chain := range wallet.Chains {
+			if chain.Chain == "" {
+				return fmt.Errorf("chain is required for chain #%d in wallet '%s'", j+1, wallet.Name)
+			}
+			if chain.RESTEndpoint == "" {
+				return fmt.Errorf("REST endpoint is required for chain '%s' in wallet '%s'", chain.Chain, wallet.Name)
+			}
+
+			cooldown := wallet.AlertCooldown
+			if chain.AlertCooldown > 0 {
+				cooldown = chain.AlertCooldown
+			}
+
+			item := AddressItem{
+				Name:            chain.Chain,
+				Address:         chain.Address,
+				AlertCooldown:   cooldown,
+				Threshold:       chain.Threshold,
+				ThresholdPreset: chain.ThresholdPreset,
+				WebhookOverride: wallet.WebhookOverride,
+			}
+
+			config.Addresses = append(config.Addresses, AddressConfig{
+				Name:           wallet.Name,
+				RESTEndpoint:   chain.RESTEndpoint,
+				CheckInterval:  wallet.CheckInterval,
+				Addresses:      []AddressItem{item},
+				RequestOptions: chain.RequestOptions,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// TestExpandWallets checks that each chain of a wallet becomes an address
+// group named after the wallet, with the chain cooldown overriding the wallet's
+func TestExpandWallets(t *testing.T) {
+	config := &Config{Wallets: []WalletConfig{{
+		Name:          "operator",
+		AlertCooldown: 600,
+		Chains: []WalletChain{
+			{Chain: "dymension", RESTEndpoint: "http://dymension.test", Address: "dym1abc"},
+			{Chain: "osmosis", RESTEndpoint: "http://osmosis.test", Address: "osmo1abc", AlertCooldown: 60},
+		},
+		WebhookOverride: WebhookOverride{WebhookURL: "https://hooks.test/operator"},
+	}}}
+
+	if err := expandWallets(config); err != nil {
+		t.Fatalf("expandWallets: %v", err)
+	}
+	if len(config.Addresses) != 2 {
+		t.Fatalf("expected an address group per chain, got %d", len(config.Addresses))
+	}
+
+	expected := []struct {
+		endpoint string
+		name     string
+		cooldown Seconds
+	}{
+		{endpoint: "http://dymension.test", name: "dymension", cooldown: 600},
+		{endpoint: "http://osmosis.test", name: "osmosis", cooldown: 60},
+	}
+	for i, want := range expected {
+		group := config.Addresses[i]
+		item := group.Addresses[0]
+		if group.Name != "operator" || group.RESTEndpoint != want.endpoint || item.Name != want.name || item.AlertCooldown != want.cooldown {
+			t.Errorf("unexpected group %q at %s with item %q and cooldown %d", group.Name, group.RESTEndpoint, item.Name, item.AlertCooldown)
+		}
+		if item.WebhookURL != "https://hooks.test/operator" {
+			t.Errorf("expected the wallet's webhook on %q, got %q", item.Name, item.WebhookURL)
+		}
+	}
+
+	if err := expandWallets(&Config{Wallets: []WalletConfig{{Name: "operator", Chains: []WalletChain{{Chain: "dymension"}}}}}); err == nil {
+		t.Error("expected a chain without a REST endpoint to be rejected")
+	}
+}